@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSAKeyBits = 2048
+
+// Key is a key that access tokens are signed or verified with, identified
+// by the kid header of the tokens. Keys without a private part only verify.
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+	Private   crypto.Signer
+}
+
+// ParseKeyPEM reads a PKCS#8 private key, a PKCS#1 RSA private key or a
+// PKIX public key. RSA keys sign with RS256 and Ed25519 keys with EdDSA.
+func ParseKeyPEM(id string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("key %s: no PEM data", id)
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("key %s: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	key := Key{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.Public, key.Private = jwt.SigningMethodRS256.Alg(), &k.PublicKey, k
+	case *rsa.PublicKey:
+		key.Algorithm, key.Public = jwt.SigningMethodRS256.Alg(), k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.Public, key.Private = jwt.SigningMethodEdDSA.Alg(), k.Public(), k
+	case ed25519.PublicKey:
+		key.Algorithm, key.Public = jwt.SigningMethodEdDSA.Alg(), k
+	default:
+		return Key{}, fmt.Errorf("key %s: unsupported key type %T", id, parsed)
+	}
+
+	if rsaKey, ok := key.Public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+		return Key{}, fmt.Errorf("key %s: RSA keys must have at least %d bits", id, minRSAKeyBits)
+	}
+
+	return key, nil
+}
+
+// GenerateKey returns a new Ed25519 key.
+func GenerateKey(id string) (Key, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{ID: id, Algorithm: jwt.SigningMethodEdDSA.Alg(), Public: public, Private: private}, nil
+}
+
+// KeySet signs access tokens with one key and accepts tokens signed by any
+// of its keys. Rotating keys means adding the new key, signing with it, and
+// dropping the old key once the tokens it signed have expired.
+type KeySet struct {
+	signing Key
+	keys    map[string]Key
+}
+
+// NewKeySet builds a key set that signs with the key named signingID, which
+// must be one of keys and have a private part.
+func NewKeySet(signingID string, keys ...Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]Key, len(keys))}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("key without an ID")
+		}
+		if _, ok := ks.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key ID %s", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	signing, ok := ks.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %s not found", signingID)
+	}
+	if signing.Private == nil {
+		return nil, fmt.Errorf("signing key %s has no private key", signingID)
+	}
+	ks.signing = signing
+
+	return ks, nil
+}
+
+// SigningKeyID is the kid of the tokens signed now.
+func (ks *KeySet) SigningKeyID() string {
+	return ks.signing.ID
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(ks.signing.Algorithm), claims)
+	token.Header["kid"] = ks.signing.ID
+
+	return token.SignedString(ks.signing.Private)
+}
+
+// verificationKey finds the key a token was signed with by its kid. The
+// algorithm of the token must be the one of the key.
+func (ks *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("key %s does not sign with %s", kid, token.Method.Alg())
+	}
+
+	return key.Public, nil
+}
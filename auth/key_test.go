@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePEM(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+func TestParseKeyPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	edKey, err := GenerateKey("ed")
+	require.NoError(t, err)
+
+	pkcs8 := func(key any) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return encodePEM("PRIVATE KEY", der)
+	}
+	pkix := func(key any) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+		return encodePEM("PUBLIC KEY", der)
+	}
+
+	tests := []struct {
+		name          string
+		data          []byte
+		expectedAlg   string
+		expectPrivate bool
+		expectedError string
+	}{
+		{name: "RSA PKCS#8", data: pkcs8(rsaKey), expectedAlg: "RS256", expectPrivate: true},
+		{name: "RSA PKCS#1", data: encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), expectedAlg: "RS256", expectPrivate: true},
+		{name: "RSA public key", data: pkix(&rsaKey.PublicKey), expectedAlg: "RS256"},
+		{name: "Ed25519", data: pkcs8(edKey.Private), expectedAlg: "EdDSA", expectPrivate: true},
+		{name: "Ed25519 public key", data: pkix(edKey.Public), expectedAlg: "EdDSA"},
+		{name: "Small RSA key", data: pkcs8(smallRSAKey), expectedError: "key test: RSA keys must have at least 2048 bits"},
+		{name: "Not PEM", data: []byte("secret"), expectedError: "key test: no PEM data"},
+		{name: "Certificate", data: encodePEM("CERTIFICATE", []byte{1}), expectedError: `key test: unsupported PEM block "CERTIFICATE"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKeyPEM("test", tt.data)
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "test", key.ID)
+			require.Equal(t, tt.expectedAlg, key.Algorithm)
+			require.NotNil(t, key.Public)
+			require.Equal(t, tt.expectPrivate, key.Private != nil)
+		})
+	}
+}
+
+func TestNewKeySet(t *testing.T) {
+	key, err := GenerateKey("key-1")
+	require.NoError(t, err)
+	publicOnly := Key{ID: "key-0", Algorithm: key.Algorithm, Public: key.Public}
+
+	_, err = NewKeySet("key-2", key)
+	require.EqualError(t, err, "signing key key-2 not found")
+
+	_, err = NewKeySet("key-0", key, publicOnly)
+	require.EqualError(t, err, "signing key key-0 has no private key")
+
+	_, err = NewKeySet("key-1", key, key)
+	require.EqualError(t, err, "duplicate key ID key-1")
+
+	keys, err := NewKeySet("key-1", key, publicOnly)
+	require.NoError(t, err)
+	require.Equal(t, "key-1", keys.SigningKeyID())
+}
+
+func TestKeyRotation(t *testing.T) {
+	userID := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	sessionID := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+
+	oldKey, err := GenerateKey("2026-09")
+	require.NoError(t, err)
+	newKey, err := GenerateKey("2026-10")
+	require.NoError(t, err)
+
+	before, err := NewKeySet("2026-09", oldKey)
+	require.NoError(t, err)
+	oldToken, err := NewAccessToken(before, userID, sessionID, "testuser", RoleCustomer, time.Now())
+	require.NoError(t, err)
+
+	// Signing with the new key while the old one still verifies.
+	during, err := NewKeySet("2026-10", oldKey, newKey)
+	require.NoError(t, err)
+	newToken, err := NewAccessToken(during, userID, sessionID, "testuser", RoleCustomer, time.Now())
+	require.NoError(t, err)
+
+	_, err = ParseAccessToken(during, oldToken)
+	require.NoError(t, err)
+	_, err = ParseAccessToken(during, newToken)
+	require.NoError(t, err)
+
+	// Servers that have not picked up the new key yet already accept it
+	// once it is published, before it signs anything.
+	published, err := NewKeySet("2026-09", oldKey, newKey)
+	require.NoError(t, err)
+	_, err = ParseAccessToken(published, newToken)
+	require.NoError(t, err)
+
+	after, err := NewKeySet("2026-10", newKey)
+	require.NoError(t, err)
+	_, err = ParseAccessToken(after, oldToken)
+	require.Error(t, err)
+	_, err = ParseAccessToken(after, newToken)
+	require.NoError(t, err)
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edKey, err := GenerateKey("ed")
+	require.NoError(t, err)
+
+	keys, err := NewKeySet("ed", edKey, Key{ID: "rsa", Algorithm: "RS256", Public: &rsaKey.PublicKey, Private: rsaKey})
+	require.NoError(t, err)
+
+	set := keys.JWKS()
+	require.Len(t, set.Keys, 2)
+
+	require.Equal(t, JWK{Kty: "OKP", Kid: "ed", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: set.Keys[0].X}, set.Keys[0])
+	require.Len(t, set.Keys[0].X, 43)
+
+	require.Equal(t, "RSA", set.Keys[1].Kty)
+	require.Equal(t, "rsa", set.Keys[1].Kid)
+	require.Equal(t, "RS256", set.Keys[1].Alg)
+	require.Equal(t, "AQAB", set.Keys[1].E)
+	require.Len(t, set.Keys[1].N, 342)
+}
@@ -0,0 +1,70 @@
+package auth
+
+import "time"
+
+// Scopes that failed logins are counted in.
+const (
+	LockoutScopeUsername = "username"
+	LockoutScopeIP       = "ip"
+)
+
+// LoginFailureWindow is how long failed logins are remembered. A failure
+// after a quiet window starts the count over.
+const LoginFailureWindow = 15 * time.Minute
+
+const (
+	loginBaseDelay = time.Second
+	loginMaxDelay  = 30 * time.Second
+)
+
+// LoginLockout throttles the failed logins of one username or client IP.
+// After FreeAttempts failures each attempt has to wait for a delay that
+// doubles with every failure, and after LockAfter failures logins are
+// refused for LockFor.
+type LoginLockout struct {
+	FreeAttempts int
+	LockAfter    int
+	LockFor      time.Duration
+}
+
+var (
+	// UsernameLockout guards one account against guessing from anywhere.
+	UsernameLockout = LoginLockout{FreeAttempts: 3, LockAfter: 10, LockFor: 15 * time.Minute}
+	// IPLockout guards against one client trying many usernames, so it
+	// allows more failures than UsernameLockout.
+	IPLockout = LoginLockout{FreeAttempts: 10, LockAfter: 50, LockFor: 15 * time.Minute}
+)
+
+// LoginFailureRetention is how long a failed login can still hold up a
+// login: past it the count would start over and any lockout has ended, so
+// the count can be forgotten.
+var LoginFailureRetention = max(LoginFailureWindow, UsernameLockout.LockFor, IPLockout.LockFor)
+
+// LoginLockoutFor returns the lockout of scope.
+func LoginLockoutFor(scope string) LoginLockout {
+	if scope == LockoutScopeIP {
+		return IPLockout
+	}
+	return UsernameLockout
+}
+
+// RetryAt is when the next login is accepted after failures failed logins,
+// the last of them at lastFailure. It is the zero time if no wait is needed.
+func (l LoginLockout) RetryAt(failures int, lastFailure time.Time) time.Time {
+	if failures >= l.LockAfter {
+		return lastFailure.Add(l.LockFor)
+	}
+	if failures < l.FreeAttempts {
+		return time.Time{}
+	}
+
+	delay := loginBaseDelay
+	for i := l.FreeAttempts; i < failures && delay < loginMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > loginMaxDelay {
+		delay = loginMaxDelay
+	}
+
+	return lastFailure.Add(delay)
+}
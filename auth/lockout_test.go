@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginLockoutRetryAt(t *testing.T) {
+	last := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	lockout := LoginLockout{FreeAttempts: 3, LockAfter: 10, LockFor: 15 * time.Minute}
+
+	tests := []struct {
+		name     string
+		failures int
+		expected time.Time
+	}{
+		{name: "No failures", failures: 0, expected: time.Time{}},
+		{name: "Free attempts left", failures: 2, expected: time.Time{}},
+		{name: "First delay", failures: 3, expected: last.Add(time.Second)},
+		{name: "Delay doubles", failures: 5, expected: last.Add(4 * time.Second)},
+		{name: "Delay is capped", failures: 9, expected: last.Add(30 * time.Second)},
+		{name: "Locked", failures: 10, expected: last.Add(15 * time.Minute)},
+		{name: "Still locked", failures: 12, expected: last.Add(15 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, lockout.RetryAt(tt.failures, last))
+		})
+	}
+}
+
+func TestLoginLockoutFor(t *testing.T) {
+	require.Equal(t, IPLockout, LoginLockoutFor(LockoutScopeIP))
+	require.Equal(t, UsernameLockout, LoginLockoutFor(LockoutScopeUsername))
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Principal is the authenticated caller of a request, taken from the claims
+// of its access token.
+type Principal struct {
+	UserID    pgtype.UUID
+	SessionID pgtype.UUID
+	Username  string
+	Role      string
+}
+
+// Elevated reports whether the principal may act on accounts other than its
+// own.
+func (p Principal) Elevated() bool {
+	return p.Role == RoleSupport || p.Role == RoleAdmin
+}
+
+// CanActFor reports whether the principal may act on the account of userID.
+func (p Principal) CanActFor(userID pgtype.UUID) bool {
+	return (p.UserID.Valid && p.UserID == userID) || p.Elevated()
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored in ctx by WithPrincipal.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
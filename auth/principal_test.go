@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrincipalCanActFor(t *testing.T) {
+	own := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	other := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+
+	tests := []struct {
+		name     string
+		role     string
+		userID   pgtype.UUID
+		expected bool
+	}{
+		{name: "Own account", role: RoleCustomer, userID: own, expected: true},
+		{name: "Another account", role: RoleCustomer, userID: other, expected: false},
+		{name: "Affiliate on another account", role: RoleAffiliate, userID: other, expected: false},
+		{name: "Support on another account", role: RoleSupport, userID: other, expected: true},
+		{name: "Admin on another account", role: RoleAdmin, userID: other, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Principal{UserID: own, Role: tt.role}
+			require.Equal(t, tt.expected, p.CanActFor(tt.userID))
+		})
+	}
+
+	require.False(t, Principal{Role: RoleCustomer}.CanActFor(pgtype.UUID{}))
+}
+
+func TestPrincipalFrom(t *testing.T) {
+	_, ok := PrincipalFrom(context.Background())
+	require.False(t, ok)
+
+	p := Principal{UserID: pgtype.UUID{Bytes: [16]byte{1}, Valid: true}, Username: "testuser", Role: RoleAdmin}
+	got, ok := PrincipalFrom(WithPrincipal(context.Background(), p))
+	require.True(t, ok)
+	require.Equal(t, p, got)
+}
@@ -0,0 +1,23 @@
+package auth
+
+// Roles a user can hold. A role is stored on the user and copied into the
+// access tokens of its sessions.
+const (
+	RoleCustomer  = "customer"
+	RoleAffiliate = "affiliate"
+	RoleSupport   = "support"
+	RoleAdmin     = "admin"
+)
+
+// Roles lists every role, from the least to the most privileged.
+var Roles = []string{RoleCustomer, RoleAffiliate, RoleSupport, RoleAdmin}
+
+// ValidRole reports whether role is one of Roles.
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+// Package auth issues and verifies the tokens of a login session: short-lived
+// JWT access tokens and opaque refresh tokens that are rotated on every use.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Claims are the claims of an access token. Subject is the user ID and ID
+// (jti) is the session the token belongs to.
+type Claims struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// NewAccessToken signs an access token for the session with the signing key
+// of keys. It expires after AccessTokenTTL.
+func NewAccessToken(keys *KeySet, userID, sessionID pgtype.UUID, username, role string, now time.Time) (string, error) {
+	return keys.sign(Claims{
+		Username: username,
+		Role:     role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			ID:        sessionID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	})
+}
+
+// ParseAccessToken verifies the signature and expiry of an access token
+// against the key named by its kid and returns its claims.
+func ParseAccessToken(keys *KeySet, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, keys.verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// NewRefreshToken returns a random refresh token and the hash to store for
+// it. The token itself is only ever given to the client.
+func NewRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the hex SHA-256 of a refresh token, the form it
+// is stored and looked up in.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeySet(t *testing.T, id string) *KeySet {
+	key, err := GenerateKey(id)
+	require.NoError(t, err)
+
+	keys, err := NewKeySet(id, key)
+	require.NoError(t, err)
+
+	return keys
+}
+
+func TestAccessToken(t *testing.T) {
+	keys := newTestKeySet(t, "key-1")
+	userID := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	sessionID := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+
+	t.Run("Round trip", func(t *testing.T) {
+		tokenString, err := NewAccessToken(keys, userID, sessionID, "testuser", RoleCustomer, time.Now())
+		require.NoError(t, err)
+
+		claims, err := ParseAccessToken(keys, tokenString)
+		require.NoError(t, err)
+		require.Equal(t, "01000000-0000-0000-0000-000000000000", claims.Subject)
+		require.Equal(t, "02000000-0000-0000-0000-000000000000", claims.ID)
+		require.Equal(t, "testuser", claims.Username)
+		require.Equal(t, RoleCustomer, claims.Role)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		tokenString, err := NewAccessToken(keys, userID, sessionID, "testuser", RoleCustomer, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		_, err = ParseAccessToken(keys, tokenString)
+		require.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		tokenString, err := NewAccessToken(newTestKeySet(t, "key-2"), userID, sessionID, "testuser", RoleCustomer, time.Now())
+		require.NoError(t, err)
+
+		_, err = ParseAccessToken(keys, tokenString)
+		require.ErrorIs(t, err, jwt.ErrTokenUnverifiable)
+	})
+
+	t.Run("Other key with the same ID", func(t *testing.T) {
+		tokenString, err := NewAccessToken(newTestKeySet(t, "key-1"), userID, sessionID, "testuser", RoleCustomer, time.Now())
+		require.NoError(t, err)
+
+		_, err = ParseAccessToken(keys, tokenString)
+		require.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+
+	t.Run("HMAC token", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "testuser", "exp": time.Now().Add(time.Hour).Unix()})
+		token.Header["kid"] = "key-1"
+		tokenString, err := token.SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		_, err = ParseAccessToken(keys, tokenString)
+		require.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+
+	t.Run("No expiry", func(t *testing.T) {
+		tokenString, err := keys.sign(jwt.MapClaims{"sub": "testuser"})
+		require.NoError(t, err)
+
+		_, err = ParseAccessToken(keys, tokenString)
+		require.ErrorIs(t, err, jwt.ErrTokenRequiredClaimMissing)
+	})
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	require.NoError(t, err)
+	require.Len(t, token, 43)
+	require.Equal(t, HashRefreshToken(token), hash)
+
+	other, _, err := NewRefreshToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+}
@@ -0,0 +1,102 @@
+// Package commission computes how an order total is split across a chain of
+// affiliates. It does no I/O; callers load the chain and the rates and
+// persist the allocations.
+package commission
+
+import (
+	"errors"
+
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNoRates         = errors.New("At least one commission rate is required")
+	ErrRateOutOfRange  = errors.New("Commission rates must be between 0 and 1")
+	ErrIncreasingRates = errors.New("Commission rates must not increase with level")
+)
+
+// Plan holds the per-level rates as fractions of the order total. Rates[0] is
+// the top level and caps the total payout of an order.
+type Plan struct {
+	Rates []float64
+}
+
+// Allocation is the amount one affiliate earns on an order. Level is the
+// index into the plan rates, 0 being the top of the chain.
+type Allocation struct {
+	AffiliateID pgtype.UUID
+	Level       int
+	Amount      decimal.Decimal
+}
+
+// Validate checks that rates are fractions of the order total and never
+// increase with depth, so each level's share stays non-negative.
+func (p Plan) Validate() error {
+	if len(p.Rates) == 0 {
+		return ErrNoRates
+	}
+
+	for i, rate := range p.Rates {
+		if rate <= 0 || rate > 1 {
+			return ErrRateOutOfRange
+		}
+		if i > 0 && rate > p.Rates[i-1] {
+			return ErrIncreasingRates
+		}
+	}
+
+	return nil
+}
+
+// Calculate splits total across chain, which is ordered from the buyer's
+// affiliate up to the top of the tree.
+//
+// Counting levels from the top, every affiliate below the top at a level the
+// plan covers earns the difference between the rate of the level above and
+// its own rate. The top affiliate earns what is left of Rates[0] once those
+// are paid. Affiliates deeper than the plan earn nothing, so the payout of an
+// order is always Rates[0] of the total however long the chain is.
+//
+// Rounding: each level's cumulative amount, rate times total, is rounded to
+// the cent with helpers.RoundMoney and shares are the differences between
+// neighbouring cumulative amounts. Shares are therefore exact cents and always
+// add up to Rates[0] times the total rounded to the cent, with no residue.
+// Allocations are returned in chain order and zero amounts are left out.
+func Calculate(chain []pgtype.UUID, total decimal.Decimal, plan Plan) []Allocation {
+	allocations := []Allocation{}
+	numLevels := len(plan.Rates)
+	if numLevels == 0 || len(chain) == 0 {
+		return allocations
+	}
+
+	cumulative := func(level int) decimal.Decimal {
+		return helpers.RoundMoney(decimal.NewFromFloat(plan.Rates[level]).Mul(total))
+	}
+
+	paidLevels := min(len(chain), numLevels)
+
+	for i, affiliateID := range chain {
+		var amount decimal.Decimal
+		level := len(chain) - 1 - i
+
+		if level == 0 {
+			amount = cumulative(paidLevels - 1)
+		} else if level < numLevels {
+			amount = cumulative(level - 1).Sub(cumulative(level))
+		}
+
+		if !amount.IsPositive() {
+			continue
+		}
+
+		allocations = append(allocations, Allocation{
+			AffiliateID: affiliateID,
+			Level:       level,
+			Amount:      amount,
+		})
+	}
+
+	return allocations
+}
@@ -0,0 +1,221 @@
+package commission
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/quick"
+
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func newChain(n int) []pgtype.UUID {
+	chain := make([]pgtype.UUID, n)
+	for i := range chain {
+		chain[i] = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	}
+	return chain
+}
+
+func sumAllocations(allocations []Allocation) decimal.Decimal {
+	sum := decimal.Zero
+	for _, allocation := range allocations {
+		sum = sum.Add(allocation.Amount)
+	}
+	return sum
+}
+
+func TestPlanValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		rates         []float64
+		expectedError error
+	}{
+		{name: "Valid rates", rates: []float64{0.20, 0.15, 0.10, 0.05}},
+		{name: "Single level", rates: []float64{0.30}},
+		{name: "Equal levels", rates: []float64{0.10, 0.10}},
+		{name: "Empty", rates: []float64{}, expectedError: ErrNoRates},
+		{name: "Zero rate", rates: []float64{0.20, 0}, expectedError: ErrRateOutOfRange},
+		{name: "Rate above one", rates: []float64{1.5}, expectedError: ErrRateOutOfRange},
+		{name: "Increasing rates", rates: []float64{0.10, 0.20}, expectedError: ErrIncreasingRates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Plan{Rates: tt.rates}.Validate()
+			if tt.expectedError == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	chain := newChain(5)
+	plan := Plan{Rates: []float64{0.20, 0.15, 0.10, 0.05}}
+
+	tests := []struct {
+		name     string
+		chain    []pgtype.UUID
+		plan     Plan
+		total    string
+		expected []Allocation
+	}{
+		{
+			name:  "Single affiliate takes the top rate",
+			chain: chain[4:],
+			plan:  plan,
+			expected: []Allocation{
+				{AffiliateID: chain[4], Level: 0, Amount: decimal.NewFromInt(200)},
+			},
+		},
+		{
+			name:  "Two levels",
+			chain: chain[3:],
+			plan:  plan,
+			expected: []Allocation{
+				{AffiliateID: chain[3], Level: 1, Amount: decimal.NewFromInt(50)},
+				{AffiliateID: chain[4], Level: 0, Amount: decimal.NewFromInt(150)},
+			},
+		},
+		{
+			name:  "Chain as deep as the plan",
+			chain: chain[1:],
+			plan:  plan,
+			expected: []Allocation{
+				{AffiliateID: chain[1], Level: 3, Amount: decimal.NewFromInt(50)},
+				{AffiliateID: chain[2], Level: 2, Amount: decimal.NewFromInt(50)},
+				{AffiliateID: chain[3], Level: 1, Amount: decimal.NewFromInt(50)},
+				{AffiliateID: chain[4], Level: 0, Amount: decimal.NewFromInt(50)},
+			},
+		},
+		{
+			name:  "Chain deeper than the plan",
+			chain: chain,
+			plan:  Plan{Rates: []float64{0.20, 0.10}},
+			expected: []Allocation{
+				{AffiliateID: chain[3], Level: 1, Amount: decimal.NewFromInt(100)},
+				{AffiliateID: chain[4], Level: 0, Amount: decimal.NewFromInt(100)},
+			},
+		},
+		{
+			name:  "Flat rates pay only the top",
+			chain: chain[2:],
+			plan:  Plan{Rates: []float64{0.10, 0.10, 0.10}},
+			expected: []Allocation{
+				{AffiliateID: chain[4], Level: 0, Amount: decimal.NewFromInt(100)},
+			},
+		},
+		{
+			name:  "Shares are rounded to the cent without residue",
+			chain: chain[3:],
+			plan:  Plan{Rates: []float64{0.15, 0.05}},
+			total: "33.33",
+			expected: []Allocation{
+				{AffiliateID: chain[3], Level: 1, Amount: decimal.RequireFromString("3.33")},
+				{AffiliateID: chain[4], Level: 0, Amount: decimal.RequireFromString("1.67")},
+			},
+		},
+		{
+			name:     "No rates",
+			chain:    chain,
+			plan:     Plan{},
+			expected: []Allocation{},
+		},
+		{
+			name:     "No affiliates",
+			chain:    nil,
+			plan:     plan,
+			expected: []Allocation{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total := decimal.NewFromInt(1000)
+			if tt.total != "" {
+				total = decimal.RequireFromString(tt.total)
+			}
+
+			allocations := Calculate(tt.chain, total, tt.plan)
+			require.Len(t, allocations, len(tt.expected))
+			for i, allocation := range allocations {
+				require.Equal(t, tt.expected[i].AffiliateID, allocation.AffiliateID)
+				require.Equal(t, tt.expected[i].Level, allocation.Level)
+				require.True(t, tt.expected[i].Amount.Equal(allocation.Amount), "expected %s, got %s", tt.expected[i].Amount, allocation.Amount)
+			}
+		})
+	}
+}
+
+// scenario is a random valid plan, chain length and order total.
+type scenario struct {
+	Plan  Plan
+	Depth int
+	Total decimal.Decimal
+}
+
+func (scenario) Generate(r *rand.Rand, size int) reflect.Value {
+	rates := make([]float64, 1+r.Intn(6))
+	for i := range rates {
+		rates[i] = 0.01 + r.Float64()*0.99
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(rates)))
+
+	return reflect.ValueOf(scenario{
+		Plan:  Plan{Rates: rates},
+		Depth: r.Intn(10),
+		Total: decimal.New(r.Int63n(10000000), -2),
+	})
+}
+
+func TestCalculateProperties(t *testing.T) {
+	properties := map[string]func(s scenario) bool{
+		"allocations never exceed the top rate times the total rounded to the cent": func(s scenario) bool {
+			allocations := Calculate(newChain(s.Depth), s.Total, s.Plan)
+			limit := helpers.RoundMoney(decimal.NewFromFloat(s.Plan.Rates[0]).Mul(s.Total))
+			return sumAllocations(allocations).LessThanOrEqual(limit)
+		},
+		"deeper chains never increase the total payout": func(s scenario) bool {
+			chain := newChain(s.Depth + 2)
+			shallow := sumAllocations(Calculate(chain[1:], s.Total, s.Plan))
+			deep := sumAllocations(Calculate(chain, s.Total, s.Plan))
+			return deep.LessThanOrEqual(shallow)
+		},
+		"every allocation is positive and goes to a distinct chain member": func(s scenario) bool {
+			chain := newChain(s.Depth)
+			members := make(map[pgtype.UUID]bool, len(chain))
+			for _, id := range chain {
+				members[id] = true
+			}
+			for _, allocation := range Calculate(chain, s.Total, s.Plan) {
+				if !allocation.Amount.IsPositive() || !members[allocation.AffiliateID] {
+					return false
+				}
+				delete(members, allocation.AffiliateID)
+			}
+			return true
+		},
+		"only levels covered by the plan are paid": func(s scenario) bool {
+			for _, allocation := range Calculate(newChain(s.Depth), s.Total, s.Plan) {
+				if allocation.Level >= len(s.Plan.Rates) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+
+	for name, property := range properties {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, quick.Check(property, &quick.Config{MaxCount: 1000}))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buranasakS/trading_application/auth"
+)
+
+const devKeyID = "dev"
+
+// LoadKeySet reads the access token keys from JWT_KEYS_DIR, one PEM file per
+// key named <kid>.pem, and signs with the key named by JWT_SIGNING_KEY_ID.
+// Every other key in the directory is still accepted and published, which
+// is how keys are rotated without logging anyone out:
+//
+//  1. add the new key to the directory of every instance;
+//  2. point JWT_SIGNING_KEY_ID at it;
+//  3. remove the old key once the access tokens it signed have expired.
+//
+// Without JWT_KEYS_DIR a throwaway key is generated, so tokens do not
+// survive a restart and other services cannot verify them reliably.
+func LoadKeySet() (*auth.KeySet, error) {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		log.Println("JWT_KEYS_DIR is not set, signing access tokens with a temporary key")
+		key, err := auth.GenerateKey(devKeyID)
+		if err != nil {
+			return nil, err
+		}
+		return auth.NewKeySet(devKeyID, key)
+	}
+
+	signingID := os.Getenv("JWT_SIGNING_KEY_ID")
+	if signingID == "" {
+		return nil, errors.New("JWT_SIGNING_KEY_ID must be set with JWT_KEYS_DIR")
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	keys := make([]auth.Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := auth.ParseKeyPEM(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	keySet, err := auth.NewKeySet(signingID, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_KEYS_DIR %s: %w", dir, err)
+	}
+
+	return keySet, nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buranasakS/trading_application/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKey(t *testing.T, dir, id string, public bool) {
+	key, err := auth.GenerateKey(id)
+	require.NoError(t, err)
+
+	block := &pem.Block{Type: "PRIVATE KEY"}
+	if public {
+		block.Type = "PUBLIC KEY"
+		block.Bytes, err = x509.MarshalPKIXPublicKey(key.Public)
+	} else {
+		block.Bytes, err = x509.MarshalPKCS8PrivateKey(key.Private)
+	}
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, id+".pem"), pem.EncodeToMemory(block), 0o600))
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "2026-09", true)
+	writeKey(t, dir, "2026-10", false)
+
+	tests := []struct {
+		name          string
+		dir           string
+		signingID     string
+		expectedID    string
+		expectedKeys  int
+		expectedError string
+	}{
+		{name: "Temporary key", expectedID: "dev", expectedKeys: 1},
+		{name: "Keys from the directory", dir: dir, signingID: "2026-10", expectedID: "2026-10", expectedKeys: 2},
+		{name: "No signing key ID", dir: dir, expectedError: "JWT_SIGNING_KEY_ID must be set with JWT_KEYS_DIR"},
+		{name: "Unknown signing key", dir: dir, signingID: "2026-11", expectedError: "JWT_KEYS_DIR " + dir + ": signing key 2026-11 not found"},
+		{name: "Signing key without private key", dir: dir, signingID: "2026-09", expectedError: "JWT_KEYS_DIR " + dir + ": signing key 2026-09 has no private key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_KEYS_DIR", tt.dir)
+			t.Setenv("JWT_SIGNING_KEY_ID", tt.signingID)
+
+			keys, err := LoadKeySet()
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedID, keys.SigningKeyID())
+			require.Len(t, keys.JWKS().Keys, tt.expectedKeys)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDatabaseConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		expected      DatabaseConfig
+		expectedError string
+	}{
+		{
+			name: "Defaults",
+			expected: DatabaseConfig{
+				Source:         defaultDBSource,
+				ConnectTimeout: 10 * time.Second,
+			},
+		},
+		{
+			name: "All settings",
+			env: map[string]string{
+				"DB_SOURCE":              "postgresql://app@db:5432/trading",
+				"DB_MAX_CONNS":           "20",
+				"DB_MIN_CONNS":           "2",
+				"DB_MAX_CONN_LIFETIME":   "1h",
+				"DB_MAX_CONN_IDLE_TIME":  "30m",
+				"DB_HEALTH_CHECK_PERIOD": "1m",
+				"DB_CONNECT_TIMEOUT":     "5s",
+			},
+			expected: DatabaseConfig{
+				Source:            "postgresql://app@db:5432/trading",
+				MaxConns:          20,
+				MinConns:          2,
+				MaxConnLifetime:   time.Hour,
+				MaxConnIdleTime:   30 * time.Minute,
+				HealthCheckPeriod: time.Minute,
+				ConnectTimeout:    5 * time.Second,
+			},
+		},
+		{
+			name:          "Invalid max conns",
+			env:           map[string]string{"DB_MAX_CONNS": "many"},
+			expectedError: "DB_MAX_CONNS must be a non-negative integer",
+		},
+		{
+			name:          "Invalid duration",
+			env:           map[string]string{"DB_MAX_CONN_LIFETIME": "-1h"},
+			expectedError: "DB_MAX_CONN_LIFETIME must be a non-negative duration",
+		},
+	}
+
+	keys := []string{"DB_SOURCE", "DB_MAX_CONNS", "DB_MIN_CONNS", "DB_MAX_CONN_LIFETIME", "DB_MAX_CONN_IDLE_TIME", "DB_HEALTH_CHECK_PERIOD", "DB_CONNECT_TIMEOUT"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range keys {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := LoadDatabaseConfig()
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
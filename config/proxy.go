@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// LoadTrustedProxies reads the proxies whose X-Forwarded-For and X-Real-IP
+// headers are believed from TRUSTED_PROXIES, a comma separated list of IPs
+// and CIDRs. Without it no proxy is trusted and the client IP is the address
+// of the connection, so a client cannot choose its own IP.
+func LoadTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
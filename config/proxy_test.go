@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{name: "None", value: "", expected: nil},
+		{name: "One", value: "10.0.0.1", expected: []string{"10.0.0.1"}},
+		{name: "List", value: " 10.0.0.0/8, 192.168.1.1 ,", expected: []string{"10.0.0.0/8", "192.168.1.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", tt.value)
+			require.Equal(t, tt.expected, LoadTrustedProxies())
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+const defaultReservationSweepInterval = time.Minute
+
+// LoadReservationSweepInterval reads how often expired stock reservations
+// are released from RESERVATION_SWEEP_INTERVAL, e.g. "30s". It defaults to
+// one minute.
+func LoadReservationSweepInterval() (time.Duration, error) {
+	interval, err := envDuration("RESERVATION_SWEEP_INTERVAL", defaultReservationSweepInterval)
+	if err != nil {
+		return 0, err
+	}
+	if interval == 0 {
+		return 0, errors.New("RESERVATION_SWEEP_INTERVAL must be a positive duration")
+	}
+
+	return interval, nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadReservationSweepInterval(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expected      time.Duration
+		expectedError string
+	}{
+		{name: "Default", expected: time.Minute},
+		{name: "Custom", value: "30s", expected: 30 * time.Second},
+		{name: "Zero", value: "0s", expectedError: "RESERVATION_SWEEP_INTERVAL must be a positive duration"},
+		{name: "Invalid", value: "soon", expectedError: "RESERVATION_SWEEP_INTERVAL must be a non-negative duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RESERVATION_SWEEP_INTERVAL", tt.value)
+
+			interval, err := LoadReservationSweepInterval()
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, interval)
+		})
+	}
+}
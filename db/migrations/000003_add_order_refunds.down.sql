@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS commissions_order_id_idx;
+
+ALTER TABLE commissions DROP COLUMN IF EXISTS created_at;
+ALTER TABLE commissions DROP COLUMN IF EXISTS type;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS refunded_quantity;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS refunded_amount;
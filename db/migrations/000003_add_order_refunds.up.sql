@@ -0,0 +1,8 @@
+ALTER TABLE orders ADD COLUMN refunded_amount DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE order_items ADD COLUMN refunded_quantity INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE commissions ADD COLUMN type TEXT NOT NULL DEFAULT 'commission';
+ALTER TABLE commissions ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX commissions_order_id_idx ON commissions (order_id);
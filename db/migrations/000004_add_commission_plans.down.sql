@@ -0,0 +1,3 @@
+ALTER TABLE commissions DROP COLUMN IF EXISTS plan_id;
+
+DROP TABLE IF EXISTS commission_plans;
@@ -0,0 +1,17 @@
+CREATE TABLE commission_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    rates DOUBLE PRECISION[] NOT NULL,
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX commission_plans_effective_from_idx ON commission_plans (effective_from);
+
+INSERT INTO commission_plans (name, rates, effective_from)
+VALUES ('Default', ARRAY[0.20, 0.15, 0.10, 0.05], '1970-01-01T00:00:00Z');
+
+ALTER TABLE commissions ADD COLUMN plan_id UUID;
+ALTER TABLE commissions ADD FOREIGN KEY (plan_id) REFERENCES commission_plans(id);
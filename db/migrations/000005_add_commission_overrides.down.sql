@@ -0,0 +1,5 @@
+ALTER TABLE commissions DROP COLUMN IF EXISTS rule;
+
+DROP TABLE IF EXISTS commission_overrides;
+
+ALTER TABLE products DROP COLUMN IF EXISTS category;
@@ -0,0 +1,18 @@
+ALTER TABLE products ADD COLUMN category TEXT;
+
+CREATE TABLE commission_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID,
+    category TEXT,
+    rates DOUBLE PRECISION[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CHECK ((product_id IS NULL) <> (category IS NULL))
+);
+
+CREATE UNIQUE INDEX commission_overrides_product_id_idx ON commission_overrides (product_id) WHERE product_id IS NOT NULL;
+CREATE UNIQUE INDEX commission_overrides_category_idx ON commission_overrides (category) WHERE category IS NOT NULL;
+
+ALTER TABLE commissions ADD COLUMN rule TEXT;
+UPDATE commissions SET rule = 'plan' WHERE type = 'commission';
@@ -0,0 +1,10 @@
+ALTER TABLE order_items ALTER COLUMN total_price TYPE DOUBLE PRECISION;
+ALTER TABLE order_items ALTER COLUMN unit_price TYPE DOUBLE PRECISION;
+
+ALTER TABLE orders ALTER COLUMN refunded_amount TYPE DOUBLE PRECISION;
+ALTER TABLE orders ALTER COLUMN total_cost TYPE DOUBLE PRECISION;
+
+ALTER TABLE commissions ALTER COLUMN amount TYPE DOUBLE PRECISION;
+ALTER TABLE products ALTER COLUMN price TYPE DOUBLE PRECISION;
+ALTER TABLE affiliates ALTER COLUMN balance TYPE DOUBLE PRECISION;
+ALTER TABLE users ALTER COLUMN balance TYPE DOUBLE PRECISION;
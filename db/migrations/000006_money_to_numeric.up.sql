@@ -0,0 +1,12 @@
+-- Money is stored as NUMERIC(20, 2). Existing values are rounded half away
+-- from zero to the cent, the same rule the application uses.
+ALTER TABLE users ALTER COLUMN balance TYPE NUMERIC(20, 2) USING round(balance::NUMERIC, 2);
+ALTER TABLE affiliates ALTER COLUMN balance TYPE NUMERIC(20, 2) USING round(balance::NUMERIC, 2);
+ALTER TABLE products ALTER COLUMN price TYPE NUMERIC(20, 2) USING round(price::NUMERIC, 2);
+ALTER TABLE commissions ALTER COLUMN amount TYPE NUMERIC(20, 2) USING round(amount::NUMERIC, 2);
+
+ALTER TABLE orders ALTER COLUMN total_cost TYPE NUMERIC(20, 2) USING round(total_cost::NUMERIC, 2);
+ALTER TABLE orders ALTER COLUMN refunded_amount TYPE NUMERIC(20, 2) USING round(refunded_amount::NUMERIC, 2);
+
+ALTER TABLE order_items ALTER COLUMN unit_price TYPE NUMERIC(20, 2) USING round(unit_price::NUMERIC, 2);
+ALTER TABLE order_items ALTER COLUMN total_price TYPE NUMERIC(20, 2) USING round(total_price::NUMERIC, 2);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS ledger_entries_balanced ON ledger_entries;
+DROP FUNCTION IF EXISTS check_ledger_transaction_balanced();
+
+DROP TABLE IF EXISTS ledger_entries;
@@ -0,0 +1,47 @@
+-- Every balance movement is a ledger transaction made of postings that sum to
+-- zero. user and affiliate postings carry the account_id of the holder; the
+-- other accounts (external, sales, commission_expense) belong to the platform.
+-- A positive amount increases the account's balance.
+CREATE TABLE ledger_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL,
+    kind TEXT NOT NULL,
+    account TEXT NOT NULL,
+    account_id UUID,
+    amount NUMERIC(20, 2) NOT NULL,
+    order_id UUID,
+    commission_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (order_id) REFERENCES orders(id),
+    FOREIGN KEY (commission_id) REFERENCES commissions(id),
+    CHECK ((account IN ('user', 'affiliate')) = (account_id IS NOT NULL))
+);
+
+CREATE INDEX ledger_entries_transaction_id_idx ON ledger_entries (transaction_id);
+CREATE INDEX ledger_entries_account_idx ON ledger_entries (account, account_id, created_at);
+
+-- Existing balances become opening entries against the external account.
+WITH opening AS MATERIALIZED (
+    SELECT gen_random_uuid() AS transaction_id, 'user' AS account, id, balance FROM users WHERE balance <> 0
+    UNION ALL
+    SELECT gen_random_uuid(), 'affiliate', id, balance FROM affiliates WHERE balance <> 0
+)
+INSERT INTO ledger_entries (transaction_id, kind, account, account_id, amount)
+SELECT transaction_id, 'opening_balance', account, id, balance FROM opening
+UNION ALL
+SELECT transaction_id, 'opening_balance', 'external', NULL, -balance FROM opening;
+
+CREATE FUNCTION check_ledger_transaction_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_entries WHERE transaction_id = NEW.transaction_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.transaction_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Checked at commit so all postings of a transaction can be inserted first.
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_ledger_transaction_balanced();
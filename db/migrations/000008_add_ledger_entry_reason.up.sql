@@ -0,0 +1,11 @@
+ALTER TABLE ledger_entries ADD COLUMN reason TEXT;
+
+UPDATE ledger_entries SET reason = CASE kind
+    WHEN 'opening_balance' THEN 'Opening balance'
+    WHEN 'top_up' THEN 'Balance top-up'
+    WHEN 'deduction' THEN 'Balance deduction'
+    WHEN 'purchase' THEN 'Order purchase'
+    WHEN 'refund' THEN 'Order refund'
+    WHEN 'commission' THEN 'Commission earned'
+    WHEN 'commission_reversal' THEN 'Commission reversed on refund'
+END;
@@ -0,0 +1,10 @@
+-- Responses of successful writes made with an Idempotency-Key header. The row
+-- is written in the same transaction as the write, so a key is stored if and
+-- only if its request took effect.
+CREATE TABLE idempotency_keys (
+    key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    status_code INT NOT NULL,
+    response_body JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
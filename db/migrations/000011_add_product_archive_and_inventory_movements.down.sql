@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS inventory_movements;
+ALTER TABLE products DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,16 @@
+-- Archived products can no longer be bought but stay in place so past orders
+-- still resolve.
+ALTER TABLE products ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- Changes to the stock of a product. quantity is positive for stock coming in.
+CREATE TABLE inventory_movements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL,
+    kind TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX inventory_movements_product_id_idx ON inventory_movements (product_id, created_at);
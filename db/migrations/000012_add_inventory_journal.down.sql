@@ -0,0 +1,4 @@
+DELETE FROM inventory_movements WHERE kind = 'opening_balance';
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS order_id;
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS actor;
+ALTER TABLE inventory_movements RENAME COLUMN reason TO note;
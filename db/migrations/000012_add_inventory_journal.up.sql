@@ -0,0 +1,13 @@
+ALTER TABLE inventory_movements RENAME COLUMN note TO reason;
+ALTER TABLE inventory_movements
+    ADD COLUMN actor TEXT,
+    ADD COLUMN order_id UUID REFERENCES orders(id);
+
+-- Stock that no movement explains yet becomes an opening movement, so the
+-- movements of every product add up to its quantity.
+INSERT INTO inventory_movements (product_id, kind, quantity, reason)
+SELECT p.id, 'opening_balance', p.quantity - COALESCE(SUM(m.quantity), 0), 'Opening balance'
+FROM products p
+LEFT JOIN inventory_movements m ON m.product_id = p.id
+GROUP BY p.id, p.quantity
+HAVING p.quantity - COALESCE(SUM(m.quantity), 0) <> 0;
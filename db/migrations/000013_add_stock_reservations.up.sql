@@ -0,0 +1,22 @@
+-- A reservation holds stock for a user without selling it. While it is
+-- active and not expired it counts against the product, so the stock others
+-- can buy is quantity minus the active reservations.
+CREATE TABLE stock_reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    quantity INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMPTZ NOT NULL,
+    order_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (order_id) REFERENCES orders(id),
+    CHECK (quantity > 0),
+    CHECK (status IN ('active', 'confirmed', 'released', 'expired'))
+);
+
+CREATE INDEX stock_reservations_active_product_idx ON stock_reservations (product_id) WHERE status = 'active';
+CREATE INDEX stock_reservations_active_expires_at_idx ON stock_reservations (expires_at) WHERE status = 'active';
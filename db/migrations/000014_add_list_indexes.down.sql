@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS users_affiliate_id_idx;
+DROP INDEX IF EXISTS commissions_created_at_idx;
+DROP INDEX IF EXISTS commissions_affiliate_id_idx;
+DROP INDEX IF EXISTS affiliates_master_affiliate_idx;
+DROP INDEX IF EXISTS affiliates_name_idx;
+DROP INDEX IF EXISTS products_price_idx;
+DROP INDEX IF EXISTS products_name_idx;
@@ -0,0 +1,9 @@
+-- Keyset paging sorts by (field, id); these indexes serve the sortable
+-- fields and the filters of the list endpoints.
+CREATE INDEX products_name_idx ON products (name, id) WHERE archived_at IS NULL;
+CREATE INDEX products_price_idx ON products (price, id) WHERE archived_at IS NULL;
+CREATE INDEX affiliates_name_idx ON affiliates (name, id);
+CREATE INDEX affiliates_master_affiliate_idx ON affiliates (master_affiliate);
+CREATE INDEX commissions_affiliate_id_idx ON commissions (affiliate_id, created_at, id);
+CREATE INDEX commissions_created_at_idx ON commissions (created_at, id);
+CREATE INDEX users_affiliate_id_idx ON users (affiliate_id);
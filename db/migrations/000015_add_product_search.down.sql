@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS products_tags_idx;
+DROP INDEX IF EXISTS products_category_idx;
+DROP INDEX IF EXISTS products_search_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS tags;
+ALTER TABLE products DROP COLUMN IF EXISTS description;
@@ -0,0 +1,11 @@
+-- Products get a description and free-form tags next to their category.
+-- Search matches the words of the name and the description; the GIN index
+-- must use the same expression as the search queries to be picked up.
+ALTER TABLE products ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE products ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX products_search_idx ON products
+    USING GIN (to_tsvector('english', name || ' ' || description))
+    WHERE archived_at IS NULL;
+CREATE INDEX products_category_idx ON products (category) WHERE archived_at IS NULL;
+CREATE INDEX products_tags_idx ON products USING GIN (tags) WHERE archived_at IS NULL;
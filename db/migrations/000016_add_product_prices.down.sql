@@ -0,0 +1,3 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS price_id;
+ALTER TABLE order_items DROP COLUMN IF EXISTS list_price;
+DROP TABLE IF EXISTS product_prices;
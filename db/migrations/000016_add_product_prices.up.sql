@@ -0,0 +1,25 @@
+-- Scheduled prices of a product. products.price stays the list price; a
+-- scheduled price replaces it while its window is open. When windows
+-- overlap, the one that started last wins, so a sale can be scheduled on
+-- top of a longer price change.
+CREATE TABLE product_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL,
+    price NUMERIC(20, 2) NOT NULL,
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    CHECK (price > 0),
+    CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX product_prices_product_id_idx ON product_prices (product_id, effective_from);
+
+-- Every order item keeps the list price next to the price paid and the
+-- scheduled price that was applied, if any.
+ALTER TABLE order_items ADD COLUMN list_price NUMERIC(20, 2);
+UPDATE order_items SET list_price = unit_price;
+ALTER TABLE order_items ALTER COLUMN list_price SET NOT NULL;
+ALTER TABLE order_items ADD COLUMN price_id UUID;
+ALTER TABLE order_items ADD FOREIGN KEY (price_id) REFERENCES product_prices(id);
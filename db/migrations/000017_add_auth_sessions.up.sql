@@ -0,0 +1,27 @@
+-- A login session. Its id is the jti of every access token issued for it,
+-- so revoking the session invalidates those tokens before they expire.
+CREATE TABLE auth_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX auth_sessions_user_id_idx ON auth_sessions (user_id) WHERE revoked_at IS NULL;
+
+-- Refresh tokens are stored as SHA-256 hashes. Each refresh rotates the
+-- token: the old one is marked rotated and a new one is issued for the same
+-- session. Presenting a rotated token again means it leaked, and the whole
+-- session is revoked.
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    rotated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (session_id) REFERENCES auth_sessions(id)
+);
+
+CREATE INDEX refresh_tokens_session_id_idx ON refresh_tokens (session_id);
@@ -0,0 +1,6 @@
+-- The role a user acts with. Everyone registers as a customer; the first
+-- admin has to be promoted by hand:
+--   UPDATE users SET role = 'admin' WHERE username = '...';
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'customer';
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('customer', 'affiliate', 'support', 'admin'));
@@ -0,0 +1,12 @@
+-- Failed logins per username and per client IP. The count starts over when
+-- a failure comes after a quiet window, and is cleared for the username by a
+-- successful login or an admin unlock. Usernames that do not exist are
+-- tracked the same way so lockouts do not reveal which ones do.
+CREATE TABLE login_failures (
+    scope TEXT NOT NULL,
+    key TEXT NOT NULL,
+    failures INTEGER NOT NULL,
+    last_failure_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, key),
+    CONSTRAINT login_failures_scope_check CHECK (scope IN ('username', 'ip'))
+);
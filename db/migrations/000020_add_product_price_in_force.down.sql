@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS product_price_in_force(UUID, TIMESTAMPTZ);
@@ -0,0 +1,14 @@
+-- The scheduled price of a product in force at a moment, the one rule every
+-- query that prices a product goes through. Of overlapping windows the one
+-- that started last wins. The moment is always passed in by the caller so a
+-- cart, a listing and a checkout priced at the same time agree.
+CREATE FUNCTION product_price_in_force(p_product_id UUID, p_at TIMESTAMPTZ)
+RETURNS SETOF product_prices
+LANGUAGE sql STABLE AS $$
+    SELECT * FROM product_prices
+    WHERE product_id = p_product_id
+      AND effective_from <= p_at
+      AND (effective_to IS NULL OR effective_to > p_at)
+    ORDER BY effective_from DESC, created_at DESC
+    LIMIT 1
+$$;
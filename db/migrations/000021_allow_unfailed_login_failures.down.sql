@@ -0,0 +1,2 @@
+DELETE FROM login_failures WHERE last_failure_at IS NULL;
+ALTER TABLE login_failures ALTER COLUMN last_failure_at SET NOT NULL;
@@ -0,0 +1,4 @@
+-- A login locks the rows of its username and client IP, creating them with
+-- no failures, so attempts on either are checked and counted one at a time.
+-- Such a row has no last failure yet.
+ALTER TABLE login_failures ALTER COLUMN last_failure_at DROP NOT NULL;
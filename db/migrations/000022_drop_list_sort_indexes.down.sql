@@ -0,0 +1,4 @@
+CREATE INDEX products_name_idx ON products (name, id) WHERE archived_at IS NULL;
+CREATE INDEX products_price_idx ON products (price, id) WHERE archived_at IS NULL;
+CREATE INDEX affiliates_name_idx ON affiliates (name, id);
+CREATE INDEX commissions_created_at_idx ON commissions (created_at, id);
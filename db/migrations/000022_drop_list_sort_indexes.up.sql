@@ -0,0 +1,7 @@
+-- The lists pick their sort with CASE expressions, which no index can serve,
+-- and products are sorted by the price in force rather than products.price,
+-- so the indexes on the sortable fields are never used.
+DROP INDEX IF EXISTS products_name_idx;
+DROP INDEX IF EXISTS products_price_idx;
+DROP INDEX IF EXISTS affiliates_name_idx;
+DROP INDEX IF EXISTS commissions_created_at_idx;
@@ -0,0 +1 @@
+ALTER TABLE commissions DROP COLUMN IF EXISTS rates;
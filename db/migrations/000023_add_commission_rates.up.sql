@@ -0,0 +1,5 @@
+-- Overrides are edited in place and plans can be replaced, so each commission
+-- keeps the level rates it was calculated with. Rows paid under an override
+-- before this column existed cannot be recovered and stay NULL.
+ALTER TABLE commissions ADD COLUMN rates DOUBLE PRECISION[];
+UPDATE commissions c SET rates = p.rates FROM commission_plans p WHERE c.plan_id = p.id;
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+)
+
+// FakeStore is a db.Store for handler tests. Queries, including those made
+// inside ExecTx, go to the wrapped MockQuerier, and every transaction is
+// counted as committed or rolled back so tests can assert on the outcome.
+type FakeStore struct {
+	*MockQuerier
+
+	// CommitErr simulates the database failing to commit a transaction.
+	CommitErr error
+
+	Commits   int
+	Rollbacks int
+}
+
+func NewFakeStore(q *MockQuerier) *FakeStore {
+	return &FakeStore{MockQuerier: q}
+}
+
+func (s *FakeStore) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	if err := fn(s.MockQuerier); err != nil {
+		s.Rollbacks++
+		return err
+	}
+
+	if s.CommitErr != nil {
+		s.Rollbacks++
+		return s.CommitErr
+	}
+
+	s.Commits++
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	db "github.com/buranasakS/trading_application/db/sqlc"
 	gomock "github.com/golang/mock/gomock"
 	pgtype "github.com/jackc/pgx/v5/pgtype"
+	decimal "github.com/shopspring/decimal"
 )
 
 // MockQuerier is a mock of Querier interface.
@@ -50,6 +51,51 @@ func (mr *MockQuerierMockRecorder) AddAffiliateBalance(ctx, arg interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAffiliateBalance", reflect.TypeOf((*MockQuerier)(nil).AddAffiliateBalance), ctx, arg)
 }
 
+// AddCartItem mocks base method.
+func (m *MockQuerier) AddCartItem(ctx context.Context, arg db.AddCartItemParams) (db.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCartItem", ctx, arg)
+	ret0, _ := ret[0].(db.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCartItem indicates an expected call of AddCartItem.
+func (mr *MockQuerierMockRecorder) AddCartItem(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCartItem", reflect.TypeOf((*MockQuerier)(nil).AddCartItem), ctx, arg)
+}
+
+// AddOrderItemRefundedQuantity mocks base method.
+func (m *MockQuerier) AddOrderItemRefundedQuantity(ctx context.Context, arg db.AddOrderItemRefundedQuantityParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrderItemRefundedQuantity", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOrderItemRefundedQuantity indicates an expected call of AddOrderItemRefundedQuantity.
+func (mr *MockQuerierMockRecorder) AddOrderItemRefundedQuantity(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrderItemRefundedQuantity", reflect.TypeOf((*MockQuerier)(nil).AddOrderItemRefundedQuantity), ctx, arg)
+}
+
+// AddProductQuantity mocks base method.
+func (m *MockQuerier) AddProductQuantity(ctx context.Context, arg db.AddProductQuantityParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProductQuantity", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProductQuantity indicates an expected call of AddProductQuantity.
+func (mr *MockQuerierMockRecorder) AddProductQuantity(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProductQuantity", reflect.TypeOf((*MockQuerier)(nil).AddProductQuantity), ctx, arg)
+}
+
 // AddUserBalance mocks base method.
 func (m *MockQuerier) AddUserBalance(ctx context.Context, arg db.AddUserBalanceParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +111,36 @@ func (mr *MockQuerierMockRecorder) AddUserBalance(ctx, arg interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserBalance", reflect.TypeOf((*MockQuerier)(nil).AddUserBalance), ctx, arg)
 }
 
+// ArchiveProduct mocks base method.
+func (m *MockQuerier) ArchiveProduct(ctx context.Context, id pgtype.UUID) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveProduct", ctx, id)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveProduct indicates an expected call of ArchiveProduct.
+func (mr *MockQuerierMockRecorder) ArchiveProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveProduct", reflect.TypeOf((*MockQuerier)(nil).ArchiveProduct), ctx, id)
+}
+
+// CheckOutCart mocks base method.
+func (m *MockQuerier) CheckOutCart(ctx context.Context, arg db.CheckOutCartParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckOutCart", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckOutCart indicates an expected call of CheckOutCart.
+func (mr *MockQuerierMockRecorder) CheckOutCart(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOutCart", reflect.TypeOf((*MockQuerier)(nil).CheckOutCart), ctx, arg)
+}
+
 // CheckUserExists mocks base method.
 func (m *MockQuerier) CheckUserExists(ctx context.Context, id pgtype.UUID) (bool, error) {
 	m.ctrl.T.Helper()
@@ -80,289 +156,1427 @@ func (mr *MockQuerierMockRecorder) CheckUserExists(ctx, id interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUserExists", reflect.TypeOf((*MockQuerier)(nil).CheckUserExists), ctx, id)
 }
 
-// CountUsers mocks base method.
-func (m *MockQuerier) CountUsers(ctx context.Context) (int64, error) {
+// ConfirmStockReservation mocks base method.
+func (m *MockQuerier) ConfirmStockReservation(ctx context.Context, arg db.ConfirmStockReservationParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CountUsers", ctx)
+	ret := m.ctrl.Call(m, "ConfirmStockReservation", ctx, arg)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CountUsers indicates an expected call of CountUsers.
-func (mr *MockQuerierMockRecorder) CountUsers(ctx interface{}) *gomock.Call {
+// ConfirmStockReservation indicates an expected call of ConfirmStockReservation.
+func (mr *MockQuerierMockRecorder) ConfirmStockReservation(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockQuerier)(nil).CountUsers), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmStockReservation", reflect.TypeOf((*MockQuerier)(nil).ConfirmStockReservation), ctx, arg)
 }
 
-// CreateAffiliate mocks base method.
-func (m *MockQuerier) CreateAffiliate(ctx context.Context, arg db.CreateAffiliateParams) (db.Affiliate, error) {
+// CountAccountTransactions mocks base method.
+func (m *MockQuerier) CountAccountTransactions(ctx context.Context, arg db.CountAccountTransactionsParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAffiliate", ctx, arg)
-	ret0, _ := ret[0].(db.Affiliate)
+	ret := m.ctrl.Call(m, "CountAccountTransactions", ctx, arg)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateAffiliate indicates an expected call of CreateAffiliate.
-func (mr *MockQuerierMockRecorder) CreateAffiliate(ctx, arg interface{}) *gomock.Call {
+// CountAccountTransactions indicates an expected call of CountAccountTransactions.
+func (mr *MockQuerierMockRecorder) CountAccountTransactions(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAffiliate", reflect.TypeOf((*MockQuerier)(nil).CreateAffiliate), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAccountTransactions", reflect.TypeOf((*MockQuerier)(nil).CountAccountTransactions), ctx, arg)
 }
 
-// CreateCommission mocks base method.
-func (m *MockQuerier) CreateCommission(ctx context.Context, arg db.CreateCommissionParams) (db.Commission, error) {
+// CountAffiliates mocks base method.
+func (m *MockQuerier) CountAffiliates(ctx context.Context, arg db.CountAffiliatesParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateCommission", ctx, arg)
-	ret0, _ := ret[0].(db.Commission)
+	ret := m.ctrl.Call(m, "CountAffiliates", ctx, arg)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateCommission indicates an expected call of CreateCommission.
-func (mr *MockQuerierMockRecorder) CreateCommission(ctx, arg interface{}) *gomock.Call {
+// CountAffiliates indicates an expected call of CountAffiliates.
+func (mr *MockQuerierMockRecorder) CountAffiliates(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommission", reflect.TypeOf((*MockQuerier)(nil).CreateCommission), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAffiliates", reflect.TypeOf((*MockQuerier)(nil).CountAffiliates), ctx, arg)
 }
 
-// CreateProduct mocks base method.
-func (m *MockQuerier) CreateProduct(ctx context.Context, arg db.CreateProductParams) (db.Product, error) {
+// CountCommissions mocks base method.
+func (m *MockQuerier) CountCommissions(ctx context.Context, arg db.CountCommissionsParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateProduct", ctx, arg)
-	ret0, _ := ret[0].(db.Product)
+	ret := m.ctrl.Call(m, "CountCommissions", ctx, arg)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateProduct indicates an expected call of CreateProduct.
-func (mr *MockQuerierMockRecorder) CreateProduct(ctx, arg interface{}) *gomock.Call {
+// CountCommissions indicates an expected call of CountCommissions.
+func (mr *MockQuerierMockRecorder) CountCommissions(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockQuerier)(nil).CreateProduct), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCommissions", reflect.TypeOf((*MockQuerier)(nil).CountCommissions), ctx, arg)
 }
 
-// CreateUser mocks base method.
-func (m *MockQuerier) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+// CountCommissionsByPlanID mocks base method.
+func (m *MockQuerier) CountCommissionsByPlanID(ctx context.Context, planID pgtype.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", ctx, arg)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "CountCommissionsByPlanID", ctx, planID)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockQuerierMockRecorder) CreateUser(ctx, arg interface{}) *gomock.Call {
+// CountCommissionsByPlanID indicates an expected call of CountCommissionsByPlanID.
+func (mr *MockQuerierMockRecorder) CountCommissionsByPlanID(ctx, planID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockQuerier)(nil).CreateUser), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCommissionsByPlanID", reflect.TypeOf((*MockQuerier)(nil).CountCommissionsByPlanID), ctx, planID)
 }
 
-// DeductProductQuantity mocks base method.
-func (m *MockQuerier) DeductProductQuantity(ctx context.Context, arg db.DeductProductQuantityParams) (int64, error) {
+// CountInventoryMovements mocks base method.
+func (m *MockQuerier) CountInventoryMovements(ctx context.Context, productID pgtype.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeductProductQuantity", ctx, arg)
+	ret := m.ctrl.Call(m, "CountInventoryMovements", ctx, productID)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DeductProductQuantity indicates an expected call of DeductProductQuantity.
-func (mr *MockQuerierMockRecorder) DeductProductQuantity(ctx, arg interface{}) *gomock.Call {
+// CountInventoryMovements indicates an expected call of CountInventoryMovements.
+func (mr *MockQuerierMockRecorder) CountInventoryMovements(ctx, productID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductProductQuantity", reflect.TypeOf((*MockQuerier)(nil).DeductProductQuantity), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountInventoryMovements", reflect.TypeOf((*MockQuerier)(nil).CountInventoryMovements), ctx, productID)
 }
 
-// DeductUserBalance mocks base method.
-func (m *MockQuerier) DeductUserBalance(ctx context.Context, arg db.DeductUserBalanceParams) (int64, error) {
+// CountOrdersByUserID mocks base method.
+func (m *MockQuerier) CountOrdersByUserID(ctx context.Context, userID pgtype.UUID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeductUserBalance", ctx, arg)
+	ret := m.ctrl.Call(m, "CountOrdersByUserID", ctx, userID)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DeductUserBalance indicates an expected call of DeductUserBalance.
-func (mr *MockQuerierMockRecorder) DeductUserBalance(ctx, arg interface{}) *gomock.Call {
+// CountOrdersByUserID indicates an expected call of CountOrdersByUserID.
+func (mr *MockQuerierMockRecorder) CountOrdersByUserID(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductUserBalance", reflect.TypeOf((*MockQuerier)(nil).DeductUserBalance), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOrdersByUserID", reflect.TypeOf((*MockQuerier)(nil).CountOrdersByUserID), ctx, userID)
 }
 
-// GetAffiliateByID mocks base method.
-func (m *MockQuerier) GetAffiliateByID(ctx context.Context, id pgtype.UUID) (db.Affiliate, error) {
+// CountProducts mocks base method.
+func (m *MockQuerier) CountProducts(ctx context.Context, arg db.CountProductsParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAffiliateByID", ctx, id)
+	ret := m.ctrl.Call(m, "CountProducts", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountProducts indicates an expected call of CountProducts.
+func (mr *MockQuerierMockRecorder) CountProducts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountProducts", reflect.TypeOf((*MockQuerier)(nil).CountProducts), ctx, arg)
+}
+
+// CountSearchProducts mocks base method.
+func (m *MockQuerier) CountSearchProducts(ctx context.Context, arg db.CountSearchProductsParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSearchProducts", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSearchProducts indicates an expected call of CountSearchProducts.
+func (mr *MockQuerierMockRecorder) CountSearchProducts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSearchProducts", reflect.TypeOf((*MockQuerier)(nil).CountSearchProducts), ctx, arg)
+}
+
+// CountUsers mocks base method.
+func (m *MockQuerier) CountUsers(ctx context.Context, arg db.CountUsersParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsers", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsers indicates an expected call of CountUsers.
+func (mr *MockQuerierMockRecorder) CountUsers(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockQuerier)(nil).CountUsers), ctx, arg)
+}
+
+// CreateAffiliate mocks base method.
+func (m *MockQuerier) CreateAffiliate(ctx context.Context, arg db.CreateAffiliateParams) (db.Affiliate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAffiliate", ctx, arg)
 	ret0, _ := ret[0].(db.Affiliate)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAffiliateByID indicates an expected call of GetAffiliateByID.
-func (mr *MockQuerierMockRecorder) GetAffiliateByID(ctx, id interface{}) *gomock.Call {
+// CreateAffiliate indicates an expected call of CreateAffiliate.
+func (mr *MockQuerierMockRecorder) CreateAffiliate(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAffiliateByID", reflect.TypeOf((*MockQuerier)(nil).GetAffiliateByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAffiliate", reflect.TypeOf((*MockQuerier)(nil).CreateAffiliate), ctx, arg)
 }
 
-// GetAffiliateByUserID mocks base method.
-func (m *MockQuerier) GetAffiliateByUserID(ctx context.Context, id pgtype.UUID) (db.GetAffiliateByUserIDRow, error) {
+// CreateAuthSession mocks base method.
+func (m *MockQuerier) CreateAuthSession(ctx context.Context, userID pgtype.UUID) (db.AuthSession, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAffiliateByUserID", ctx, id)
-	ret0, _ := ret[0].(db.GetAffiliateByUserIDRow)
+	ret := m.ctrl.Call(m, "CreateAuthSession", ctx, userID)
+	ret0, _ := ret[0].(db.AuthSession)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAffiliateByUserID indicates an expected call of GetAffiliateByUserID.
-func (mr *MockQuerierMockRecorder) GetAffiliateByUserID(ctx, id interface{}) *gomock.Call {
+// CreateAuthSession indicates an expected call of CreateAuthSession.
+func (mr *MockQuerierMockRecorder) CreateAuthSession(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAffiliateByUserID", reflect.TypeOf((*MockQuerier)(nil).GetAffiliateByUserID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthSession", reflect.TypeOf((*MockQuerier)(nil).CreateAuthSession), ctx, userID)
 }
 
-// GetCommissionByID mocks base method.
-func (m *MockQuerier) GetCommissionByID(ctx context.Context, id pgtype.UUID) (db.Commission, error) {
+// CreateCart mocks base method.
+func (m *MockQuerier) CreateCart(ctx context.Context, userID pgtype.UUID) (db.Cart, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCommissionByID", ctx, id)
+	ret := m.ctrl.Call(m, "CreateCart", ctx, userID)
+	ret0, _ := ret[0].(db.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCart indicates an expected call of CreateCart.
+func (mr *MockQuerierMockRecorder) CreateCart(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCart", reflect.TypeOf((*MockQuerier)(nil).CreateCart), ctx, userID)
+}
+
+// CreateCommission mocks base method.
+func (m *MockQuerier) CreateCommission(ctx context.Context, arg db.CreateCommissionParams) (db.Commission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommission", ctx, arg)
 	ret0, _ := ret[0].(db.Commission)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCommissionByID indicates an expected call of GetCommissionByID.
-func (mr *MockQuerierMockRecorder) GetCommissionByID(ctx, id interface{}) *gomock.Call {
+// CreateCommission indicates an expected call of CreateCommission.
+func (mr *MockQuerierMockRecorder) CreateCommission(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommissionByID", reflect.TypeOf((*MockQuerier)(nil).GetCommissionByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommission", reflect.TypeOf((*MockQuerier)(nil).CreateCommission), ctx, arg)
 }
 
-// GetCommissionByOrderID mocks base method.
-func (m *MockQuerier) GetCommissionByOrderID(ctx context.Context, orderID pgtype.UUID) ([]db.GetCommissionByOrderIDRow, error) {
+// CreateCommissionPlan mocks base method.
+func (m *MockQuerier) CreateCommissionPlan(ctx context.Context, arg db.CreateCommissionPlanParams) (db.CommissionPlan, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCommissionByOrderID", ctx, orderID)
-	ret0, _ := ret[0].([]db.GetCommissionByOrderIDRow)
+	ret := m.ctrl.Call(m, "CreateCommissionPlan", ctx, arg)
+	ret0, _ := ret[0].(db.CommissionPlan)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCommissionByOrderID indicates an expected call of GetCommissionByOrderID.
-func (mr *MockQuerierMockRecorder) GetCommissionByOrderID(ctx, orderID interface{}) *gomock.Call {
+// CreateCommissionPlan indicates an expected call of CreateCommissionPlan.
+func (mr *MockQuerierMockRecorder) CreateCommissionPlan(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommissionByOrderID", reflect.TypeOf((*MockQuerier)(nil).GetCommissionByOrderID), ctx, orderID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommissionPlan", reflect.TypeOf((*MockQuerier)(nil).CreateCommissionPlan), ctx, arg)
 }
 
-// GetProductByID mocks base method.
-func (m *MockQuerier) GetProductByID(ctx context.Context, id pgtype.UUID) (db.Product, error) {
+// CreateCommissionReversal mocks base method.
+func (m *MockQuerier) CreateCommissionReversal(ctx context.Context, arg db.CreateCommissionReversalParams) (db.Commission, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProductByID", ctx, id)
-	ret0, _ := ret[0].(db.Product)
+	ret := m.ctrl.Call(m, "CreateCommissionReversal", ctx, arg)
+	ret0, _ := ret[0].(db.Commission)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetProductByID indicates an expected call of GetProductByID.
-func (mr *MockQuerierMockRecorder) GetProductByID(ctx, id interface{}) *gomock.Call {
+// CreateCommissionReversal indicates an expected call of CreateCommissionReversal.
+func (mr *MockQuerierMockRecorder) CreateCommissionReversal(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByID", reflect.TypeOf((*MockQuerier)(nil).GetProductByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommissionReversal", reflect.TypeOf((*MockQuerier)(nil).CreateCommissionReversal), ctx, arg)
 }
 
-// GetTotalCommission mocks base method.
-func (m *MockQuerier) GetTotalCommission(ctx context.Context, orderID pgtype.UUID) (float64, error) {
+// CreateIdempotencyKey mocks base method.
+func (m *MockQuerier) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTotalCommission", ctx, orderID)
-	ret0, _ := ret[0].(float64)
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", ctx, arg)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTotalCommission indicates an expected call of GetTotalCommission.
-func (mr *MockQuerierMockRecorder) GetTotalCommission(ctx, orderID interface{}) *gomock.Call {
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockQuerierMockRecorder) CreateIdempotencyKey(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalCommission", reflect.TypeOf((*MockQuerier)(nil).GetTotalCommission), ctx, orderID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockQuerier)(nil).CreateIdempotencyKey), ctx, arg)
 }
 
-// GetUserByUsernameForLogin mocks base method.
-func (m *MockQuerier) GetUserByUsernameForLogin(ctx context.Context, username string) (db.GetUserByUsernameForLoginRow, error) {
+// CreateInventoryMovement mocks base method.
+func (m *MockQuerier) CreateInventoryMovement(ctx context.Context, arg db.CreateInventoryMovementParams) (db.InventoryMovement, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsernameForLogin", ctx, username)
-	ret0, _ := ret[0].(db.GetUserByUsernameForLoginRow)
+	ret := m.ctrl.Call(m, "CreateInventoryMovement", ctx, arg)
+	ret0, _ := ret[0].(db.InventoryMovement)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByUsernameForLogin indicates an expected call of GetUserByUsernameForLogin.
-func (mr *MockQuerierMockRecorder) GetUserByUsernameForLogin(ctx, username interface{}) *gomock.Call {
+// CreateInventoryMovement indicates an expected call of CreateInventoryMovement.
+func (mr *MockQuerierMockRecorder) CreateInventoryMovement(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsernameForLogin", reflect.TypeOf((*MockQuerier)(nil).GetUserByUsernameForLogin), ctx, username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInventoryMovement", reflect.TypeOf((*MockQuerier)(nil).CreateInventoryMovement), ctx, arg)
 }
 
-// GetUserDetailByID mocks base method.
-func (m *MockQuerier) GetUserDetailByID(ctx context.Context, id pgtype.UUID) (db.GetUserDetailByIDRow, error) {
+// CreateLedgerEntry mocks base method.
+func (m *MockQuerier) CreateLedgerEntry(ctx context.Context, arg db.CreateLedgerEntryParams) (db.LedgerEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserDetailByID", ctx, id)
-	ret0, _ := ret[0].(db.GetUserDetailByIDRow)
+	ret := m.ctrl.Call(m, "CreateLedgerEntry", ctx, arg)
+	ret0, _ := ret[0].(db.LedgerEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserDetailByID indicates an expected call of GetUserDetailByID.
-func (mr *MockQuerierMockRecorder) GetUserDetailByID(ctx, id interface{}) *gomock.Call {
+// CreateLedgerEntry indicates an expected call of CreateLedgerEntry.
+func (mr *MockQuerierMockRecorder) CreateLedgerEntry(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDetailByID", reflect.TypeOf((*MockQuerier)(nil).GetUserDetailByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLedgerEntry", reflect.TypeOf((*MockQuerier)(nil).CreateLedgerEntry), ctx, arg)
 }
 
-// ListAffiliates mocks base method.
-func (m *MockQuerier) ListAffiliates(ctx context.Context) ([]db.Affiliate, error) {
+// CreateOrder mocks base method.
+func (m *MockQuerier) CreateOrder(ctx context.Context, arg db.CreateOrderParams) (db.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAffiliates", ctx)
-	ret0, _ := ret[0].([]db.Affiliate)
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, arg)
+	ret0, _ := ret[0].(db.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAffiliates indicates an expected call of ListAffiliates.
-func (mr *MockQuerierMockRecorder) ListAffiliates(ctx interface{}) *gomock.Call {
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockQuerierMockRecorder) CreateOrder(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAffiliates", reflect.TypeOf((*MockQuerier)(nil).ListAffiliates), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockQuerier)(nil).CreateOrder), ctx, arg)
 }
 
-// ListCommissions mocks base method.
-func (m *MockQuerier) ListCommissions(ctx context.Context) ([]db.Commission, error) {
+// CreateOrderItem mocks base method.
+func (m *MockQuerier) CreateOrderItem(ctx context.Context, arg db.CreateOrderItemParams) (db.OrderItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListCommissions", ctx)
-	ret0, _ := ret[0].([]db.Commission)
+	ret := m.ctrl.Call(m, "CreateOrderItem", ctx, arg)
+	ret0, _ := ret[0].(db.OrderItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListCommissions indicates an expected call of ListCommissions.
-func (mr *MockQuerierMockRecorder) ListCommissions(ctx interface{}) *gomock.Call {
+// CreateOrderItem indicates an expected call of CreateOrderItem.
+func (mr *MockQuerierMockRecorder) CreateOrderItem(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommissions", reflect.TypeOf((*MockQuerier)(nil).ListCommissions), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderItem", reflect.TypeOf((*MockQuerier)(nil).CreateOrderItem), ctx, arg)
 }
 
-// ListProducts mocks base method.
-func (m *MockQuerier) ListProducts(ctx context.Context) ([]db.Product, error) {
+// CreateProduct mocks base method.
+func (m *MockQuerier) CreateProduct(ctx context.Context, arg db.CreateProductParams) (db.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListProducts", ctx)
-	ret0, _ := ret[0].([]db.Product)
+	ret := m.ctrl.Call(m, "CreateProduct", ctx, arg)
+	ret0, _ := ret[0].(db.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListProducts indicates an expected call of ListProducts.
-func (mr *MockQuerierMockRecorder) ListProducts(ctx interface{}) *gomock.Call {
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockQuerierMockRecorder) CreateProduct(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockQuerier)(nil).CreateProduct), ctx, arg)
+}
+
+// CreateProductPrice mocks base method.
+func (m *MockQuerier) CreateProductPrice(ctx context.Context, arg db.CreateProductPriceParams) (db.ProductPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductPrice", ctx, arg)
+	ret0, _ := ret[0].(db.ProductPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProductPrice indicates an expected call of CreateProductPrice.
+func (mr *MockQuerierMockRecorder) CreateProductPrice(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockQuerier)(nil).ListProducts), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductPrice", reflect.TypeOf((*MockQuerier)(nil).CreateProductPrice), ctx, arg)
 }
 
-// ListUsers mocks base method.
-func (m *MockQuerier) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.ListUsersRow, error) {
+// CreateRefreshToken mocks base method.
+func (m *MockQuerier) CreateRefreshToken(ctx context.Context, arg db.CreateRefreshTokenParams) (db.RefreshToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUsers", ctx, arg)
-	ret0, _ := ret[0].([]db.ListUsersRow)
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, arg)
+	ret0, _ := ret[0].(db.RefreshToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListUsers indicates an expected call of ListUsers.
-func (mr *MockQuerierMockRecorder) ListUsers(ctx, arg interface{}) *gomock.Call {
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockQuerierMockRecorder) CreateRefreshToken(ctx, arg interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockQuerier)(nil).ListUsers), ctx, arg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockQuerier)(nil).CreateRefreshToken), ctx, arg)
+}
+
+// CreateStockReservation mocks base method.
+func (m *MockQuerier) CreateStockReservation(ctx context.Context, arg db.CreateStockReservationParams) (db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStockReservation", ctx, arg)
+	ret0, _ := ret[0].(db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStockReservation indicates an expected call of CreateStockReservation.
+func (mr *MockQuerierMockRecorder) CreateStockReservation(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStockReservation", reflect.TypeOf((*MockQuerier)(nil).CreateStockReservation), ctx, arg)
+}
+
+// CreateUser mocks base method.
+func (m *MockQuerier) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, arg)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockQuerierMockRecorder) CreateUser(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockQuerier)(nil).CreateUser), ctx, arg)
+}
+
+// DeductAffiliateBalance mocks base method.
+func (m *MockQuerier) DeductAffiliateBalance(ctx context.Context, arg db.DeductAffiliateBalanceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeductAffiliateBalance", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeductAffiliateBalance indicates an expected call of DeductAffiliateBalance.
+func (mr *MockQuerierMockRecorder) DeductAffiliateBalance(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductAffiliateBalance", reflect.TypeOf((*MockQuerier)(nil).DeductAffiliateBalance), ctx, arg)
+}
+
+// DeductProductQuantity mocks base method.
+func (m *MockQuerier) DeductProductQuantity(ctx context.Context, arg db.DeductProductQuantityParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeductProductQuantity", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeductProductQuantity indicates an expected call of DeductProductQuantity.
+func (mr *MockQuerierMockRecorder) DeductProductQuantity(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductProductQuantity", reflect.TypeOf((*MockQuerier)(nil).DeductProductQuantity), ctx, arg)
+}
+
+// DeductUserBalance mocks base method.
+func (m *MockQuerier) DeductUserBalance(ctx context.Context, arg db.DeductUserBalanceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeductUserBalance", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeductUserBalance indicates an expected call of DeductUserBalance.
+func (mr *MockQuerierMockRecorder) DeductUserBalance(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductUserBalance", reflect.TypeOf((*MockQuerier)(nil).DeductUserBalance), ctx, arg)
+}
+
+// DeleteCategoryCommissionOverride mocks base method.
+func (m *MockQuerier) DeleteCategoryCommissionOverride(ctx context.Context, category pgtype.Text) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryCommissionOverride", ctx, category)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategoryCommissionOverride indicates an expected call of DeleteCategoryCommissionOverride.
+func (mr *MockQuerierMockRecorder) DeleteCategoryCommissionOverride(ctx, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryCommissionOverride", reflect.TypeOf((*MockQuerier)(nil).DeleteCategoryCommissionOverride), ctx, category)
+}
+
+// DeleteCommissionPlan mocks base method.
+func (m *MockQuerier) DeleteCommissionPlan(ctx context.Context, id pgtype.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCommissionPlan", ctx, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCommissionPlan indicates an expected call of DeleteCommissionPlan.
+func (mr *MockQuerierMockRecorder) DeleteCommissionPlan(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCommissionPlan", reflect.TypeOf((*MockQuerier)(nil).DeleteCommissionPlan), ctx, id)
+}
+
+// DeleteProductCommissionOverride mocks base method.
+func (m *MockQuerier) DeleteProductCommissionOverride(ctx context.Context, productID pgtype.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductCommissionOverride", ctx, productID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProductCommissionOverride indicates an expected call of DeleteProductCommissionOverride.
+func (mr *MockQuerierMockRecorder) DeleteProductCommissionOverride(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductCommissionOverride", reflect.TypeOf((*MockQuerier)(nil).DeleteProductCommissionOverride), ctx, productID)
+}
+
+// DeleteProductPrice mocks base method.
+func (m *MockQuerier) DeleteProductPrice(ctx context.Context, arg db.DeleteProductPriceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProductPrice", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProductPrice indicates an expected call of DeleteProductPrice.
+func (mr *MockQuerierMockRecorder) DeleteProductPrice(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProductPrice", reflect.TypeOf((*MockQuerier)(nil).DeleteProductPrice), ctx, arg)
+}
+
+// DeleteStaleLoginFailures mocks base method.
+func (m *MockQuerier) DeleteStaleLoginFailures(ctx context.Context, cutoff pgtype.Timestamptz) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleLoginFailures", ctx, cutoff)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStaleLoginFailures indicates an expected call of DeleteStaleLoginFailures.
+func (mr *MockQuerierMockRecorder) DeleteStaleLoginFailures(ctx, cutoff interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleLoginFailures", reflect.TypeOf((*MockQuerier)(nil).DeleteStaleLoginFailures), ctx, cutoff)
+}
+
+// DeleteUsernameLoginFailures mocks base method.
+func (m *MockQuerier) DeleteUsernameLoginFailures(ctx context.Context, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUsernameLoginFailures", ctx, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUsernameLoginFailures indicates an expected call of DeleteUsernameLoginFailures.
+func (mr *MockQuerierMockRecorder) DeleteUsernameLoginFailures(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUsernameLoginFailures", reflect.TypeOf((*MockQuerier)(nil).DeleteUsernameLoginFailures), ctx, key)
+}
+
+// ExpireStockReservations mocks base method.
+func (m *MockQuerier) ExpireStockReservations(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireStockReservations", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireStockReservations indicates an expected call of ExpireStockReservations.
+func (mr *MockQuerierMockRecorder) ExpireStockReservations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireStockReservations", reflect.TypeOf((*MockQuerier)(nil).ExpireStockReservations), ctx)
+}
+
+// GetAffiliateByID mocks base method.
+func (m *MockQuerier) GetAffiliateByID(ctx context.Context, id pgtype.UUID) (db.Affiliate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAffiliateByID", ctx, id)
+	ret0, _ := ret[0].(db.Affiliate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAffiliateByID indicates an expected call of GetAffiliateByID.
+func (mr *MockQuerierMockRecorder) GetAffiliateByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAffiliateByID", reflect.TypeOf((*MockQuerier)(nil).GetAffiliateByID), ctx, id)
+}
+
+// GetAffiliateByUserID mocks base method.
+func (m *MockQuerier) GetAffiliateByUserID(ctx context.Context, id pgtype.UUID) (db.GetAffiliateByUserIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAffiliateByUserID", ctx, id)
+	ret0, _ := ret[0].(db.GetAffiliateByUserIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAffiliateByUserID indicates an expected call of GetAffiliateByUserID.
+func (mr *MockQuerierMockRecorder) GetAffiliateByUserID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAffiliateByUserID", reflect.TypeOf((*MockQuerier)(nil).GetAffiliateByUserID), ctx, id)
+}
+
+// GetAuthSessionByID mocks base method.
+func (m *MockQuerier) GetAuthSessionByID(ctx context.Context, id pgtype.UUID) (db.AuthSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthSessionByID", ctx, id)
+	ret0, _ := ret[0].(db.AuthSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthSessionByID indicates an expected call of GetAuthSessionByID.
+func (mr *MockQuerierMockRecorder) GetAuthSessionByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthSessionByID", reflect.TypeOf((*MockQuerier)(nil).GetAuthSessionByID), ctx, id)
+}
+
+// GetCartByID mocks base method.
+func (m *MockQuerier) GetCartByID(ctx context.Context, id pgtype.UUID) (db.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCartByID", ctx, id)
+	ret0, _ := ret[0].(db.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCartByID indicates an expected call of GetCartByID.
+func (mr *MockQuerierMockRecorder) GetCartByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartByID", reflect.TypeOf((*MockQuerier)(nil).GetCartByID), ctx, id)
+}
+
+// GetCartByIDForUpdate mocks base method.
+func (m *MockQuerier) GetCartByIDForUpdate(ctx context.Context, id pgtype.UUID) (db.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCartByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(db.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCartByIDForUpdate indicates an expected call of GetCartByIDForUpdate.
+func (mr *MockQuerierMockRecorder) GetCartByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartByIDForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetCartByIDForUpdate), ctx, id)
+}
+
+// GetCommissionByID mocks base method.
+func (m *MockQuerier) GetCommissionByID(ctx context.Context, id pgtype.UUID) (db.Commission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommissionByID", ctx, id)
+	ret0, _ := ret[0].(db.Commission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommissionByID indicates an expected call of GetCommissionByID.
+func (mr *MockQuerierMockRecorder) GetCommissionByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommissionByID", reflect.TypeOf((*MockQuerier)(nil).GetCommissionByID), ctx, id)
+}
+
+// GetCommissionByOrderID mocks base method.
+func (m *MockQuerier) GetCommissionByOrderID(ctx context.Context, orderID pgtype.UUID) ([]db.GetCommissionByOrderIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommissionByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]db.GetCommissionByOrderIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommissionByOrderID indicates an expected call of GetCommissionByOrderID.
+func (mr *MockQuerierMockRecorder) GetCommissionByOrderID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommissionByOrderID", reflect.TypeOf((*MockQuerier)(nil).GetCommissionByOrderID), ctx, orderID)
+}
+
+// GetCommissionOverrideByCategory mocks base method.
+func (m *MockQuerier) GetCommissionOverrideByCategory(ctx context.Context, category pgtype.Text) (db.CommissionOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommissionOverrideByCategory", ctx, category)
+	ret0, _ := ret[0].(db.CommissionOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommissionOverrideByCategory indicates an expected call of GetCommissionOverrideByCategory.
+func (mr *MockQuerierMockRecorder) GetCommissionOverrideByCategory(ctx, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommissionOverrideByCategory", reflect.TypeOf((*MockQuerier)(nil).GetCommissionOverrideByCategory), ctx, category)
+}
+
+// GetCommissionOverrideByProductID mocks base method.
+func (m *MockQuerier) GetCommissionOverrideByProductID(ctx context.Context, productID pgtype.UUID) (db.CommissionOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommissionOverrideByProductID", ctx, productID)
+	ret0, _ := ret[0].(db.CommissionOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommissionOverrideByProductID indicates an expected call of GetCommissionOverrideByProductID.
+func (mr *MockQuerierMockRecorder) GetCommissionOverrideByProductID(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommissionOverrideByProductID", reflect.TypeOf((*MockQuerier)(nil).GetCommissionOverrideByProductID), ctx, productID)
+}
+
+// GetCommissionPlanByID mocks base method.
+func (m *MockQuerier) GetCommissionPlanByID(ctx context.Context, id pgtype.UUID) (db.CommissionPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommissionPlanByID", ctx, id)
+	ret0, _ := ret[0].(db.CommissionPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommissionPlanByID indicates an expected call of GetCommissionPlanByID.
+func (mr *MockQuerierMockRecorder) GetCommissionPlanByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommissionPlanByID", reflect.TypeOf((*MockQuerier)(nil).GetCommissionPlanByID), ctx, id)
+}
+
+// GetCommissionPlanInForce mocks base method.
+func (m *MockQuerier) GetCommissionPlanInForce(ctx context.Context, at pgtype.Timestamptz) (db.CommissionPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommissionPlanInForce", ctx, at)
+	ret0, _ := ret[0].(db.CommissionPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommissionPlanInForce indicates an expected call of GetCommissionPlanInForce.
+func (mr *MockQuerierMockRecorder) GetCommissionPlanInForce(ctx, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommissionPlanInForce", reflect.TypeOf((*MockQuerier)(nil).GetCommissionPlanInForce), ctx, at)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockQuerier) GetIdempotencyKey(ctx context.Context, key string) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockQuerierMockRecorder) GetIdempotencyKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockQuerier)(nil).GetIdempotencyKey), ctx, key)
+}
+
+// GetLedgerBalance mocks base method.
+func (m *MockQuerier) GetLedgerBalance(ctx context.Context, arg db.GetLedgerBalanceParams) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLedgerBalance", ctx, arg)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLedgerBalance indicates an expected call of GetLedgerBalance.
+func (mr *MockQuerierMockRecorder) GetLedgerBalance(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLedgerBalance", reflect.TypeOf((*MockQuerier)(nil).GetLedgerBalance), ctx, arg)
+}
+
+// GetOrderByID mocks base method.
+func (m *MockQuerier) GetOrderByID(ctx context.Context, id pgtype.UUID) (db.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByID", ctx, id)
+	ret0, _ := ret[0].(db.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByID indicates an expected call of GetOrderByID.
+func (mr *MockQuerierMockRecorder) GetOrderByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByID", reflect.TypeOf((*MockQuerier)(nil).GetOrderByID), ctx, id)
+}
+
+// GetOrderByIDForUpdate mocks base method.
+func (m *MockQuerier) GetOrderByIDForUpdate(ctx context.Context, id pgtype.UUID) (db.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(db.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderByIDForUpdate indicates an expected call of GetOrderByIDForUpdate.
+func (mr *MockQuerierMockRecorder) GetOrderByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByIDForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetOrderByIDForUpdate), ctx, id)
+}
+
+// GetProductByID mocks base method.
+func (m *MockQuerier) GetProductByID(ctx context.Context, id pgtype.UUID) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductByID", ctx, id)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductByID indicates an expected call of GetProductByID.
+func (mr *MockQuerierMockRecorder) GetProductByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByID", reflect.TypeOf((*MockQuerier)(nil).GetProductByID), ctx, id)
+}
+
+// GetProductByIDForUpdate mocks base method.
+func (m *MockQuerier) GetProductByIDForUpdate(ctx context.Context, id pgtype.UUID) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductByIDForUpdate indicates an expected call of GetProductByIDForUpdate.
+func (mr *MockQuerierMockRecorder) GetProductByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByIDForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetProductByIDForUpdate), ctx, id)
+}
+
+// GetProductPriceByID mocks base method.
+func (m *MockQuerier) GetProductPriceByID(ctx context.Context, id pgtype.UUID) (db.ProductPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductPriceByID", ctx, id)
+	ret0, _ := ret[0].(db.ProductPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductPriceByID indicates an expected call of GetProductPriceByID.
+func (mr *MockQuerierMockRecorder) GetProductPriceByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductPriceByID", reflect.TypeOf((*MockQuerier)(nil).GetProductPriceByID), ctx, id)
+}
+
+// GetProductPriceInForce mocks base method.
+func (m *MockQuerier) GetProductPriceInForce(ctx context.Context, arg db.GetProductPriceInForceParams) (db.ProductPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductPriceInForce", ctx, arg)
+	ret0, _ := ret[0].(db.ProductPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductPriceInForce indicates an expected call of GetProductPriceInForce.
+func (mr *MockQuerierMockRecorder) GetProductPriceInForce(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductPriceInForce", reflect.TypeOf((*MockQuerier)(nil).GetProductPriceInForce), ctx, arg)
+}
+
+// GetRefreshTokenByHashForUpdate mocks base method.
+func (m *MockQuerier) GetRefreshTokenByHashForUpdate(ctx context.Context, tokenHash string) (db.GetRefreshTokenByHashForUpdateRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenByHashForUpdate", ctx, tokenHash)
+	ret0, _ := ret[0].(db.GetRefreshTokenByHashForUpdateRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenByHashForUpdate indicates an expected call of GetRefreshTokenByHashForUpdate.
+func (mr *MockQuerierMockRecorder) GetRefreshTokenByHashForUpdate(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByHashForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetRefreshTokenByHashForUpdate), ctx, tokenHash)
+}
+
+// GetReservedProductQuantity mocks base method.
+func (m *MockQuerier) GetReservedProductQuantity(ctx context.Context, arg db.GetReservedProductQuantityParams) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservedProductQuantity", ctx, arg)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservedProductQuantity indicates an expected call of GetReservedProductQuantity.
+func (mr *MockQuerierMockRecorder) GetReservedProductQuantity(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservedProductQuantity", reflect.TypeOf((*MockQuerier)(nil).GetReservedProductQuantity), ctx, arg)
+}
+
+// GetStockReservationByID mocks base method.
+func (m *MockQuerier) GetStockReservationByID(ctx context.Context, id pgtype.UUID) (db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockReservationByID", ctx, id)
+	ret0, _ := ret[0].(db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockReservationByID indicates an expected call of GetStockReservationByID.
+func (mr *MockQuerierMockRecorder) GetStockReservationByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockReservationByID", reflect.TypeOf((*MockQuerier)(nil).GetStockReservationByID), ctx, id)
+}
+
+// GetStockReservationByIDForUpdate mocks base method.
+func (m *MockQuerier) GetStockReservationByIDForUpdate(ctx context.Context, id pgtype.UUID) (db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockReservationByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStockReservationByIDForUpdate indicates an expected call of GetStockReservationByIDForUpdate.
+func (mr *MockQuerierMockRecorder) GetStockReservationByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockReservationByIDForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetStockReservationByIDForUpdate), ctx, id)
+}
+
+// GetTotalCommission mocks base method.
+func (m *MockQuerier) GetTotalCommission(ctx context.Context, orderID pgtype.UUID) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotalCommission", ctx, orderID)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotalCommission indicates an expected call of GetTotalCommission.
+func (mr *MockQuerierMockRecorder) GetTotalCommission(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalCommission", reflect.TypeOf((*MockQuerier)(nil).GetTotalCommission), ctx, orderID)
+}
+
+// GetUserByUsernameForLogin mocks base method.
+func (m *MockQuerier) GetUserByUsernameForLogin(ctx context.Context, username string) (db.GetUserByUsernameForLoginRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsernameForLogin", ctx, username)
+	ret0, _ := ret[0].(db.GetUserByUsernameForLoginRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsernameForLogin indicates an expected call of GetUserByUsernameForLogin.
+func (mr *MockQuerierMockRecorder) GetUserByUsernameForLogin(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsernameForLogin", reflect.TypeOf((*MockQuerier)(nil).GetUserByUsernameForLogin), ctx, username)
+}
+
+// GetUserDetailByID mocks base method.
+func (m *MockQuerier) GetUserDetailByID(ctx context.Context, id pgtype.UUID) (db.GetUserDetailByIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDetailByID", ctx, id)
+	ret0, _ := ret[0].(db.GetUserDetailByIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDetailByID indicates an expected call of GetUserDetailByID.
+func (mr *MockQuerierMockRecorder) GetUserDetailByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDetailByID", reflect.TypeOf((*MockQuerier)(nil).GetUserDetailByID), ctx, id)
+}
+
+// GetUserDetailByIDForUpdate mocks base method.
+func (m *MockQuerier) GetUserDetailByIDForUpdate(ctx context.Context, id pgtype.UUID) (db.GetUserDetailByIDForUpdateRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDetailByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(db.GetUserDetailByIDForUpdateRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDetailByIDForUpdate indicates an expected call of GetUserDetailByIDForUpdate.
+func (mr *MockQuerierMockRecorder) GetUserDetailByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDetailByIDForUpdate", reflect.TypeOf((*MockQuerier)(nil).GetUserDetailByIDForUpdate), ctx, id)
+}
+
+// ListAccountTransactions mocks base method.
+func (m *MockQuerier) ListAccountTransactions(ctx context.Context, arg db.ListAccountTransactionsParams) ([]db.ListAccountTransactionsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountTransactions", ctx, arg)
+	ret0, _ := ret[0].([]db.ListAccountTransactionsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountTransactions indicates an expected call of ListAccountTransactions.
+func (mr *MockQuerierMockRecorder) ListAccountTransactions(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountTransactions", reflect.TypeOf((*MockQuerier)(nil).ListAccountTransactions), ctx, arg)
+}
+
+// ListAffiliateBalanceMismatches mocks base method.
+func (m *MockQuerier) ListAffiliateBalanceMismatches(ctx context.Context) ([]db.ListAffiliateBalanceMismatchesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAffiliateBalanceMismatches", ctx)
+	ret0, _ := ret[0].([]db.ListAffiliateBalanceMismatchesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAffiliateBalanceMismatches indicates an expected call of ListAffiliateBalanceMismatches.
+func (mr *MockQuerierMockRecorder) ListAffiliateBalanceMismatches(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAffiliateBalanceMismatches", reflect.TypeOf((*MockQuerier)(nil).ListAffiliateBalanceMismatches), ctx)
+}
+
+// ListAffiliates mocks base method.
+func (m *MockQuerier) ListAffiliates(ctx context.Context, arg db.ListAffiliatesParams) ([]db.Affiliate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAffiliates", ctx, arg)
+	ret0, _ := ret[0].([]db.Affiliate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAffiliates indicates an expected call of ListAffiliates.
+func (mr *MockQuerierMockRecorder) ListAffiliates(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAffiliates", reflect.TypeOf((*MockQuerier)(nil).ListAffiliates), ctx, arg)
+}
+
+// ListCartItems mocks base method.
+func (m *MockQuerier) ListCartItems(ctx context.Context, arg db.ListCartItemsParams) ([]db.ListCartItemsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCartItems", ctx, arg)
+	ret0, _ := ret[0].([]db.ListCartItemsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCartItems indicates an expected call of ListCartItems.
+func (mr *MockQuerierMockRecorder) ListCartItems(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCartItems", reflect.TypeOf((*MockQuerier)(nil).ListCartItems), ctx, arg)
+}
+
+// ListCommissionBalancesByOrderID mocks base method.
+func (m *MockQuerier) ListCommissionBalancesByOrderID(ctx context.Context, orderID pgtype.UUID) ([]db.ListCommissionBalancesByOrderIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCommissionBalancesByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]db.ListCommissionBalancesByOrderIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCommissionBalancesByOrderID indicates an expected call of ListCommissionBalancesByOrderID.
+func (mr *MockQuerierMockRecorder) ListCommissionBalancesByOrderID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommissionBalancesByOrderID", reflect.TypeOf((*MockQuerier)(nil).ListCommissionBalancesByOrderID), ctx, orderID)
+}
+
+// ListCommissionPlans mocks base method.
+func (m *MockQuerier) ListCommissionPlans(ctx context.Context) ([]db.CommissionPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCommissionPlans", ctx)
+	ret0, _ := ret[0].([]db.CommissionPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCommissionPlans indicates an expected call of ListCommissionPlans.
+func (mr *MockQuerierMockRecorder) ListCommissionPlans(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommissionPlans", reflect.TypeOf((*MockQuerier)(nil).ListCommissionPlans), ctx)
+}
+
+// ListCommissions mocks base method.
+func (m *MockQuerier) ListCommissions(ctx context.Context, arg db.ListCommissionsParams) ([]db.Commission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCommissions", ctx, arg)
+	ret0, _ := ret[0].([]db.Commission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCommissions indicates an expected call of ListCommissions.
+func (mr *MockQuerierMockRecorder) ListCommissions(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommissions", reflect.TypeOf((*MockQuerier)(nil).ListCommissions), ctx, arg)
+}
+
+// ListInventoryMismatches mocks base method.
+func (m *MockQuerier) ListInventoryMismatches(ctx context.Context) ([]db.ListInventoryMismatchesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInventoryMismatches", ctx)
+	ret0, _ := ret[0].([]db.ListInventoryMismatchesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInventoryMismatches indicates an expected call of ListInventoryMismatches.
+func (mr *MockQuerierMockRecorder) ListInventoryMismatches(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInventoryMismatches", reflect.TypeOf((*MockQuerier)(nil).ListInventoryMismatches), ctx)
+}
+
+// ListInventoryMovements mocks base method.
+func (m *MockQuerier) ListInventoryMovements(ctx context.Context, arg db.ListInventoryMovementsParams) ([]db.ListInventoryMovementsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInventoryMovements", ctx, arg)
+	ret0, _ := ret[0].([]db.ListInventoryMovementsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInventoryMovements indicates an expected call of ListInventoryMovements.
+func (mr *MockQuerierMockRecorder) ListInventoryMovements(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInventoryMovements", reflect.TypeOf((*MockQuerier)(nil).ListInventoryMovements), ctx, arg)
+}
+
+// ListLedgerEntriesByTransactionID mocks base method.
+func (m *MockQuerier) ListLedgerEntriesByTransactionID(ctx context.Context, transactionID pgtype.UUID) ([]db.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLedgerEntriesByTransactionID", ctx, transactionID)
+	ret0, _ := ret[0].([]db.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLedgerEntriesByTransactionID indicates an expected call of ListLedgerEntriesByTransactionID.
+func (mr *MockQuerierMockRecorder) ListLedgerEntriesByTransactionID(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLedgerEntriesByTransactionID", reflect.TypeOf((*MockQuerier)(nil).ListLedgerEntriesByTransactionID), ctx, transactionID)
+}
+
+// ListOrderItemsByOrderID mocks base method.
+func (m *MockQuerier) ListOrderItemsByOrderID(ctx context.Context, orderID pgtype.UUID) ([]db.ListOrderItemsByOrderIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrderItemsByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]db.ListOrderItemsByOrderIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrderItemsByOrderID indicates an expected call of ListOrderItemsByOrderID.
+func (mr *MockQuerierMockRecorder) ListOrderItemsByOrderID(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrderItemsByOrderID", reflect.TypeOf((*MockQuerier)(nil).ListOrderItemsByOrderID), ctx, orderID)
+}
+
+// ListOrdersByUserID mocks base method.
+func (m *MockQuerier) ListOrdersByUserID(ctx context.Context, arg db.ListOrdersByUserIDParams) ([]db.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrdersByUserID", ctx, arg)
+	ret0, _ := ret[0].([]db.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrdersByUserID indicates an expected call of ListOrdersByUserID.
+func (mr *MockQuerierMockRecorder) ListOrdersByUserID(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrdersByUserID", reflect.TypeOf((*MockQuerier)(nil).ListOrdersByUserID), ctx, arg)
+}
+
+// ListProductCategoryFacets mocks base method.
+func (m *MockQuerier) ListProductCategoryFacets(ctx context.Context, arg db.ListProductCategoryFacetsParams) ([]db.ListProductCategoryFacetsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductCategoryFacets", ctx, arg)
+	ret0, _ := ret[0].([]db.ListProductCategoryFacetsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductCategoryFacets indicates an expected call of ListProductCategoryFacets.
+func (mr *MockQuerierMockRecorder) ListProductCategoryFacets(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductCategoryFacets", reflect.TypeOf((*MockQuerier)(nil).ListProductCategoryFacets), ctx, arg)
+}
+
+// ListProductPrices mocks base method.
+func (m *MockQuerier) ListProductPrices(ctx context.Context, productID pgtype.UUID) ([]db.ProductPrice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProductPrices", ctx, productID)
+	ret0, _ := ret[0].([]db.ProductPrice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProductPrices indicates an expected call of ListProductPrices.
+func (mr *MockQuerierMockRecorder) ListProductPrices(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProductPrices", reflect.TypeOf((*MockQuerier)(nil).ListProductPrices), ctx, productID)
+}
+
+// ListProducts mocks base method.
+func (m *MockQuerier) ListProducts(ctx context.Context, arg db.ListProductsParams) ([]db.ListProductsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProducts", ctx, arg)
+	ret0, _ := ret[0].([]db.ListProductsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProducts indicates an expected call of ListProducts.
+func (mr *MockQuerierMockRecorder) ListProducts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockQuerier)(nil).ListProducts), ctx, arg)
+}
+
+// ListUserBalanceMismatches mocks base method.
+func (m *MockQuerier) ListUserBalanceMismatches(ctx context.Context) ([]db.ListUserBalanceMismatchesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserBalanceMismatches", ctx)
+	ret0, _ := ret[0].([]db.ListUserBalanceMismatchesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserBalanceMismatches indicates an expected call of ListUserBalanceMismatches.
+func (mr *MockQuerierMockRecorder) ListUserBalanceMismatches(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserBalanceMismatches", reflect.TypeOf((*MockQuerier)(nil).ListUserBalanceMismatches), ctx)
+}
+
+// ListUsers mocks base method.
+func (m *MockQuerier) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.ListUsersRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, arg)
+	ret0, _ := ret[0].([]db.ListUsersRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockQuerierMockRecorder) ListUsers(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockQuerier)(nil).ListUsers), ctx, arg)
+}
+
+// LockLoginFailures mocks base method.
+func (m *MockQuerier) LockLoginFailures(ctx context.Context, arg db.LockLoginFailuresParams) ([]db.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLoginFailures", ctx, arg)
+	ret0, _ := ret[0].([]db.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockLoginFailures indicates an expected call of LockLoginFailures.
+func (mr *MockQuerierMockRecorder) LockLoginFailures(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLoginFailures", reflect.TypeOf((*MockQuerier)(nil).LockLoginFailures), ctx, arg)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockQuerier) RecordLoginFailure(ctx context.Context, arg db.RecordLoginFailureParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, arg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockQuerierMockRecorder) RecordLoginFailure(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockQuerier)(nil).RecordLoginFailure), ctx, arg)
+}
+
+// ReleaseStockReservation mocks base method.
+func (m *MockQuerier) ReleaseStockReservation(ctx context.Context, id pgtype.UUID) (db.StockReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseStockReservation", ctx, id)
+	ret0, _ := ret[0].(db.StockReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseStockReservation indicates an expected call of ReleaseStockReservation.
+func (mr *MockQuerierMockRecorder) ReleaseStockReservation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseStockReservation", reflect.TypeOf((*MockQuerier)(nil).ReleaseStockReservation), ctx, id)
+}
+
+// RemoveCartItem mocks base method.
+func (m *MockQuerier) RemoveCartItem(ctx context.Context, arg db.RemoveCartItemParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCartItem", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveCartItem indicates an expected call of RemoveCartItem.
+func (mr *MockQuerierMockRecorder) RemoveCartItem(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCartItem", reflect.TypeOf((*MockQuerier)(nil).RemoveCartItem), ctx, arg)
+}
+
+// RevokeAuthSession mocks base method.
+func (m *MockQuerier) RevokeAuthSession(ctx context.Context, arg db.RevokeAuthSessionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAuthSession", ctx, arg)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAuthSession indicates an expected call of RevokeAuthSession.
+func (mr *MockQuerierMockRecorder) RevokeAuthSession(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAuthSession", reflect.TypeOf((*MockQuerier)(nil).RevokeAuthSession), ctx, arg)
+}
+
+// RevokeUserAuthSessions mocks base method.
+func (m *MockQuerier) RevokeUserAuthSessions(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserAuthSessions", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserAuthSessions indicates an expected call of RevokeUserAuthSessions.
+func (mr *MockQuerierMockRecorder) RevokeUserAuthSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserAuthSessions", reflect.TypeOf((*MockQuerier)(nil).RevokeUserAuthSessions), ctx, userID)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockQuerier) RotateRefreshToken(ctx context.Context, id pgtype.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockQuerierMockRecorder) RotateRefreshToken(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockQuerier)(nil).RotateRefreshToken), ctx, id)
+}
+
+// SearchProducts mocks base method.
+func (m *MockQuerier) SearchProducts(ctx context.Context, arg db.SearchProductsParams) ([]db.SearchProductsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", ctx, arg)
+	ret0, _ := ret[0].([]db.SearchProductsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockQuerierMockRecorder) SearchProducts(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockQuerier)(nil).SearchProducts), ctx, arg)
+}
+
+// TouchCart mocks base method.
+func (m *MockQuerier) TouchCart(ctx context.Context, id pgtype.UUID) (db.Cart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchCart", ctx, id)
+	ret0, _ := ret[0].(db.Cart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TouchCart indicates an expected call of TouchCart.
+func (mr *MockQuerierMockRecorder) TouchCart(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchCart", reflect.TypeOf((*MockQuerier)(nil).TouchCart), ctx, id)
+}
+
+// UpdateCommissionPlan mocks base method.
+func (m *MockQuerier) UpdateCommissionPlan(ctx context.Context, arg db.UpdateCommissionPlanParams) (db.CommissionPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCommissionPlan", ctx, arg)
+	ret0, _ := ret[0].(db.CommissionPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCommissionPlan indicates an expected call of UpdateCommissionPlan.
+func (mr *MockQuerierMockRecorder) UpdateCommissionPlan(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCommissionPlan", reflect.TypeOf((*MockQuerier)(nil).UpdateCommissionPlan), ctx, arg)
+}
+
+// UpdateOrderRefund mocks base method.
+func (m *MockQuerier) UpdateOrderRefund(ctx context.Context, arg db.UpdateOrderRefundParams) (db.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderRefund", ctx, arg)
+	ret0, _ := ret[0].(db.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrderRefund indicates an expected call of UpdateOrderRefund.
+func (mr *MockQuerierMockRecorder) UpdateOrderRefund(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderRefund", reflect.TypeOf((*MockQuerier)(nil).UpdateOrderRefund), ctx, arg)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockQuerier) UpdateProduct(ctx context.Context, arg db.UpdateProductParams) (db.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, arg)
+	ret0, _ := ret[0].(db.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockQuerierMockRecorder) UpdateProduct(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockQuerier)(nil).UpdateProduct), ctx, arg)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockQuerier) UpdateUserRole(ctx context.Context, arg db.UpdateUserRoleParams) (db.UpdateUserRoleRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", ctx, arg)
+	ret0, _ := ret[0].(db.UpdateUserRoleRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockQuerierMockRecorder) UpdateUserRole(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockQuerier)(nil).UpdateUserRole), ctx, arg)
+}
+
+// UpsertCategoryCommissionOverride mocks base method.
+func (m *MockQuerier) UpsertCategoryCommissionOverride(ctx context.Context, arg db.UpsertCategoryCommissionOverrideParams) (db.CommissionOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCategoryCommissionOverride", ctx, arg)
+	ret0, _ := ret[0].(db.CommissionOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertCategoryCommissionOverride indicates an expected call of UpsertCategoryCommissionOverride.
+func (mr *MockQuerierMockRecorder) UpsertCategoryCommissionOverride(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCategoryCommissionOverride", reflect.TypeOf((*MockQuerier)(nil).UpsertCategoryCommissionOverride), ctx, arg)
+}
+
+// UpsertProductCommissionOverride mocks base method.
+func (m *MockQuerier) UpsertProductCommissionOverride(ctx context.Context, arg db.UpsertProductCommissionOverrideParams) (db.CommissionOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertProductCommissionOverride", ctx, arg)
+	ret0, _ := ret[0].(db.CommissionOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertProductCommissionOverride indicates an expected call of UpsertProductCommissionOverride.
+func (mr *MockQuerierMockRecorder) UpsertProductCommissionOverride(ctx, arg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertProductCommissionOverride", reflect.TypeOf((*MockQuerier)(nil).UpsertProductCommissionOverride), ctx, arg)
 }
 
 // UserBalance mocks base method.
@@ -4,7 +4,36 @@ VALUES ($1, $2, 0)
 RETURNING *;
 
 -- name: ListAffiliates :many
-SELECT id, name, master_affiliate, balance FROM affiliates;
+-- Every filter is optional. sort is '', 'name' or 'balance', prefixed with
+-- '-' for descending order; ties and the default order go by ID. With
+-- after_id the list continues after that row, after_value being its sort
+-- value. name matches part of the name, with \, % and _ escaped by a
+-- backslash.
+SELECT id, name, master_affiliate, balance
+FROM affiliates
+WHERE (sqlc.narg(name)::text IS NULL OR name ILIKE '%' || sqlc.narg(name) || '%' ESCAPE '\')
+    AND (sqlc.narg(master_affiliate)::uuid IS NULL OR master_affiliate = sqlc.narg(master_affiliate))
+    AND (sqlc.narg(after_id)::uuid IS NULL OR CASE @sort::text
+        WHEN 'name' THEN (name, id) > (sqlc.narg(after_value)::text, sqlc.narg(after_id))
+        WHEN '-name' THEN (name, id) < (sqlc.narg(after_value)::text, sqlc.narg(after_id))
+        WHEN 'balance' THEN (balance, id) > (sqlc.narg(after_value)::text::numeric, sqlc.narg(after_id))
+        WHEN '-balance' THEN (balance, id) < (sqlc.narg(after_value)::text::numeric, sqlc.narg(after_id))
+        ELSE id > sqlc.narg(after_id)
+    END)
+ORDER BY
+    CASE WHEN @sort = 'name' THEN name END,
+    CASE WHEN @sort = '-name' THEN name END DESC,
+    CASE WHEN @sort = 'balance' THEN balance END,
+    CASE WHEN @sort = '-balance' THEN balance END DESC,
+    CASE WHEN @sort LIKE '-%' THEN id END DESC,
+    id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountAffiliates :one
+SELECT COUNT(*)
+FROM affiliates
+WHERE (sqlc.narg(name)::text IS NULL OR name ILIKE '%' || sqlc.narg(name) || '%' ESCAPE '\')
+    AND (sqlc.narg(master_affiliate)::uuid IS NULL OR master_affiliate = sqlc.narg(master_affiliate));
 
 -- name: GetAffiliateByID :one
 SELECT id, name, master_affiliate, balance FROM affiliates WHERE id = $1;
@@ -13,4 +42,7 @@ SELECT id, name, master_affiliate, balance FROM affiliates WHERE id = $1;
 SELECT id, master_affiliate FROM affiliates WHERE id = $1;
 
 -- name: AddAffiliateBalance :exec
-UPDATE affiliates SET balance = balance + $1 WHERE id = $2;
\ No newline at end of file
+UPDATE affiliates SET balance = balance + $1 WHERE id = $2;
+
+-- name: DeductAffiliateBalance :exec
+UPDATE affiliates SET balance = balance - $1 WHERE id = $2;
@@ -0,0 +1,66 @@
+-- name: CreateAuthSession :one
+INSERT INTO auth_sessions (user_id)
+VALUES ($1)
+RETURNING *;
+
+-- name: GetAuthSessionByID :one
+SELECT id, user_id, created_at, revoked_at FROM auth_sessions WHERE id = $1;
+
+-- name: RevokeAuthSession :execrows
+UPDATE auth_sessions SET revoked_at = now()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserAuthSessions :execrows
+UPDATE auth_sessions SET revoked_at = now()
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetRefreshTokenByHashForUpdate :one
+-- Locks the token so two refreshes with the same token cannot both rotate
+-- it. The session and user come along to issue the next access token.
+SELECT rt.id, rt.session_id, rt.expires_at, rt.rotated_at,
+    s.user_id, s.revoked_at AS session_revoked_at, u.username, u.role
+FROM refresh_tokens rt
+JOIN auth_sessions s ON s.id = rt.session_id
+JOIN users u ON u.id = s.user_id
+WHERE rt.token_hash = $1
+FOR UPDATE OF rt;
+
+-- name: RotateRefreshToken :execrows
+UPDATE refresh_tokens SET rotated_at = now()
+WHERE id = $1 AND rotated_at IS NULL;
+
+-- name: LockLoginFailures :many
+-- Creates the counts of the username and the client IP when missing and
+-- locks both until the login's transaction ends.
+INSERT INTO login_failures (scope, key, failures)
+VALUES ('username', sqlc.arg(username), 0),
+    ('ip', sqlc.arg(client_ip), 0)
+ON CONFLICT (scope, key) DO UPDATE SET failures = login_failures.failures
+RETURNING scope, key, failures, last_failure_at;
+
+-- name: RecordLoginFailure :exec
+-- Counts a failure against both the username and the client IP. Counts
+-- whose last failure is before window_start start over.
+UPDATE login_failures SET
+    failures = CASE
+        WHEN last_failure_at < sqlc.arg(window_start) THEN 1
+        ELSE failures + 1
+    END,
+    last_failure_at = sqlc.arg(failed_at)
+WHERE (scope = 'username' AND key = sqlc.arg(username))
+    OR (scope = 'ip' AND key = sqlc.arg(client_ip));
+
+-- name: DeleteUsernameLoginFailures :execrows
+DELETE FROM login_failures WHERE scope = 'username' AND key = $1;
+
+-- name: DeleteStaleLoginFailures :execrows
+-- Run by the sweeper. Forgets the counts whose last failure is before cutoff
+-- and the rows logins created without failing, so the table does not grow
+-- with every username tried.
+DELETE FROM login_failures
+WHERE last_failure_at IS NULL OR last_failure_at < sqlc.arg(cutoff);
@@ -0,0 +1,36 @@
+-- name: CreateCart :one
+INSERT INTO carts (user_id) VALUES ($1) RETURNING *;
+
+-- name: GetCartByID :one
+SELECT id, user_id, status, order_id, created_at, updated_at FROM carts WHERE id = $1;
+
+-- name: GetCartByIDForUpdate :one
+-- Locks the cart so two checkouts of the same cart cannot both go through.
+SELECT id, user_id, status, order_id, created_at, updated_at FROM carts WHERE id = $1 FOR UPDATE;
+
+-- name: AddCartItem :one
+-- Adding a product that is already in the cart increases its quantity.
+INSERT INTO cart_items (cart_id, product_id, quantity) VALUES ($1, $2, $3)
+ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity
+RETURNING *;
+
+-- name: RemoveCartItem :execrows
+DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2;
+
+-- name: ListCartItems :many
+-- Ordered by product ID, the order in which checkout locks the products.
+-- unit_price is the scheduled price in force at the given moment, or the
+-- list price.
+SELECT ci.product_id, p.name AS product_name, ci.quantity,
+    COALESCE((SELECT pp.price FROM product_price_in_force(p.id, sqlc.arg(at)) pp), p.price)::numeric AS unit_price
+FROM cart_items ci
+JOIN products p ON ci.product_id = p.id
+WHERE ci.cart_id = sqlc.arg(cart_id)
+ORDER BY ci.product_id;
+
+-- name: TouchCart :one
+UPDATE carts SET updated_at = now() WHERE id = $1 RETURNING *;
+
+-- name: CheckOutCart :execrows
+UPDATE carts SET status = 'checked_out', order_id = $1, updated_at = now()
+WHERE id = $2 AND status = 'open';
@@ -1,17 +1,58 @@
 -- name: CreateCommission :one
-INSERT INTO commissions (order_id, affiliate_id, amount) VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO commissions (order_id, affiliate_id, amount, plan_id, rule, rates) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: CreateCommissionReversal :one
+INSERT INTO commissions (order_id, affiliate_id, amount, type) VALUES ($1, $2, $3, 'reversal') RETURNING *;
 
 -- name: GetCommissionByID :one
-SELECT id, order_id, affiliate_id, amount FROM commissions WHERE id = $1;
+SELECT id, order_id, affiliate_id, amount, type, created_at, plan_id, rule, rates FROM commissions WHERE id = $1;
   
 -- name: ListCommissions :many
-SELECT id, order_id, affiliate_id, amount FROM commissions;
+-- Every filter is optional. sort is '', 'created_at' or 'amount', prefixed
+-- with '-' for descending order; ties and the default order go by ID. With
+-- after_id the list continues after that row, after_value being its sort
+-- value.
+SELECT id, order_id, affiliate_id, amount, type, created_at, plan_id, rule, rates
+FROM commissions
+WHERE (sqlc.narg(affiliate_id)::uuid IS NULL OR affiliate_id = sqlc.narg(affiliate_id))
+    AND (sqlc.narg(order_id)::uuid IS NULL OR order_id = sqlc.narg(order_id))
+    AND (sqlc.narg(after_id)::uuid IS NULL OR CASE @sort::text
+        WHEN 'created_at' THEN (created_at, id) > (sqlc.narg(after_value)::text::timestamptz, sqlc.narg(after_id))
+        WHEN '-created_at' THEN (created_at, id) < (sqlc.narg(after_value)::text::timestamptz, sqlc.narg(after_id))
+        WHEN 'amount' THEN (amount, id) > (sqlc.narg(after_value)::text::numeric, sqlc.narg(after_id))
+        WHEN '-amount' THEN (amount, id) < (sqlc.narg(after_value)::text::numeric, sqlc.narg(after_id))
+        ELSE id > sqlc.narg(after_id)
+    END)
+ORDER BY
+    CASE WHEN @sort = 'created_at' THEN created_at END,
+    CASE WHEN @sort = '-created_at' THEN created_at END DESC,
+    CASE WHEN @sort = 'amount' THEN amount END,
+    CASE WHEN @sort = '-amount' THEN amount END DESC,
+    CASE WHEN @sort LIKE '-%' THEN id END DESC,
+    id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountCommissions :one
+SELECT COUNT(*)
+FROM commissions
+WHERE (sqlc.narg(affiliate_id)::uuid IS NULL OR affiliate_id = sqlc.narg(affiliate_id))
+    AND (sqlc.narg(order_id)::uuid IS NULL OR order_id = sqlc.narg(order_id));
 
 -- name: GetCommissionByOrderID :many
-SELECT a.id, a.name, c.amount 
+SELECT a.id, a.name, c.amount, c.type, c.created_at, c.plan_id, c.rule, c.rates
 FROM commissions c 
 JOIN affiliates a ON c.affiliate_id = a.id 
-WHERE c.order_id = $1;
+WHERE c.order_id = $1
+ORDER BY c.created_at;
 
 -- name: GetTotalCommission :one
-SELECT SUM(amount)::FLOAT FROM commissions WHERE order_id = $1;
\ No newline at end of file
+SELECT SUM(amount)::NUMERIC FROM commissions WHERE order_id = $1;
+
+-- name: ListCommissionBalancesByOrderID :many
+SELECT affiliate_id,
+       COALESCE(SUM(amount) FILTER (WHERE type = 'commission'), 0)::NUMERIC AS earned,
+       SUM(amount)::NUMERIC AS outstanding
+FROM commissions
+WHERE order_id = $1
+GROUP BY affiliate_id
+ORDER BY affiliate_id;
@@ -0,0 +1,25 @@
+-- name: GetCommissionOverrideByProductID :one
+SELECT id, product_id, category, rates, created_at, updated_at FROM commission_overrides WHERE product_id = $1;
+
+-- name: GetCommissionOverrideByCategory :one
+SELECT id, product_id, category, rates, created_at, updated_at FROM commission_overrides WHERE category = $1;
+
+-- name: UpsertProductCommissionOverride :one
+INSERT INTO commission_overrides (product_id, rates)
+VALUES ($1, $2)
+ON CONFLICT (product_id) WHERE product_id IS NOT NULL
+DO UPDATE SET rates = EXCLUDED.rates, updated_at = now()
+RETURNING *;
+
+-- name: UpsertCategoryCommissionOverride :one
+INSERT INTO commission_overrides (category, rates)
+VALUES ($1, $2)
+ON CONFLICT (category) WHERE category IS NOT NULL
+DO UPDATE SET rates = EXCLUDED.rates, updated_at = now()
+RETURNING *;
+
+-- name: DeleteProductCommissionOverride :execrows
+DELETE FROM commission_overrides WHERE product_id = $1;
+
+-- name: DeleteCategoryCommissionOverride :execrows
+DELETE FROM commission_overrides WHERE category = $1;
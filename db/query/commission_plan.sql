@@ -0,0 +1,33 @@
+-- name: CreateCommissionPlan :one
+INSERT INTO commission_plans (name, rates, effective_from, effective_to)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetCommissionPlanByID :one
+SELECT id, name, rates, effective_from, effective_to, created_at FROM commission_plans WHERE id = $1;
+
+-- name: ListCommissionPlans :many
+SELECT id, name, rates, effective_from, effective_to, created_at
+FROM commission_plans
+ORDER BY effective_from DESC;
+
+-- name: GetCommissionPlanInForce :one
+SELECT id, name, rates, effective_from, effective_to, created_at
+FROM commission_plans
+WHERE effective_from <= sqlc.arg(at)
+  AND (effective_to IS NULL OR effective_to > sqlc.arg(at))
+ORDER BY effective_from DESC
+LIMIT 1;
+
+-- name: UpdateCommissionPlan :one
+UPDATE commission_plans
+SET name = COALESCE(sqlc.narg(name), name),
+    effective_to = COALESCE(sqlc.narg(effective_to), effective_to)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteCommissionPlan :execrows
+DELETE FROM commission_plans WHERE id = $1;
+
+-- name: CountCommissionsByPlanID :one
+SELECT COUNT(*) FROM commissions WHERE plan_id = $1;
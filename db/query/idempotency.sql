@@ -0,0 +1,7 @@
+-- name: GetIdempotencyKey :one
+SELECT key, request_hash, status_code, response_body, created_at FROM idempotency_keys WHERE key = $1;
+
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, request_hash, status_code, response_body)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (key) DO NOTHING;
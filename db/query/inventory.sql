@@ -0,0 +1,31 @@
+-- name: CreateInventoryMovement :one
+INSERT INTO inventory_movements (product_id, kind, quantity, reason, actor, order_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListInventoryMovements :many
+-- Newest first. running_quantity is the stock of the product right after the
+-- movement.
+SELECT id, kind, quantity, running_quantity, reason, actor, order_id, created_at
+FROM (
+    SELECT id, kind, quantity,
+        (SUM(quantity) OVER (ORDER BY created_at, id))::INTEGER AS running_quantity,
+        reason, actor, order_id, created_at
+    FROM inventory_movements
+    WHERE product_id = @product_id
+) m
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountInventoryMovements :one
+SELECT COUNT(*) FROM inventory_movements WHERE product_id = $1;
+
+-- name: ListInventoryMismatches :many
+-- Products whose quantity differs from the sum of their movements.
+SELECT p.id, p.name, p.quantity, COALESCE(m.total, 0)::INTEGER AS movement_quantity
+FROM products p
+LEFT JOIN (
+    SELECT product_id, SUM(quantity) AS total FROM inventory_movements GROUP BY product_id
+) m ON m.product_id = p.id
+WHERE p.quantity <> COALESCE(m.total, 0)
+ORDER BY p.id;
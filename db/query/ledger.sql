@@ -0,0 +1,57 @@
+-- name: CreateLedgerEntry :one
+INSERT INTO ledger_entries (transaction_id, kind, account, account_id, amount, order_id, commission_id, reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListLedgerEntriesByTransactionID :many
+SELECT id, transaction_id, kind, account, account_id, amount, order_id, commission_id, created_at, reason
+FROM ledger_entries
+WHERE transaction_id = $1
+ORDER BY account, account_id;
+
+-- name: GetLedgerBalance :one
+SELECT COALESCE(SUM(amount), 0)::NUMERIC AS balance
+FROM ledger_entries
+WHERE account = $1 AND account_id = $2;
+
+-- name: ListUserBalanceMismatches :many
+SELECT u.id, u.balance, COALESCE(l.total, 0)::NUMERIC AS ledger_balance
+FROM users u
+LEFT JOIN (
+    SELECT account_id, SUM(amount) AS total FROM ledger_entries WHERE account = 'user' GROUP BY account_id
+) l ON l.account_id = u.id
+WHERE u.balance <> COALESCE(l.total, 0)
+ORDER BY u.id;
+
+-- name: ListAffiliateBalanceMismatches :many
+SELECT a.id, a.balance, COALESCE(l.total, 0)::NUMERIC AS ledger_balance
+FROM affiliates a
+LEFT JOIN (
+    SELECT account_id, SUM(amount) AS total FROM ledger_entries WHERE account = 'affiliate' GROUP BY account_id
+) l ON l.account_id = a.id
+WHERE a.balance <> COALESCE(l.total, 0)
+ORDER BY a.id;
+
+-- name: ListAccountTransactions :many
+-- The running balance is taken over the whole history before filtering, so
+-- every row shows the balance right after it was posted.
+SELECT id, transaction_id, kind, amount, reason, order_id, commission_id, created_at, running_balance
+FROM (
+    SELECT id, transaction_id, kind, amount, reason, order_id, commission_id, created_at,
+        SUM(amount) OVER (ORDER BY created_at, id)::NUMERIC AS running_balance
+    FROM ledger_entries
+    WHERE account = @account AND account_id = @account_id
+) t
+WHERE (sqlc.narg(kind)::TEXT IS NULL OR kind = sqlc.narg(kind))
+    AND (sqlc.narg(from_time)::TIMESTAMPTZ IS NULL OR created_at >= sqlc.narg(from_time))
+    AND (sqlc.narg(to_time)::TIMESTAMPTZ IS NULL OR created_at < sqlc.narg(to_time))
+ORDER BY created_at DESC, id DESC
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountAccountTransactions :one
+SELECT COUNT(*)
+FROM ledger_entries
+WHERE account = @account AND account_id = @account_id
+    AND (sqlc.narg(kind)::TEXT IS NULL OR kind = sqlc.narg(kind))
+    AND (sqlc.narg(from_time)::TIMESTAMPTZ IS NULL OR created_at >= sqlc.narg(from_time))
+    AND (sqlc.narg(to_time)::TIMESTAMPTZ IS NULL OR created_at < sqlc.narg(to_time));
@@ -0,0 +1,41 @@
+-- name: CreateOrder :one
+INSERT INTO orders (user_id, total_cost, status) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: CreateOrderItem :one
+INSERT INTO order_items (order_id, product_id, quantity, unit_price, total_price, list_price, price_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetOrderByID :one
+SELECT id, user_id, total_cost, status, created_at, updated_at, refunded_amount FROM orders WHERE id = $1;
+
+-- name: GetOrderByIDForUpdate :one
+-- Refunds and cancellations of an order take its lock, so each decides
+-- what is left to refund from the items as the previous one left them.
+SELECT id, user_id, total_cost, status, created_at, updated_at, refunded_amount FROM orders WHERE id = $1 FOR UPDATE;
+
+-- name: ListOrderItemsByOrderID :many
+SELECT oi.id, oi.product_id, p.name AS product_name, oi.quantity, oi.refunded_quantity, oi.unit_price, oi.total_price, oi.list_price
+FROM order_items oi
+JOIN products p ON oi.product_id = p.id
+WHERE oi.order_id = $1
+ORDER BY p.name;
+
+-- name: ListOrdersByUserID :many
+SELECT id, user_id, total_cost, status, created_at, updated_at, refunded_amount
+FROM orders
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountOrdersByUserID :one
+SELECT COUNT(*) FROM orders WHERE user_id = $1;
+
+-- name: AddOrderItemRefundedQuantity :execrows
+UPDATE order_items SET refunded_quantity = refunded_quantity + $1
+WHERE id = $2 AND quantity - refunded_quantity >= $1;
+
+-- name: UpdateOrderRefund :one
+UPDATE orders SET refunded_amount = refunded_amount + $1, status = $2, updated_at = now()
+WHERE id = $3
+RETURNING *;
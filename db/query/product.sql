@@ -1,13 +1,138 @@
 -- name: CreateProduct :one
-INSERT INTO products (name, quantity, price) VALUES ($1, $2, $3) RETURNING * ;
+INSERT INTO products (name, quantity, price, category, description, tags)
+VALUES ($1, $2, $3, $4, $5, COALESCE(sqlc.narg(tags)::text[], '{}'))
+RETURNING *;
 
 -- name: ListProducts :many
-SELECT id, name, quantity, price FROM products;
+-- Every filter is optional. sort is '', 'name' or 'price', prefixed with '-'
+-- for descending order; ties and the default order go by ID. With after_id
+-- the list continues after that row, after_value being its sort value. The
+-- price filters and the price sort use effective_price, the price in force
+-- at the given moment. name matches part of the name, with \, % and _
+-- escaped by a backslash.
+SELECT id, name, quantity, price, category, archived_at, description, tags, ep.effective_price
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, sqlc.arg(at)) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND (sqlc.narg(name)::text IS NULL OR name ILIKE '%' || sqlc.narg(name) || '%' ESCAPE '\')
+    AND (sqlc.narg(min_price)::numeric IS NULL OR ep.effective_price >= sqlc.narg(min_price))
+    AND (sqlc.narg(max_price)::numeric IS NULL OR ep.effective_price <= sqlc.narg(max_price))
+    AND (sqlc.narg(after_id)::uuid IS NULL OR CASE @sort::text
+        WHEN 'name' THEN (name, id) > (sqlc.narg(after_value)::text, sqlc.narg(after_id))
+        WHEN '-name' THEN (name, id) < (sqlc.narg(after_value)::text, sqlc.narg(after_id))
+        WHEN 'price' THEN (ep.effective_price, id) > (sqlc.narg(after_value)::text::numeric, sqlc.narg(after_id))
+        WHEN '-price' THEN (ep.effective_price, id) < (sqlc.narg(after_value)::text::numeric, sqlc.narg(after_id))
+        ELSE id > sqlc.narg(after_id)
+    END)
+ORDER BY
+    CASE WHEN @sort = 'name' THEN name END,
+    CASE WHEN @sort = '-name' THEN name END DESC,
+    CASE WHEN @sort = 'price' THEN ep.effective_price END,
+    CASE WHEN @sort = '-price' THEN ep.effective_price END DESC,
+    CASE WHEN @sort LIKE '-%' THEN id END DESC,
+    id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountProducts :one
+SELECT COUNT(*)
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, sqlc.arg(at)) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND (sqlc.narg(name)::text IS NULL OR name ILIKE '%' || sqlc.narg(name) || '%' ESCAPE '\')
+    AND (sqlc.narg(min_price)::numeric IS NULL OR ep.effective_price >= sqlc.narg(min_price))
+    AND (sqlc.narg(max_price)::numeric IS NULL OR ep.effective_price <= sqlc.narg(max_price));
+
+-- name: SearchProducts :many
+-- Full-text search over the name and the description, best matches first.
+-- Every filter is optional; without a query every product matches with a
+-- rank of 0 and the products are ordered by name. in_stock keeps the
+-- products with stock left after active reservations. The price filters use
+-- effective_price, the price in force at the given moment.
+SELECT id, name, quantity, price, category, archived_at, description, tags, ep.effective_price,
+    COALESCE(ts_rank(to_tsvector('english', name || ' ' || description), websearch_to_tsquery('english', sqlc.narg(query)::text)), 0)::real AS rank
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, sqlc.arg(at)) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND (sqlc.narg(query)::text IS NULL OR to_tsvector('english', name || ' ' || description) @@ websearch_to_tsquery('english', sqlc.narg(query)))
+    AND (sqlc.narg(category)::text IS NULL OR category = sqlc.narg(category))
+    AND (sqlc.narg(tag)::text IS NULL OR sqlc.narg(tag) = ANY(tags))
+    AND (sqlc.narg(min_price)::numeric IS NULL OR ep.effective_price >= sqlc.narg(min_price))
+    AND (sqlc.narg(max_price)::numeric IS NULL OR ep.effective_price <= sqlc.narg(max_price))
+    AND (NOT @in_stock::bool OR quantity > (
+        SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+        WHERE r.product_id = products.id AND r.status = 'active' AND r.expires_at > now()
+    ))
+ORDER BY rank DESC, name, id
+LIMIT @page_limit OFFSET @page_offset;
+
+-- name: CountSearchProducts :one
+SELECT COUNT(*)
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, sqlc.arg(at)) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND (sqlc.narg(query)::text IS NULL OR to_tsvector('english', name || ' ' || description) @@ websearch_to_tsquery('english', sqlc.narg(query)))
+    AND (sqlc.narg(category)::text IS NULL OR category = sqlc.narg(category))
+    AND (sqlc.narg(tag)::text IS NULL OR sqlc.narg(tag) = ANY(tags))
+    AND (sqlc.narg(min_price)::numeric IS NULL OR ep.effective_price >= sqlc.narg(min_price))
+    AND (sqlc.narg(max_price)::numeric IS NULL OR ep.effective_price <= sqlc.narg(max_price))
+    AND (NOT @in_stock::bool OR quantity > (
+        SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+        WHERE r.product_id = products.id AND r.status = 'active' AND r.expires_at > now()
+    ));
+
+-- name: ListProductCategoryFacets :many
+-- Counts the search matches per category, products without a category
+-- under NULL. The category filter is left out so the counts show what
+-- choosing another category would return.
+SELECT category, COUNT(*) AS count
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, sqlc.arg(at)) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND (sqlc.narg(query)::text IS NULL OR to_tsvector('english', name || ' ' || description) @@ websearch_to_tsquery('english', sqlc.narg(query)))
+    AND (sqlc.narg(tag)::text IS NULL OR sqlc.narg(tag) = ANY(tags))
+    AND (sqlc.narg(min_price)::numeric IS NULL OR ep.effective_price >= sqlc.narg(min_price))
+    AND (sqlc.narg(max_price)::numeric IS NULL OR ep.effective_price <= sqlc.narg(max_price))
+    AND (NOT @in_stock::bool OR quantity > (
+        SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+        WHERE r.product_id = products.id AND r.status = 'active' AND r.expires_at > now()
+    ))
+GROUP BY category
+ORDER BY count DESC, category NULLS LAST;
 
 -- name: GetProductByID :one
-SELECT id, name, quantity, price FROM products WHERE id = $1;
+SELECT id, name, quantity, price, category, archived_at, description, tags FROM products WHERE id = $1;
+
+-- name: GetProductByIDForUpdate :one
+-- Locks the product row until the end of the transaction so concurrent
+-- orders see each other's stock deductions.
+SELECT id, name, quantity, price, category, archived_at, description, tags FROM products WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateProduct :one
+-- An empty category removes the product from its category.
+UPDATE products
+SET name = COALESCE(sqlc.narg(name), name),
+    price = COALESCE(sqlc.narg(price), price),
+    category = CASE WHEN sqlc.narg(category)::text = '' THEN NULL ELSE COALESCE(sqlc.narg(category), category) END,
+    description = COALESCE(sqlc.narg(description), description),
+    tags = COALESCE(sqlc.narg(tags)::text[], tags)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ArchiveProduct :one
+UPDATE products SET archived_at = now() WHERE id = $1 AND archived_at IS NULL RETURNING *;
 
 -- name: DeductProductQuantity :execrows
 UPDATE products SET quantity = quantity - $1 WHERE id = $2 AND quantity >= $1;
 
-
+-- name: AddProductQuantity :execrows
+UPDATE products SET quantity = quantity + $1 WHERE id = $2;
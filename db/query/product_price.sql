@@ -0,0 +1,23 @@
+-- name: CreateProductPrice :one
+INSERT INTO product_prices (product_id, price, effective_from, effective_to)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetProductPriceByID :one
+SELECT id, product_id, price, effective_from, effective_to, created_at FROM product_prices WHERE id = $1;
+
+-- name: ListProductPrices :many
+SELECT id, product_id, price, effective_from, effective_to, created_at
+FROM product_prices
+WHERE product_id = $1
+ORDER BY effective_from DESC, created_at DESC;
+
+-- name: GetProductPriceInForce :one
+-- The scheduled price of the product at the given moment.
+SELECT id, product_id, price, effective_from, effective_to, created_at
+FROM product_price_in_force(sqlc.arg(product_id), sqlc.arg(at));
+
+-- name: DeleteProductPrice :execrows
+-- Only prices that have not come into force yet can be cancelled; the
+-- others are history that orders point to.
+DELETE FROM product_prices WHERE id = $1 AND product_id = $2 AND effective_from > now();
@@ -0,0 +1,38 @@
+-- name: CreateStockReservation :one
+INSERT INTO stock_reservations (product_id, user_id, quantity, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetStockReservationByID :one
+SELECT id, product_id, user_id, quantity, status, expires_at, order_id, created_at, updated_at
+FROM stock_reservations WHERE id = $1;
+
+-- name: GetStockReservationByIDForUpdate :one
+-- Locks the reservation so confirming, releasing and expiring it cannot
+-- overlap.
+SELECT id, product_id, user_id, quantity, status, expires_at, order_id, created_at, updated_at
+FROM stock_reservations WHERE id = $1 FOR UPDATE;
+
+-- name: GetReservedProductQuantity :one
+-- Stock held by active, unexpired reservations of the product. exclude_id
+-- leaves out the reservation that is being consumed.
+SELECT COALESCE(SUM(quantity), 0)::INTEGER AS reserved
+FROM stock_reservations
+WHERE product_id = sqlc.arg(product_id)
+    AND status = 'active'
+    AND expires_at > now()
+    AND (sqlc.narg(exclude_id)::uuid IS NULL OR id <> sqlc.narg(exclude_id));
+
+-- name: ConfirmStockReservation :execrows
+UPDATE stock_reservations SET status = 'confirmed', order_id = $1, updated_at = now()
+WHERE id = $2 AND status = 'active';
+
+-- name: ReleaseStockReservation :one
+UPDATE stock_reservations SET status = 'released', updated_at = now()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: ExpireStockReservations :execrows
+-- Run by the sweeper. Marks every active reservation past its TTL as expired.
+UPDATE stock_reservations SET status = 'expired', updated_at = now()
+WHERE status = 'active' AND expires_at <= now();
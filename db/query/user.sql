@@ -2,17 +2,45 @@
 INSERT INTO users (username, password, affiliate_id) VALUES ($1, $2, $3) RETURNING *;
 
 -- name: ListUsers :many
+-- Every filter is optional. sort is '', 'username' or 'balance', prefixed
+-- with '-' for descending order; ties and the default order go by ID. With
+-- after_id the list continues after that row, after_value being its sort
+-- value. username matches part of the username, with \, % and _ escaped
+-- by a backslash.
 SELECT id, username, balance, affiliate_id
 FROM users
-ORDER BY id
-LIMIT $1 OFFSET $2;
+WHERE (sqlc.narg(username)::text IS NULL OR username ILIKE '%' || sqlc.narg(username) || '%' ESCAPE '\')
+    AND (sqlc.narg(affiliate_id)::uuid IS NULL OR affiliate_id = sqlc.narg(affiliate_id))
+    AND (sqlc.narg(after_id)::uuid IS NULL OR CASE @sort::text
+        WHEN 'username' THEN (username, id) > (sqlc.narg(after_value)::text, sqlc.narg(after_id))
+        WHEN '-username' THEN (username, id) < (sqlc.narg(after_value)::text, sqlc.narg(after_id))
+        WHEN 'balance' THEN (balance, id) > (sqlc.narg(after_value)::text::numeric, sqlc.narg(after_id))
+        WHEN '-balance' THEN (balance, id) < (sqlc.narg(after_value)::text::numeric, sqlc.narg(after_id))
+        ELSE id > sqlc.narg(after_id)
+    END)
+ORDER BY
+    CASE WHEN @sort = 'username' THEN username END,
+    CASE WHEN @sort = '-username' THEN username END DESC,
+    CASE WHEN @sort = 'balance' THEN balance END,
+    CASE WHEN @sort = '-balance' THEN balance END DESC,
+    CASE WHEN @sort LIKE '-%' THEN id END DESC,
+    id
+LIMIT @page_limit OFFSET @page_offset;
 
 -- name: CountUsers :one
-SELECT COUNT(*) FROM users;
+SELECT COUNT(*)
+FROM users
+WHERE (sqlc.narg(username)::text IS NULL OR username ILIKE '%' || sqlc.narg(username) || '%' ESCAPE '\')
+    AND (sqlc.narg(affiliate_id)::uuid IS NULL OR affiliate_id = sqlc.narg(affiliate_id));
 
 -- name: GetUserDetailByID :one
 SELECT id, username, balance, affiliate_id FROM users WHERE id = $1;
 
+-- name: GetUserDetailByIDForUpdate :one
+-- Locks the user row until the end of the transaction so concurrent orders
+-- see each other's balance deductions.
+SELECT id, username, balance, affiliate_id FROM users WHERE id = $1 FOR UPDATE;
+
 -- name: DeductUserBalance :execrows
 UPDATE users SET balance = balance - $1 WHERE id = $2 AND balance >= $1;
 
@@ -26,7 +54,11 @@ SELECT EXISTS(SELECT 1 FROM users WHERE id = $1);
 SELECT id, balance FROM users WHERE id = $1;
 
 -- name: GetUserByUsernameForLogin :one
-SELECT id, username, password, affiliate_id, balance
+SELECT id, username, password, affiliate_id, balance, role
 FROM users
 WHERE username = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = $1 WHERE id = $2
+RETURNING id, username, role;
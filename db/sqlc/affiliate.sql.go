@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
 const addAffiliateBalance = `-- name: AddAffiliateBalance :exec
@@ -16,8 +17,8 @@ UPDATE affiliates SET balance = balance + $1 WHERE id = $2
 `
 
 type AddAffiliateBalanceParams struct {
-	Balance float64     `json:"balance"`
-	ID      pgtype.UUID `json:"id"`
+	Balance decimal.Decimal `json:"balance"`
+	ID      pgtype.UUID     `json:"id"`
 }
 
 func (q *Queries) AddAffiliateBalance(ctx context.Context, arg AddAffiliateBalanceParams) error {
@@ -25,6 +26,25 @@ func (q *Queries) AddAffiliateBalance(ctx context.Context, arg AddAffiliateBalan
 	return err
 }
 
+const countAffiliates = `-- name: CountAffiliates :one
+SELECT COUNT(*)
+FROM affiliates
+WHERE ($1::text IS NULL OR name ILIKE '%' || $1 || '%' ESCAPE '\')
+    AND ($2::uuid IS NULL OR master_affiliate = $2)
+`
+
+type CountAffiliatesParams struct {
+	Name            pgtype.Text `json:"name"`
+	MasterAffiliate pgtype.UUID `json:"master_affiliate"`
+}
+
+func (q *Queries) CountAffiliates(ctx context.Context, arg CountAffiliatesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAffiliates, arg.Name, arg.MasterAffiliate)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAffiliate = `-- name: CreateAffiliate :one
 INSERT INTO affiliates (name, master_affiliate, balance)
 VALUES ($1, $2, 0)
@@ -48,6 +68,20 @@ func (q *Queries) CreateAffiliate(ctx context.Context, arg CreateAffiliateParams
 	return i, err
 }
 
+const deductAffiliateBalance = `-- name: DeductAffiliateBalance :exec
+UPDATE affiliates SET balance = balance - $1 WHERE id = $2
+`
+
+type DeductAffiliateBalanceParams struct {
+	Balance decimal.Decimal `json:"balance"`
+	ID      pgtype.UUID     `json:"id"`
+}
+
+func (q *Queries) DeductAffiliateBalance(ctx context.Context, arg DeductAffiliateBalanceParams) error {
+	_, err := q.db.Exec(ctx, deductAffiliateBalance, arg.Balance, arg.ID)
+	return err
+}
+
 const getAffiliateByID = `-- name: GetAffiliateByID :one
 SELECT id, name, master_affiliate, balance FROM affiliates WHERE id = $1
 `
@@ -81,11 +115,52 @@ func (q *Queries) GetAffiliateByUserID(ctx context.Context, id pgtype.UUID) (Get
 }
 
 const listAffiliates = `-- name: ListAffiliates :many
-SELECT id, name, master_affiliate, balance FROM affiliates
+SELECT id, name, master_affiliate, balance
+FROM affiliates
+WHERE ($1::text IS NULL OR name ILIKE '%' || $1 || '%' ESCAPE '\')
+    AND ($2::uuid IS NULL OR master_affiliate = $2)
+    AND ($3::uuid IS NULL OR CASE $4::text
+        WHEN 'name' THEN (name, id) > ($5::text, $3)
+        WHEN '-name' THEN (name, id) < ($5::text, $3)
+        WHEN 'balance' THEN (balance, id) > ($5::text::numeric, $3)
+        WHEN '-balance' THEN (balance, id) < ($5::text::numeric, $3)
+        ELSE id > $3
+    END)
+ORDER BY
+    CASE WHEN $4 = 'name' THEN name END,
+    CASE WHEN $4 = '-name' THEN name END DESC,
+    CASE WHEN $4 = 'balance' THEN balance END,
+    CASE WHEN $4 = '-balance' THEN balance END DESC,
+    CASE WHEN $4 LIKE '-%' THEN id END DESC,
+    id
+LIMIT $6 OFFSET $7
 `
 
-func (q *Queries) ListAffiliates(ctx context.Context) ([]Affiliate, error) {
-	rows, err := q.db.Query(ctx, listAffiliates)
+type ListAffiliatesParams struct {
+	Name            pgtype.Text `json:"name"`
+	MasterAffiliate pgtype.UUID `json:"master_affiliate"`
+	AfterID         pgtype.UUID `json:"after_id"`
+	Sort            string      `json:"sort"`
+	AfterValue      pgtype.Text `json:"after_value"`
+	PageLimit       int32       `json:"page_limit"`
+	PageOffset      int32       `json:"page_offset"`
+}
+
+// Every filter is optional. sort is ”, 'name' or 'balance', prefixed with
+// '-' for descending order; ties and the default order go by ID. With
+// after_id the list continues after that row, after_value being its sort
+// value. name matches part of the name, with \, % and _ escaped by a
+// backslash.
+func (q *Queries) ListAffiliates(ctx context.Context, arg ListAffiliatesParams) ([]Affiliate, error) {
+	rows, err := q.db.Query(ctx, listAffiliates,
+		arg.Name,
+		arg.MasterAffiliate,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterValue,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,7 +47,7 @@ func TestAddAffiliateBalance(t *testing.T) {
 
 	arg := AddAffiliateBalanceParams{
 		ID:      affiliate.ID,
-		Balance: 100,
+		Balance: decimal.NewFromInt(100),
 	}
 
 	err := testQueries.AddAffiliateBalance(context.Background(), arg)
@@ -54,7 +55,21 @@ func TestAddAffiliateBalance(t *testing.T) {
 
 	affiliateResult, err := testQueries.GetAffiliateByID(context.Background(), affiliate.ID)
 	require.NoError(t, err)
-	require.Equal(t, arg.Balance, affiliateResult.Balance)
+	require.True(t, arg.Balance.Equal(affiliateResult.Balance))
+}
+
+func TestDeductAffiliateBalance(t *testing.T) {
+	affiliate := createRandomAffiliate(t)
+
+	err := testQueries.DeductAffiliateBalance(context.Background(), DeductAffiliateBalanceParams{
+		ID:      affiliate.ID,
+		Balance: decimal.NewFromInt(25),
+	})
+	require.NoError(t, err)
+
+	affiliateResult, err := testQueries.GetAffiliateByID(context.Background(), affiliate.ID)
+	require.NoError(t, err)
+	require.True(t, affiliate.Balance.Sub(decimal.NewFromInt(25)).Equal(affiliateResult.Balance))
 }
 
 func TestGetAffiliateByID(t *testing.T) {
@@ -84,9 +99,9 @@ func TestListAffiliates(t *testing.T) {
 		createRandomAffiliate(t)
 	}
 
-	affiliates, err := testQueries.ListAffiliates(context.Background())
+	affiliates, err := testQueries.ListAffiliates(context.Background(), ListAffiliatesParams{PageLimit: 5})
 	require.NoError(t, err)
-	require.NotEmpty(t, affiliates)
+	require.Len(t, affiliates, 5)
 
 	for _, affiliate := range affiliates {
 		require.NotEmpty(t, affiliate)
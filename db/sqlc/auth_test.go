@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAuthSession(t *testing.T, user User) AuthSession {
+	session, err := testQueries.CreateAuthSession(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.NotZero(t, session.ID)
+	require.Equal(t, user.ID, session.UserID)
+	require.False(t, session.RevokedAt.Valid)
+
+	return session
+}
+
+func createRandomRefreshToken(t *testing.T, session AuthSession, hash string) RefreshToken {
+	token, err := testQueries.CreateRefreshToken(context.Background(), CreateRefreshTokenParams{
+		SessionID: session.ID,
+		TokenHash: hash,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, session.ID, token.SessionID)
+	require.Equal(t, hash, token.TokenHash)
+	require.False(t, token.RotatedAt.Valid)
+
+	return token
+}
+
+func TestGetRefreshTokenByHashForUpdate(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomAuthSession(t, user)
+	token := createRandomRefreshToken(t, session, "hash-"+session.ID.String())
+
+	row, err := testQueries.GetRefreshTokenByHashForUpdate(context.Background(), token.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, token.ID, row.ID)
+	require.Equal(t, session.ID, row.SessionID)
+	require.Equal(t, user.ID, row.UserID)
+	require.Equal(t, user.Username, row.Username)
+	require.Equal(t, user.Role, row.Role)
+	require.False(t, row.SessionRevokedAt.Valid)
+
+	rotated, err := testQueries.RotateRefreshToken(context.Background(), token.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rotated)
+
+	// A token is rotated once.
+	rotated, err = testQueries.RotateRefreshToken(context.Background(), token.ID)
+	require.NoError(t, err)
+	require.Zero(t, rotated)
+
+	row, err = testQueries.GetRefreshTokenByHashForUpdate(context.Background(), token.TokenHash)
+	require.NoError(t, err)
+	require.True(t, row.RotatedAt.Valid)
+
+	_, err = testQueries.GetRefreshTokenByHashForUpdate(context.Background(), "unknown")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestRevokeAuthSessions(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	current := createRandomAuthSession(t, user)
+	second := createRandomAuthSession(t, user)
+	otherSession := createRandomAuthSession(t, other)
+
+	// Sessions of another user are left alone.
+	revoked, err := testQueries.RevokeAuthSession(context.Background(), RevokeAuthSessionParams{ID: otherSession.ID, UserID: user.ID})
+	require.NoError(t, err)
+	require.Zero(t, revoked)
+
+	revoked, err = testQueries.RevokeAuthSession(context.Background(), RevokeAuthSessionParams{ID: current.ID, UserID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	session, err := testQueries.GetAuthSessionByID(context.Background(), current.ID)
+	require.NoError(t, err)
+	require.True(t, session.RevokedAt.Valid)
+
+	// Only the sessions still active are counted.
+	revoked, err = testQueries.RevokeUserAuthSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	session, err = testQueries.GetAuthSessionByID(context.Background(), second.ID)
+	require.NoError(t, err)
+	require.True(t, session.RevokedAt.Valid)
+
+	session, err = testQueries.GetAuthSessionByID(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.False(t, session.RevokedAt.Valid)
+}
+
+func TestLoginFailures(t *testing.T) {
+	username := "user-" + time.Now().Format(time.RFC3339Nano)
+	clientIP := "192.0.2.1"
+	now := time.Now().Truncate(time.Microsecond)
+
+	lock := func() map[string]int32 {
+		rows, err := testQueries.LockLoginFailures(context.Background(), LockLoginFailuresParams{Username: username, ClientIP: clientIP})
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		counts := map[string]int32{}
+		for _, row := range rows {
+			counts[row.Scope] = row.Failures
+		}
+		return counts
+	}
+	record := func(at time.Time) {
+		err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+			WindowStart: pgtype.Timestamptz{Time: at.Add(-15 * time.Minute), Valid: true},
+			FailedAt:    pgtype.Timestamptz{Time: at, Valid: true},
+			Username:    username,
+			ClientIP:    clientIP,
+		})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, map[string]int32{"username": 0, "ip": 0}, lock())
+
+	record(now.Add(-time.Hour))
+	record(now.Add(-time.Minute))
+	require.Equal(t, map[string]int32{"username": 1, "ip": 1}, lock(), "failures before the window are forgotten")
+
+	record(now)
+	require.Equal(t, map[string]int32{"username": 2, "ip": 2}, lock())
+
+	deleted, err := testQueries.DeleteUsernameLoginFailures(context.Background(), username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+	require.Equal(t, map[string]int32{"username": 0, "ip": 2}, lock())
+}
+
+func TestDeleteStaleLoginFailures(t *testing.T) {
+	suffix := time.Now().Format(time.RFC3339Nano)
+	now := time.Now().Truncate(time.Microsecond)
+
+	lock := func(key string) map[string]int32 {
+		rows, err := testQueries.LockLoginFailures(context.Background(), LockLoginFailuresParams{Username: key + suffix, ClientIP: key + suffix})
+		require.NoError(t, err)
+		counts := map[string]int32{}
+		for _, row := range rows {
+			counts[row.Scope] = row.Failures
+		}
+		return counts
+	}
+	record := func(key string, at time.Time) {
+		err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+			WindowStart: pgtype.Timestamptz{Time: at.Add(-15 * time.Minute), Valid: true},
+			FailedAt:    pgtype.Timestamptz{Time: at, Valid: true},
+			Username:    key + suffix,
+			ClientIP:    key + suffix,
+		})
+		require.NoError(t, err)
+	}
+
+	lock("stale-")
+	record("stale-", now.Add(-time.Hour))
+	lock("unfailed-")
+	lock("recent-")
+	record("recent-", now)
+
+	deleted, err := testQueries.DeleteStaleLoginFailures(context.Background(), pgtype.Timestamptz{Time: now.Add(-15 * time.Minute), Valid: true})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(4))
+
+	require.Equal(t, map[string]int32{"username": 0, "ip": 0}, lock("stale-"))
+	require.Equal(t, map[string]int32{"username": 1, "ip": 1}, lock("recent-"))
+}
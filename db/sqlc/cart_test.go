@@ -0,0 +1,146 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCart(t *testing.T, user User) Cart {
+	cart, err := testQueries.CreateCart(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	require.NotZero(t, cart.ID)
+	require.Equal(t, user.ID, cart.UserID)
+	require.Equal(t, "open", cart.Status)
+	require.False(t, cart.OrderID.Valid)
+
+	return cart
+}
+
+func TestCreateCart(t *testing.T) {
+	createRandomCart(t, createRandomUser(t))
+}
+
+func TestAddCartItem(t *testing.T) {
+	cart := createRandomCart(t, createRandomUser(t))
+	product := createRandomProduct(t)
+
+	item, err := testQueries.AddCartItem(context.Background(), AddCartItemParams{
+		CartID:    cart.ID,
+		ProductID: product.ID,
+		Quantity:  2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), item.Quantity)
+
+	// Adding the same product again increases the quantity of its line.
+	item, err = testQueries.AddCartItem(context.Background(), AddCartItemParams{
+		CartID:    cart.ID,
+		ProductID: product.ID,
+		Quantity:  3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(5), item.Quantity)
+
+	items, err := testQueries.ListCartItems(context.Background(), ListCartItemsParams{
+		At:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		CartID: cart.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, product.Name, items[0].ProductName)
+	require.True(t, product.Price.Equal(items[0].UnitPrice))
+}
+
+func TestListCartItemsOrderedByProductID(t *testing.T) {
+	cart := createRandomCart(t, createRandomUser(t))
+
+	for i := 0; i < 3; i++ {
+		_, err := testQueries.AddCartItem(context.Background(), AddCartItemParams{
+			CartID:    cart.ID,
+			ProductID: createRandomProduct(t).ID,
+			Quantity:  1,
+		})
+		require.NoError(t, err)
+	}
+
+	items, err := testQueries.ListCartItems(context.Background(), ListCartItemsParams{
+		At:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		CartID: cart.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	for i := 1; i < len(items); i++ {
+		require.Negative(t, bytes.Compare(items[i-1].ProductID.Bytes[:], items[i].ProductID.Bytes[:]))
+	}
+}
+
+func TestListCartItemsAtScheduledPrice(t *testing.T) {
+	cart := createRandomCart(t, createRandomUser(t))
+	product := createRandomProduct(t)
+	sale := createRandomProductPrice(t, product.ID, 35, time.Now().Add(-time.Hour), pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true})
+
+	_, err := testQueries.AddCartItem(context.Background(), AddCartItemParams{
+		CartID:    cart.ID,
+		ProductID: product.ID,
+		Quantity:  1,
+	})
+	require.NoError(t, err)
+
+	items, err := testQueries.ListCartItems(context.Background(), ListCartItemsParams{
+		At:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		CartID: cart.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.True(t, sale.Price.Equal(items[0].UnitPrice))
+}
+
+func TestRemoveCartItem(t *testing.T) {
+	cart := createRandomCart(t, createRandomUser(t))
+	product := createRandomProduct(t)
+
+	_, err := testQueries.AddCartItem(context.Background(), AddCartItemParams{
+		CartID:    cart.ID,
+		ProductID: product.ID,
+		Quantity:  1,
+	})
+	require.NoError(t, err)
+
+	arg := RemoveCartItemParams{CartID: cart.ID, ProductID: product.ID}
+
+	rows, err := testQueries.RemoveCartItem(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testQueries.RemoveCartItem(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestCheckOutCart(t *testing.T) {
+	user := createRandomUser(t)
+	cart := createRandomCart(t, user)
+	order := createRandomOrder(t, user, createRandomProduct(t))
+
+	arg := CheckOutCartParams{OrderID: order.ID, ID: cart.ID}
+
+	rows, err := testQueries.CheckOutCart(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// A cart can only be checked out once.
+	rows, err = testQueries.CheckOutCart(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	cart, err = testQueries.GetCartByID(context.Background(), cart.ID)
+	require.NoError(t, err)
+	require.Equal(t, "checked_out", cart.Status)
+	require.Equal(t, order.ID, cart.OrderID)
+}
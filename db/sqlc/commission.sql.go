@@ -9,32 +9,94 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
+const countCommissions = `-- name: CountCommissions :one
+SELECT COUNT(*)
+FROM commissions
+WHERE ($1::uuid IS NULL OR affiliate_id = $1)
+    AND ($2::uuid IS NULL OR order_id = $2)
+`
+
+type CountCommissionsParams struct {
+	AffiliateID pgtype.UUID `json:"affiliate_id"`
+	OrderID     pgtype.UUID `json:"order_id"`
+}
+
+func (q *Queries) CountCommissions(ctx context.Context, arg CountCommissionsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countCommissions, arg.AffiliateID, arg.OrderID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createCommission = `-- name: CreateCommission :one
-INSERT INTO commissions (order_id, affiliate_id, amount) VALUES ($1, $2, $3) RETURNING id, order_id, affiliate_id, amount
+INSERT INTO commissions (order_id, affiliate_id, amount, plan_id, rule, rates) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, order_id, affiliate_id, amount, type, created_at, plan_id, rule, rates
 `
 
 type CreateCommissionParams struct {
-	OrderID     pgtype.UUID `json:"order_id"`
-	AffiliateID pgtype.UUID `json:"affiliate_id"`
-	Amount      float64     `json:"amount"`
+	OrderID     pgtype.UUID     `json:"order_id"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
+	Amount      decimal.Decimal `json:"amount"`
+	PlanID      pgtype.UUID     `json:"plan_id"`
+	Rule        pgtype.Text     `json:"rule"`
+	Rates       []float64       `json:"rates"`
 }
 
 func (q *Queries) CreateCommission(ctx context.Context, arg CreateCommissionParams) (Commission, error) {
-	row := q.db.QueryRow(ctx, createCommission, arg.OrderID, arg.AffiliateID, arg.Amount)
+	row := q.db.QueryRow(ctx, createCommission,
+		arg.OrderID,
+		arg.AffiliateID,
+		arg.Amount,
+		arg.PlanID,
+		arg.Rule,
+		arg.Rates,
+	)
 	var i Commission
 	err := row.Scan(
 		&i.ID,
 		&i.OrderID,
 		&i.AffiliateID,
 		&i.Amount,
+		&i.Type,
+		&i.CreatedAt,
+		&i.PlanID,
+		&i.Rule,
+		&i.Rates,
+	)
+	return i, err
+}
+
+const createCommissionReversal = `-- name: CreateCommissionReversal :one
+INSERT INTO commissions (order_id, affiliate_id, amount, type) VALUES ($1, $2, $3, 'reversal') RETURNING id, order_id, affiliate_id, amount, type, created_at, plan_id, rule, rates
+`
+
+type CreateCommissionReversalParams struct {
+	OrderID     pgtype.UUID     `json:"order_id"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+func (q *Queries) CreateCommissionReversal(ctx context.Context, arg CreateCommissionReversalParams) (Commission, error) {
+	row := q.db.QueryRow(ctx, createCommissionReversal, arg.OrderID, arg.AffiliateID, arg.Amount)
+	var i Commission
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.AffiliateID,
+		&i.Amount,
+		&i.Type,
+		&i.CreatedAt,
+		&i.PlanID,
+		&i.Rule,
+		&i.Rates,
 	)
 	return i, err
 }
 
 const getCommissionByID = `-- name: GetCommissionByID :one
-SELECT id, order_id, affiliate_id, amount FROM commissions WHERE id = $1
+SELECT id, order_id, affiliate_id, amount, type, created_at, plan_id, rule, rates FROM commissions WHERE id = $1
 `
 
 func (q *Queries) GetCommissionByID(ctx context.Context, id pgtype.UUID) (Commission, error) {
@@ -45,21 +107,32 @@ func (q *Queries) GetCommissionByID(ctx context.Context, id pgtype.UUID) (Commis
 		&i.OrderID,
 		&i.AffiliateID,
 		&i.Amount,
+		&i.Type,
+		&i.CreatedAt,
+		&i.PlanID,
+		&i.Rule,
+		&i.Rates,
 	)
 	return i, err
 }
 
 const getCommissionByOrderID = `-- name: GetCommissionByOrderID :many
-SELECT a.id, a.name, c.amount 
+SELECT a.id, a.name, c.amount, c.type, c.created_at, c.plan_id, c.rule, c.rates
 FROM commissions c 
 JOIN affiliates a ON c.affiliate_id = a.id 
 WHERE c.order_id = $1
+ORDER BY c.created_at
 `
 
 type GetCommissionByOrderIDRow struct {
-	ID     pgtype.UUID `json:"id"`
-	Name   string      `json:"name"`
-	Amount float64     `json:"amount"`
+	ID        pgtype.UUID        `json:"id"`
+	Name      string             `json:"name"`
+	Amount    decimal.Decimal    `json:"amount"`
+	Type      string             `json:"type"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	PlanID    pgtype.UUID        `json:"plan_id"`
+	Rule      pgtype.Text        `json:"rule"`
+	Rates     []float64          `json:"rates"`
 }
 
 func (q *Queries) GetCommissionByOrderID(ctx context.Context, orderID pgtype.UUID) ([]GetCommissionByOrderIDRow, error) {
@@ -71,7 +144,16 @@ func (q *Queries) GetCommissionByOrderID(ctx context.Context, orderID pgtype.UUI
 	items := []GetCommissionByOrderIDRow{}
 	for rows.Next() {
 		var i GetCommissionByOrderIDRow
-		if err := rows.Scan(&i.ID, &i.Name, &i.Amount); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Amount,
+			&i.Type,
+			&i.CreatedAt,
+			&i.PlanID,
+			&i.Rule,
+			&i.Rates,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -83,22 +165,98 @@ func (q *Queries) GetCommissionByOrderID(ctx context.Context, orderID pgtype.UUI
 }
 
 const getTotalCommission = `-- name: GetTotalCommission :one
-SELECT SUM(amount)::FLOAT FROM commissions WHERE order_id = $1
+SELECT SUM(amount)::NUMERIC FROM commissions WHERE order_id = $1
 `
 
-func (q *Queries) GetTotalCommission(ctx context.Context, orderID pgtype.UUID) (float64, error) {
+func (q *Queries) GetTotalCommission(ctx context.Context, orderID pgtype.UUID) (decimal.Decimal, error) {
 	row := q.db.QueryRow(ctx, getTotalCommission, orderID)
-	var column_1 float64
+	var column_1 decimal.Decimal
 	err := row.Scan(&column_1)
 	return column_1, err
 }
 
+const listCommissionBalancesByOrderID = `-- name: ListCommissionBalancesByOrderID :many
+SELECT affiliate_id,
+       COALESCE(SUM(amount) FILTER (WHERE type = 'commission'), 0)::NUMERIC AS earned,
+       SUM(amount)::NUMERIC AS outstanding
+FROM commissions
+WHERE order_id = $1
+GROUP BY affiliate_id
+ORDER BY affiliate_id
+`
+
+type ListCommissionBalancesByOrderIDRow struct {
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
+	Earned      decimal.Decimal `json:"earned"`
+	Outstanding decimal.Decimal `json:"outstanding"`
+}
+
+func (q *Queries) ListCommissionBalancesByOrderID(ctx context.Context, orderID pgtype.UUID) ([]ListCommissionBalancesByOrderIDRow, error) {
+	rows, err := q.db.Query(ctx, listCommissionBalancesByOrderID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListCommissionBalancesByOrderIDRow{}
+	for rows.Next() {
+		var i ListCommissionBalancesByOrderIDRow
+		if err := rows.Scan(&i.AffiliateID, &i.Earned, &i.Outstanding); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listCommissions = `-- name: ListCommissions :many
-SELECT id, order_id, affiliate_id, amount FROM commissions
+SELECT id, order_id, affiliate_id, amount, type, created_at, plan_id, rule, rates
+FROM commissions
+WHERE ($1::uuid IS NULL OR affiliate_id = $1)
+    AND ($2::uuid IS NULL OR order_id = $2)
+    AND ($3::uuid IS NULL OR CASE $4::text
+        WHEN 'created_at' THEN (created_at, id) > ($5::text::timestamptz, $3)
+        WHEN '-created_at' THEN (created_at, id) < ($5::text::timestamptz, $3)
+        WHEN 'amount' THEN (amount, id) > ($5::text::numeric, $3)
+        WHEN '-amount' THEN (amount, id) < ($5::text::numeric, $3)
+        ELSE id > $3
+    END)
+ORDER BY
+    CASE WHEN $4 = 'created_at' THEN created_at END,
+    CASE WHEN $4 = '-created_at' THEN created_at END DESC,
+    CASE WHEN $4 = 'amount' THEN amount END,
+    CASE WHEN $4 = '-amount' THEN amount END DESC,
+    CASE WHEN $4 LIKE '-%' THEN id END DESC,
+    id
+LIMIT $6 OFFSET $7
 `
 
-func (q *Queries) ListCommissions(ctx context.Context) ([]Commission, error) {
-	rows, err := q.db.Query(ctx, listCommissions)
+type ListCommissionsParams struct {
+	AffiliateID pgtype.UUID `json:"affiliate_id"`
+	OrderID     pgtype.UUID `json:"order_id"`
+	AfterID     pgtype.UUID `json:"after_id"`
+	Sort        string      `json:"sort"`
+	AfterValue  pgtype.Text `json:"after_value"`
+	PageLimit   int32       `json:"page_limit"`
+	PageOffset  int32       `json:"page_offset"`
+}
+
+// Every filter is optional. sort is ”, 'created_at' or 'amount', prefixed
+// with '-' for descending order; ties and the default order go by ID. With
+// after_id the list continues after that row, after_value being its sort
+// value.
+func (q *Queries) ListCommissions(ctx context.Context, arg ListCommissionsParams) ([]Commission, error) {
+	rows, err := q.db.Query(ctx, listCommissions,
+		arg.AffiliateID,
+		arg.OrderID,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterValue,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +269,11 @@ func (q *Queries) ListCommissions(ctx context.Context) ([]Commission, error) {
 			&i.OrderID,
 			&i.AffiliateID,
 			&i.Amount,
+			&i.Type,
+			&i.CreatedAt,
+			&i.PlanID,
+			&i.Rule,
+			&i.Rates,
 		); err != nil {
 			return nil, err
 		}
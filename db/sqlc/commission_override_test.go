@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertProductCommissionOverride(t *testing.T) {
+	product := createRandomProduct(t)
+
+	override1, err := testQueries.UpsertProductCommissionOverride(context.Background(), UpsertProductCommissionOverrideParams{
+		ProductID: product.ID,
+		Rates:     []float64{0.10, 0.05},
+	})
+	require.NoError(t, err)
+	require.Equal(t, product.ID, override1.ProductID)
+	require.False(t, override1.Category.Valid)
+
+	override2, err := testQueries.UpsertProductCommissionOverride(context.Background(), UpsertProductCommissionOverrideParams{
+		ProductID: product.ID,
+		Rates:     []float64{0.30},
+	})
+	require.NoError(t, err)
+	require.Equal(t, override1.ID, override2.ID)
+	require.Equal(t, []float64{0.30}, override2.Rates)
+
+	override3, err := testQueries.GetCommissionOverrideByProductID(context.Background(), product.ID)
+	require.NoError(t, err)
+	require.Equal(t, override2.Rates, override3.Rates)
+
+	affectedRows, err := testQueries.DeleteProductCommissionOverride(context.Background(), product.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affectedRows)
+
+	_, err = testQueries.GetCommissionOverrideByProductID(context.Background(), product.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestUpsertCategoryCommissionOverride(t *testing.T) {
+	name, err := helpers.GenerateRandomString(10)
+	require.NoError(t, err)
+	category := pgtype.Text{String: name, Valid: true}
+
+	override1, err := testQueries.UpsertCategoryCommissionOverride(context.Background(), UpsertCategoryCommissionOverrideParams{
+		Category: category,
+		Rates:    []float64{0.25, 0.10},
+	})
+	require.NoError(t, err)
+	require.Equal(t, category, override1.Category)
+	require.False(t, override1.ProductID.Valid)
+
+	override2, err := testQueries.GetCommissionOverrideByCategory(context.Background(), category)
+	require.NoError(t, err)
+	require.Equal(t, override1.ID, override2.ID)
+
+	affectedRows, err := testQueries.DeleteCategoryCommissionOverride(context.Background(), category)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affectedRows)
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCommissionPlan(t *testing.T, effectiveFrom time.Time, effectiveTo pgtype.Timestamptz) CommissionPlan {
+	name, err := helpers.GenerateRandomString(10)
+	require.NoError(t, err)
+
+	arg := CreateCommissionPlanParams{
+		Name:          name,
+		Rates:         []float64{0.30, 0.20, 0.10},
+		EffectiveFrom: pgtype.Timestamptz{Time: effectiveFrom, Valid: true},
+		EffectiveTo:   effectiveTo,
+	}
+
+	plan, err := testQueries.CreateCommissionPlan(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, plan)
+
+	require.Equal(t, arg.Name, plan.Name)
+	require.Equal(t, arg.Rates, plan.Rates)
+	require.WithinDuration(t, effectiveFrom, plan.EffectiveFrom.Time, time.Second)
+	require.Equal(t, arg.EffectiveTo.Valid, plan.EffectiveTo.Valid)
+
+	require.NotZero(t, plan.ID)
+
+	return plan
+}
+
+func TestCreateCommissionPlan(t *testing.T) {
+	createRandomCommissionPlan(t, time.Now(), pgtype.Timestamptz{})
+}
+
+func TestGetCommissionPlanByID(t *testing.T) {
+	plan1 := createRandomCommissionPlan(t, time.Now(), pgtype.Timestamptz{})
+
+	plan2, err := testQueries.GetCommissionPlanByID(context.Background(), plan1.ID)
+	require.NoError(t, err)
+	require.Equal(t, plan1, plan2)
+}
+
+func TestListCommissionPlans(t *testing.T) {
+	createRandomCommissionPlan(t, time.Now(), pgtype.Timestamptz{})
+
+	plans, err := testQueries.ListCommissionPlans(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, plans)
+
+	for i := 1; i < len(plans); i++ {
+		require.False(t, plans[i].EffectiveFrom.Time.After(plans[i-1].EffectiveFrom.Time))
+	}
+}
+
+func TestGetCommissionPlanInForce(t *testing.T) {
+	start := time.Now().AddDate(50, 0, 0)
+	end := start.AddDate(1, 0, 0)
+
+	older := createRandomCommissionPlan(t, start, pgtype.Timestamptz{Time: end, Valid: true})
+	newer := createRandomCommissionPlan(t, start.AddDate(0, 6, 0), pgtype.Timestamptz{})
+
+	plan, err := testQueries.GetCommissionPlanInForce(context.Background(), pgtype.Timestamptz{Time: start.AddDate(0, 1, 0), Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, older.ID, plan.ID)
+
+	plan, err = testQueries.GetCommissionPlanInForce(context.Background(), pgtype.Timestamptz{Time: start.AddDate(0, 7, 0), Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, newer.ID, plan.ID)
+}
+
+func TestUpdateCommissionPlan(t *testing.T) {
+	plan := createRandomCommissionPlan(t, time.Now(), pgtype.Timestamptz{})
+	closeAt := time.Now().AddDate(0, 1, 0)
+
+	updated, err := testQueries.UpdateCommissionPlan(context.Background(), UpdateCommissionPlanParams{
+		EffectiveTo: pgtype.Timestamptz{Time: closeAt, Valid: true},
+		ID:          plan.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, plan.Name, updated.Name)
+	require.Equal(t, plan.Rates, updated.Rates)
+	require.WithinDuration(t, closeAt, updated.EffectiveTo.Time, time.Second)
+}
+
+func TestDeleteCommissionPlan(t *testing.T) {
+	plan := createRandomCommissionPlan(t, time.Now(), pgtype.Timestamptz{})
+
+	used, err := testQueries.CountCommissionsByPlanID(context.Background(), plan.ID)
+	require.NoError(t, err)
+	require.Zero(t, used)
+
+	affectedRows, err := testQueries.DeleteCommissionPlan(context.Background(), plan.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affectedRows)
+
+	_, err = testQueries.GetCommissionPlanByID(context.Background(), plan.ID)
+	require.Error(t, err)
+}
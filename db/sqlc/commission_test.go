@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,7 +16,9 @@ func createRandomCommission(t *testing.T) Commission {
 	arg := CreateCommissionParams{
 		OrderID:     pgtype.UUID{Bytes: uuid.New(), Valid: true},
 		AffiliateID: affiliate.ID,
-		Amount:      100.50,
+		Amount:      decimal.RequireFromString("100.50"),
+		Rule:        pgtype.Text{String: "plan", Valid: true},
+		Rates:       []float64{0.1, 0.05},
 	}
 
 	commission, err := testQueries.CreateCommission(context.Background(), arg)
@@ -24,7 +27,8 @@ func createRandomCommission(t *testing.T) Commission {
 
 	require.Equal(t, arg.OrderID, commission.OrderID)
 	require.Equal(t, arg.AffiliateID, commission.AffiliateID)
-	require.Equal(t, arg.Amount, commission.Amount)
+	require.True(t, arg.Amount.Equal(commission.Amount))
+	require.Equal(t, arg.Rates, commission.Rates)
 
 	require.NotZero(t, commission.ID)
 
@@ -52,14 +56,58 @@ func TestListCommissions(t *testing.T) {
 		createRandomCommission(t)
 	}
 
-	commissions, err := testQueries.ListCommissions(context.Background())
+	commissions, err := testQueries.ListCommissions(context.Background(), ListCommissionsParams{PageLimit: 5})
 	require.NoError(t, err)
-	require.NotEmpty(t, commissions)
+	require.Len(t, commissions, 5)
 
 	for _, commission := range commissions {
 		require.NotEmpty(t, commission)
 	}
 }
 
+func TestListCommissionsByAffiliate(t *testing.T) {
+	commission := createRandomCommission(t)
+
+	commissions, err := testQueries.ListCommissions(context.Background(), ListCommissionsParams{
+		AffiliateID: commission.AffiliateID,
+		Sort:        "-created_at",
+		PageLimit:   10,
+	})
+	require.NoError(t, err)
+	require.Len(t, commissions, 1)
+	require.Equal(t, commission.ID, commissions[0].ID)
+
+	count, err := testQueries.CountCommissions(context.Background(), CountCommissionsParams{AffiliateID: commission.AffiliateID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+
 
 
+func TestCreateCommissionReversal(t *testing.T) {
+	commission := createRandomCommission(t)
+
+	reversal, err := testQueries.CreateCommissionReversal(context.Background(), CreateCommissionReversalParams{
+		OrderID:     commission.OrderID,
+		AffiliateID: commission.AffiliateID,
+		Amount:      decimal.RequireFromString("-40.25"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, commission.OrderID, reversal.OrderID)
+	require.Equal(t, "reversal", reversal.Type)
+	require.True(t, decimal.RequireFromString("-40.25").Equal(reversal.Amount))
+
+	balances, err := testQueries.ListCommissionBalancesByOrderID(context.Background(), commission.OrderID)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	require.Equal(t, commission.AffiliateID, balances[0].AffiliateID)
+	require.Equal(t, commission.Amount, balances[0].Earned)
+	require.True(t, commission.Amount.Sub(decimal.RequireFromString("40.25")).Equal(balances[0].Outstanding))
+
+	distribution, err := testQueries.GetCommissionByOrderID(context.Background(), commission.OrderID)
+	require.NoError(t, err)
+	require.Len(t, distribution, 2)
+	require.Equal(t, "commission", distribution[0].Type)
+	require.Equal(t, "reversal", distribution[1].Type)
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	arg := CreateIdempotencyKeyParams{
+		Key:          uuid.New().String(),
+		RequestHash:  "hash",
+		StatusCode:   201,
+		ResponseBody: []byte(`{"status":"success"}`),
+	}
+
+	rows, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Key:          arg.Key,
+		RequestHash:  "other",
+		StatusCode:   200,
+		ResponseBody: []byte(`{}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), rows)
+
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), arg.Key)
+	require.NoError(t, err)
+	require.Equal(t, arg.RequestHash, stored.RequestHash)
+	require.Equal(t, arg.StatusCode, stored.StatusCode)
+	require.JSONEq(t, string(arg.ResponseBody), string(stored.ResponseBody))
+}
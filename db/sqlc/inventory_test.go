@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomInventoryMovement(t *testing.T, product Product, kind string, quantity int32) InventoryMovement {
+	arg := CreateInventoryMovementParams{
+		ProductID: product.ID,
+		Kind:      kind,
+		Quantity:  quantity,
+		Reason:    pgtype.Text{String: kind, Valid: true},
+		Actor:     pgtype.Text{String: "tester", Valid: true},
+	}
+
+	movement, err := testQueries.CreateInventoryMovement(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, movement.ID)
+	require.Equal(t, arg.ProductID, movement.ProductID)
+	require.Equal(t, arg.Kind, movement.Kind)
+	require.Equal(t, arg.Quantity, movement.Quantity)
+	require.Equal(t, arg.Reason, movement.Reason)
+	require.Equal(t, arg.Actor, movement.Actor)
+	require.True(t, movement.CreatedAt.Valid)
+
+	return movement
+}
+
+func TestCreateInventoryMovement(t *testing.T) {
+	createRandomInventoryMovement(t, createRandomProduct(t), "restock", 5)
+}
+
+func TestListInventoryMovements(t *testing.T) {
+	product := createRandomProduct(t)
+	createRandomInventoryMovement(t, product, "initial_stock", 100)
+	createRandomInventoryMovement(t, product, "sale", -30)
+	createRandomInventoryMovement(t, product, "restock", 5)
+
+	movements, err := testQueries.ListInventoryMovements(context.Background(), ListInventoryMovementsParams{
+		ProductID:  product.ID,
+		PageLimit:  2,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, movements, 2)
+
+	// Newest first, with the stock right after each movement.
+	require.Equal(t, "restock", movements[0].Kind)
+	require.Equal(t, int32(75), movements[0].RunningQuantity)
+	require.Equal(t, "sale", movements[1].Kind)
+	require.Equal(t, int32(70), movements[1].RunningQuantity)
+
+	count, err := testQueries.CountInventoryMovements(context.Background(), product.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+}
+
+func TestListInventoryMismatches(t *testing.T) {
+	balanced := createRandomProduct(t)
+	createRandomInventoryMovement(t, balanced, "initial_stock", balanced.Quantity)
+
+	drifted := createRandomProduct(t)
+	createRandomInventoryMovement(t, drifted, "initial_stock", drifted.Quantity-1)
+
+	mismatches, err := testQueries.ListInventoryMismatches(context.Background())
+	require.NoError(t, err)
+
+	var found bool
+	for _, mismatch := range mismatches {
+		require.NotEqual(t, balanced.ID, mismatch.ID)
+		if mismatch.ID == drifted.ID {
+			found = true
+			require.Equal(t, drifted.Quantity, mismatch.Quantity)
+			require.Equal(t, drifted.Quantity-1, mismatch.MovementQuantity)
+		}
+	}
+	require.True(t, found)
+}
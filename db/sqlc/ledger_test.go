@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateLedgerEntry(t *testing.T) {
+	user := createRandomUser(t)
+	transactionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	amount := decimal.RequireFromString("25.50")
+
+	tx, err := testDB.Begin(context.Background())
+	require.NoError(t, err)
+	qtx := testQueries.WithTx(tx)
+
+	entry1, err := qtx.CreateLedgerEntry(context.Background(), CreateLedgerEntryParams{
+		TransactionID: transactionID,
+		Kind:          "top_up",
+		Account:       "user",
+		AccountID:     user.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, entry1.ID)
+	require.True(t, amount.Equal(entry1.Amount))
+
+	_, err = qtx.CreateLedgerEntry(context.Background(), CreateLedgerEntryParams{
+		TransactionID: transactionID,
+		Kind:          "top_up",
+		Account:       "external",
+		Amount:        amount.Neg(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(context.Background()))
+
+	entries, err := testQueries.ListLedgerEntriesByTransactionID(context.Background(), transactionID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	balance, err := testQueries.GetLedgerBalance(context.Background(), GetLedgerBalanceParams{
+		Account:   "user",
+		AccountID: user.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, amount.Equal(balance))
+
+	mismatches, err := testQueries.ListUserBalanceMismatches(context.Background())
+	require.NoError(t, err)
+
+	var found bool
+	for _, m := range mismatches {
+		if m.ID == user.ID {
+			found = true
+			require.True(t, amount.Equal(m.LedgerBalance))
+		}
+	}
+	require.True(t, found)
+}
+
+func TestCreateLedgerEntryUnbalanced(t *testing.T) {
+	user := createRandomUser(t)
+
+	tx, err := testDB.Begin(context.Background())
+	require.NoError(t, err)
+	qtx := testQueries.WithTx(tx)
+
+	_, err = qtx.CreateLedgerEntry(context.Background(), CreateLedgerEntryParams{
+		TransactionID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Kind:          "top_up",
+		Account:       "user",
+		AccountID:     user.ID,
+		Amount:        decimal.NewFromInt(10),
+	})
+	require.NoError(t, err)
+
+	err = tx.Commit(context.Background())
+	require.Error(t, err)
+}
+
+func TestListAccountTransactions(t *testing.T) {
+	user := createRandomUser(t)
+
+	amounts := []string{"100.00", "-30.00", "-20.00"}
+	kinds := []string{"top_up", "purchase", "purchase"}
+	for i, amount := range amounts {
+		tx, err := testDB.Begin(context.Background())
+		require.NoError(t, err)
+		qtx := testQueries.WithTx(tx)
+
+		transactionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+		_, err = qtx.CreateLedgerEntry(context.Background(), CreateLedgerEntryParams{
+			TransactionID: transactionID,
+			Kind:          kinds[i],
+			Account:       "user",
+			AccountID:     user.ID,
+			Amount:        decimal.RequireFromString(amount),
+			Reason:        pgtype.Text{String: kinds[i], Valid: true},
+		})
+		require.NoError(t, err)
+		_, err = qtx.CreateLedgerEntry(context.Background(), CreateLedgerEntryParams{
+			TransactionID: transactionID,
+			Kind:          kinds[i],
+			Account:       "external",
+			Amount:        decimal.RequireFromString(amount).Neg(),
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit(context.Background()))
+	}
+
+	rows, err := testQueries.ListAccountTransactions(context.Background(), ListAccountTransactionsParams{
+		Account:    "user",
+		AccountID:  user.ID,
+		PageLimit:  10,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.True(t, decimal.RequireFromString("50").Equal(rows[0].RunningBalance))
+	require.True(t, decimal.RequireFromString("70").Equal(rows[1].RunningBalance))
+	require.True(t, decimal.RequireFromString("100").Equal(rows[2].RunningBalance))
+
+	filtered, err := testQueries.ListAccountTransactions(context.Background(), ListAccountTransactionsParams{
+		Account:    "user",
+		AccountID:  user.ID,
+		Kind:       pgtype.Text{String: "purchase", Valid: true},
+		PageLimit:  1,
+		PageOffset: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	require.True(t, decimal.RequireFromString("70").Equal(filtered[0].RunningBalance))
+
+	count, err := testQueries.CountAccountTransactions(context.Background(), CountAccountTransactionsParams{
+		Account:   "user",
+		AccountID: user.ID,
+		Kind:      pgtype.Text{String: "purchase", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
@@ -6,33 +6,177 @@ package db
 
 import (
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
 type Affiliate struct {
-	ID              pgtype.UUID `json:"id"`
-	Name            string      `json:"name"`
-	MasterAffiliate pgtype.UUID `json:"master_affiliate"`
-	Balance         float64     `json:"balance"`
+	ID              pgtype.UUID     `json:"id"`
+	Name            string          `json:"name"`
+	MasterAffiliate pgtype.UUID     `json:"master_affiliate"`
+	Balance         decimal.Decimal `json:"balance"`
+}
+
+type AuthSession struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+}
+
+type Cart struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Status    string             `json:"status"`
+	OrderID   pgtype.UUID        `json:"order_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CartItem struct {
+	ID        pgtype.UUID        `json:"id"`
+	CartID    pgtype.UUID        `json:"cart_id"`
+	ProductID pgtype.UUID        `json:"product_id"`
+	Quantity  int32              `json:"quantity"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type Commission struct {
-	ID          pgtype.UUID `json:"id"`
-	OrderID     pgtype.UUID `json:"order_id"`
-	AffiliateID pgtype.UUID `json:"affiliate_id"`
-	Amount      float64     `json:"amount"`
+	ID          pgtype.UUID        `json:"id"`
+	OrderID     pgtype.UUID        `json:"order_id"`
+	AffiliateID pgtype.UUID        `json:"affiliate_id"`
+	Amount      decimal.Decimal    `json:"amount"`
+	Type        string             `json:"type"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	PlanID      pgtype.UUID        `json:"plan_id"`
+	Rule        pgtype.Text        `json:"rule"`
+	Rates       []float64          `json:"rates"`
+}
+
+type CommissionOverride struct {
+	ID        pgtype.UUID        `json:"id"`
+	ProductID pgtype.UUID        `json:"product_id"`
+	Category  pgtype.Text        `json:"category"`
+	Rates     []float64          `json:"rates"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CommissionPlan struct {
+	ID            pgtype.UUID        `json:"id"`
+	Name          string             `json:"name"`
+	Rates         []float64          `json:"rates"`
+	EffectiveFrom pgtype.Timestamptz `json:"effective_from"`
+	EffectiveTo   pgtype.Timestamptz `json:"effective_to"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Key          string             `json:"key"`
+	RequestHash  string             `json:"request_hash"`
+	StatusCode   int32              `json:"status_code"`
+	ResponseBody []byte             `json:"response_body"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type InventoryMovement struct {
+	ID        pgtype.UUID        `json:"id"`
+	ProductID pgtype.UUID        `json:"product_id"`
+	Kind      string             `json:"kind"`
+	Quantity  int32              `json:"quantity"`
+	Reason    pgtype.Text        `json:"reason"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	Actor     pgtype.Text        `json:"actor"`
+	OrderID   pgtype.UUID        `json:"order_id"`
+}
+
+type LedgerEntry struct {
+	ID            pgtype.UUID        `json:"id"`
+	TransactionID pgtype.UUID        `json:"transaction_id"`
+	Kind          string             `json:"kind"`
+	Account       string             `json:"account"`
+	AccountID     pgtype.UUID        `json:"account_id"`
+	Amount        decimal.Decimal    `json:"amount"`
+	OrderID       pgtype.UUID        `json:"order_id"`
+	CommissionID  pgtype.UUID        `json:"commission_id"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Reason        pgtype.Text        `json:"reason"`
+}
+
+type LoginFailure struct {
+	Scope         string             `json:"scope"`
+	Key           string             `json:"key"`
+	Failures      int32              `json:"failures"`
+	LastFailureAt pgtype.Timestamptz `json:"last_failure_at"`
+}
+
+type Order struct {
+	ID             pgtype.UUID        `json:"id"`
+	UserID         pgtype.UUID        `json:"user_id"`
+	TotalCost      decimal.Decimal    `json:"total_cost"`
+	Status         string             `json:"status"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	RefundedAmount decimal.Decimal    `json:"refunded_amount"`
+}
+
+type OrderItem struct {
+	ID               pgtype.UUID     `json:"id"`
+	OrderID          pgtype.UUID     `json:"order_id"`
+	ProductID        pgtype.UUID     `json:"product_id"`
+	Quantity         int32           `json:"quantity"`
+	UnitPrice        decimal.Decimal `json:"unit_price"`
+	TotalPrice       decimal.Decimal `json:"total_price"`
+	RefundedQuantity int32           `json:"refunded_quantity"`
+	ListPrice        decimal.Decimal `json:"list_price"`
+	PriceID          pgtype.UUID     `json:"price_id"`
 }
 
 type Product struct {
-	ID       pgtype.UUID `json:"id"`
-	Name     string      `json:"name"`
-	Quantity int32       `json:"quantity"`
-	Price    float64     `json:"price"`
+	ID          pgtype.UUID        `json:"id"`
+	Name        string             `json:"name"`
+	Quantity    int32              `json:"quantity"`
+	Price       decimal.Decimal    `json:"price"`
+	Category    pgtype.Text        `json:"category"`
+	ArchivedAt  pgtype.Timestamptz `json:"archived_at"`
+	Description string             `json:"description"`
+	Tags        []string           `json:"tags"`
+}
+
+type ProductPrice struct {
+	ID            pgtype.UUID        `json:"id"`
+	ProductID     pgtype.UUID        `json:"product_id"`
+	Price         decimal.Decimal    `json:"price"`
+	EffectiveFrom pgtype.Timestamptz `json:"effective_from"`
+	EffectiveTo   pgtype.Timestamptz `json:"effective_to"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        pgtype.UUID        `json:"id"`
+	SessionID pgtype.UUID        `json:"session_id"`
+	TokenHash string             `json:"token_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	RotatedAt pgtype.Timestamptz `json:"rotated_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type StockReservation struct {
+	ID        pgtype.UUID        `json:"id"`
+	ProductID pgtype.UUID        `json:"product_id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Quantity  int32              `json:"quantity"`
+	Status    string             `json:"status"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	OrderID   pgtype.UUID        `json:"order_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type User struct {
-	ID          pgtype.UUID `json:"id"`
-	Username    string      `json:"username"`
-	Password    string      `json:"password"`
-	Balance     float64     `json:"balance"`
-	AffiliateID pgtype.UUID `json:"affiliate_id"`
+	ID          pgtype.UUID     `json:"id"`
+	Username    string          `json:"username"`
+	Password    string          `json:"password"`
+	Balance     decimal.Decimal `json:"balance"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
+	Role        string          `json:"role"`
 }
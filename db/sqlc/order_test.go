@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOrder(t *testing.T, user User, product Product) Order {
+	arg := CreateOrderParams{
+		UserID:    user.ID,
+		TotalCost: product.Price.Mul(decimal.NewFromInt(2)),
+		Status:    "completed",
+	}
+
+	order, err := testQueries.CreateOrder(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, order)
+
+	require.Equal(t, arg.UserID, order.UserID)
+	require.True(t, arg.TotalCost.Equal(order.TotalCost))
+	require.Equal(t, arg.Status, order.Status)
+
+	require.NotZero(t, order.ID)
+	require.True(t, order.CreatedAt.Valid)
+	require.True(t, order.UpdatedAt.Valid)
+
+	item, err := testQueries.CreateOrderItem(context.Background(), CreateOrderItemParams{
+		OrderID:    order.ID,
+		ProductID:  product.ID,
+		Quantity:   2,
+		UnitPrice:  product.Price,
+		TotalPrice: product.Price.Mul(decimal.NewFromInt(2)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, order.ID, item.OrderID)
+	require.Equal(t, product.ID, item.ProductID)
+
+	return order
+}
+
+func TestCreateOrder(t *testing.T) {
+	createRandomOrder(t, createRandomUser(t), createRandomProduct(t))
+}
+
+func TestGetOrderByID(t *testing.T) {
+	order1 := createRandomOrder(t, createRandomUser(t), createRandomProduct(t))
+
+	order2, err := testQueries.GetOrderByID(context.Background(), order1.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, order2)
+
+	require.Equal(t, order1.ID, order2.ID)
+	require.Equal(t, order1.UserID, order2.UserID)
+	require.Equal(t, order1.TotalCost, order2.TotalCost)
+	require.Equal(t, order1.Status, order2.Status)
+}
+
+func TestListOrderItemsByOrderID(t *testing.T) {
+	product := createRandomProduct(t)
+	order := createRandomOrder(t, createRandomUser(t), product)
+
+	items, err := testQueries.ListOrderItemsByOrderID(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	require.Equal(t, product.ID, items[0].ProductID)
+	require.Equal(t, product.Name, items[0].ProductName)
+	require.Equal(t, int32(2), items[0].Quantity)
+	require.Equal(t, product.Price, items[0].UnitPrice)
+}
+
+func TestListOrdersByUserID(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomOrder(t, user, createRandomProduct(t))
+	}
+
+	orders, err := testQueries.ListOrdersByUserID(context.Background(), ListOrdersByUserIDParams{
+		UserID: user.ID,
+		Limit:  2,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+
+	for _, order := range orders {
+		require.Equal(t, user.ID, order.UserID)
+	}
+
+	count, err := testQueries.CountOrdersByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+}
+
+func TestAddOrderItemRefundedQuantity(t *testing.T) {
+	order := createRandomOrder(t, createRandomUser(t), createRandomProduct(t))
+
+	items, err := testQueries.ListOrderItemsByOrderID(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	affectedRows, err := testQueries.AddOrderItemRefundedQuantity(context.Background(), AddOrderItemRefundedQuantityParams{
+		RefundedQuantity: 1,
+		ID:               items[0].ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affectedRows)
+
+	affectedRows, err = testQueries.AddOrderItemRefundedQuantity(context.Background(), AddOrderItemRefundedQuantityParams{
+		RefundedQuantity: 2,
+		ID:               items[0].ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), affectedRows)
+
+	items, err = testQueries.ListOrderItemsByOrderID(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), items[0].RefundedQuantity)
+}
+
+func TestUpdateOrderRefund(t *testing.T) {
+	order := createRandomOrder(t, createRandomUser(t), createRandomProduct(t))
+
+	updated, err := testQueries.UpdateOrderRefund(context.Background(), UpdateOrderRefundParams{
+		RefundedAmount: order.TotalCost,
+		Status:         "cancelled",
+		ID:             order.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, order.ID, updated.ID)
+	require.Equal(t, order.TotalCost, updated.RefundedAmount)
+	require.Equal(t, "cancelled", updated.Status)
+	require.False(t, updated.UpdatedAt.Time.Before(order.UpdatedAt.Time))
+}
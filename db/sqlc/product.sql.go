@@ -9,26 +9,154 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
+const addProductQuantity = `-- name: AddProductQuantity :execrows
+UPDATE products SET quantity = quantity + $1 WHERE id = $2
+`
+
+type AddProductQuantityParams struct {
+	Quantity int32       `json:"quantity"`
+	ID       pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) AddProductQuantity(ctx context.Context, arg AddProductQuantityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addProductQuantity, arg.Quantity, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const archiveProduct = `-- name: ArchiveProduct :one
+UPDATE products SET archived_at = now() WHERE id = $1 AND archived_at IS NULL RETURNING id, name, quantity, price, category, archived_at, description, tags
+`
+
+func (q *Queries) ArchiveProduct(ctx context.Context, id pgtype.UUID) (Product, error) {
+	row := q.db.QueryRow(ctx, archiveProduct, id)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Quantity,
+		&i.Price,
+		&i.Category,
+		&i.ArchivedAt,
+		&i.Description,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const countProducts = `-- name: CountProducts :one
+SELECT COUNT(*)
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, $1) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND ($2::text IS NULL OR name ILIKE '%' || $2 || '%' ESCAPE '\')
+    AND ($3::numeric IS NULL OR ep.effective_price >= $3)
+    AND ($4::numeric IS NULL OR ep.effective_price <= $4)
+`
+
+type CountProductsParams struct {
+	At       pgtype.Timestamptz  `json:"at"`
+	Name     pgtype.Text         `json:"name"`
+	MinPrice decimal.NullDecimal `json:"min_price"`
+	MaxPrice decimal.NullDecimal `json:"max_price"`
+}
+
+func (q *Queries) CountProducts(ctx context.Context, arg CountProductsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countProducts,
+		arg.At,
+		arg.Name,
+		arg.MinPrice,
+		arg.MaxPrice,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchProducts = `-- name: CountSearchProducts :one
+SELECT COUNT(*)
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, $1) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND ($2::text IS NULL OR to_tsvector('english', name || ' ' || description) @@ websearch_to_tsquery('english', $2))
+    AND ($3::text IS NULL OR category = $3)
+    AND ($4::text IS NULL OR $4 = ANY(tags))
+    AND ($5::numeric IS NULL OR ep.effective_price >= $5)
+    AND ($6::numeric IS NULL OR ep.effective_price <= $6)
+    AND (NOT $7::bool OR quantity > (
+        SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+        WHERE r.product_id = products.id AND r.status = 'active' AND r.expires_at > now()
+    ))
+`
+
+type CountSearchProductsParams struct {
+	At       pgtype.Timestamptz  `json:"at"`
+	Query    pgtype.Text         `json:"query"`
+	Category pgtype.Text         `json:"category"`
+	Tag      pgtype.Text         `json:"tag"`
+	MinPrice decimal.NullDecimal `json:"min_price"`
+	MaxPrice decimal.NullDecimal `json:"max_price"`
+	InStock  bool                `json:"in_stock"`
+}
+
+func (q *Queries) CountSearchProducts(ctx context.Context, arg CountSearchProductsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchProducts,
+		arg.At,
+		arg.Query,
+		arg.Category,
+		arg.Tag,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.InStock,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createProduct = `-- name: CreateProduct :one
-INSERT INTO products (name, quantity, price) VALUES ($1, $2, $3) RETURNING id, name, quantity, price
+INSERT INTO products (name, quantity, price, category, description, tags)
+VALUES ($1, $2, $3, $4, $5, COALESCE($1::text[], '{}'))
+RETURNING id, name, quantity, price, category, archived_at, description, tags
 `
 
 type CreateProductParams struct {
-	Name     string  `json:"name"`
-	Quantity int32   `json:"quantity"`
-	Price    float64 `json:"price"`
+	Name        string          `json:"name"`
+	Quantity    int32           `json:"quantity"`
+	Price       decimal.Decimal `json:"price"`
+	Category    pgtype.Text     `json:"category"`
+	Description string          `json:"description"`
+	Tags        []string        `json:"tags"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
-	row := q.db.QueryRow(ctx, createProduct, arg.Name, arg.Quantity, arg.Price)
+	row := q.db.QueryRow(ctx, createProduct,
+		arg.Name,
+		arg.Quantity,
+		arg.Price,
+		arg.Category,
+		arg.Description,
+		arg.Tags,
+	)
 	var i Product
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Quantity,
 		&i.Price,
+		&i.Category,
+		&i.ArchivedAt,
+		&i.Description,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -51,7 +179,7 @@ func (q *Queries) DeductProductQuantity(ctx context.Context, arg DeductProductQu
 }
 
 const getProductByID = `-- name: GetProductByID :one
-SELECT id, name, quantity, price FROM products WHERE id = $1
+SELECT id, name, quantity, price, category, archived_at, description, tags FROM products WHERE id = $1
 `
 
 func (q *Queries) GetProductByID(ctx context.Context, id pgtype.UUID) (Product, error) {
@@ -62,28 +190,277 @@ func (q *Queries) GetProductByID(ctx context.Context, id pgtype.UUID) (Product,
 		&i.Name,
 		&i.Quantity,
 		&i.Price,
+		&i.Category,
+		&i.ArchivedAt,
+		&i.Description,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getProductByIDForUpdate = `-- name: GetProductByIDForUpdate :one
+SELECT id, name, quantity, price, category, archived_at, description, tags FROM products WHERE id = $1 FOR UPDATE
+`
+
+// Locks the product row until the end of the transaction so concurrent
+// orders see each other's stock deductions.
+func (q *Queries) GetProductByIDForUpdate(ctx context.Context, id pgtype.UUID) (Product, error) {
+	row := q.db.QueryRow(ctx, getProductByIDForUpdate, id)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Quantity,
+		&i.Price,
+		&i.Category,
+		&i.ArchivedAt,
+		&i.Description,
+		&i.Tags,
 	)
 	return i, err
 }
 
+const listProductCategoryFacets = `-- name: ListProductCategoryFacets :many
+SELECT category, COUNT(*) AS count
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, $1) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND ($2::text IS NULL OR to_tsvector('english', name || ' ' || description) @@ websearch_to_tsquery('english', $2))
+    AND ($3::text IS NULL OR $3 = ANY(tags))
+    AND ($4::numeric IS NULL OR ep.effective_price >= $4)
+    AND ($5::numeric IS NULL OR ep.effective_price <= $5)
+    AND (NOT $6::bool OR quantity > (
+        SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+        WHERE r.product_id = products.id AND r.status = 'active' AND r.expires_at > now()
+    ))
+GROUP BY category
+ORDER BY count DESC, category NULLS LAST
+`
+
+type ListProductCategoryFacetsParams struct {
+	At       pgtype.Timestamptz  `json:"at"`
+	Query    pgtype.Text         `json:"query"`
+	Tag      pgtype.Text         `json:"tag"`
+	MinPrice decimal.NullDecimal `json:"min_price"`
+	MaxPrice decimal.NullDecimal `json:"max_price"`
+	InStock  bool                `json:"in_stock"`
+}
+
+type ListProductCategoryFacetsRow struct {
+	Category pgtype.Text `json:"category"`
+	Count    int64       `json:"count"`
+}
+
+// Counts the search matches per category, products without a category
+// under NULL. The category filter is left out so the counts show what
+// choosing another category would return.
+func (q *Queries) ListProductCategoryFacets(ctx context.Context, arg ListProductCategoryFacetsParams) ([]ListProductCategoryFacetsRow, error) {
+	rows, err := q.db.Query(ctx, listProductCategoryFacets,
+		arg.At,
+		arg.Query,
+		arg.Tag,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.InStock,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProductCategoryFacetsRow{}
+	for rows.Next() {
+		var i ListProductCategoryFacetsRow
+		if err := rows.Scan(&i.Category, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProducts = `-- name: ListProducts :many
-SELECT id, name, quantity, price FROM products
+SELECT id, name, quantity, price, category, archived_at, description, tags, ep.effective_price
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, $1) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND ($2::text IS NULL OR name ILIKE '%' || $2 || '%' ESCAPE '\')
+    AND ($3::numeric IS NULL OR ep.effective_price >= $3)
+    AND ($4::numeric IS NULL OR ep.effective_price <= $4)
+    AND ($5::uuid IS NULL OR CASE $6::text
+        WHEN 'name' THEN (name, id) > ($7::text, $5)
+        WHEN '-name' THEN (name, id) < ($7::text, $5)
+        WHEN 'price' THEN (ep.effective_price, id) > ($7::text::numeric, $5)
+        WHEN '-price' THEN (ep.effective_price, id) < ($7::text::numeric, $5)
+        ELSE id > $5
+    END)
+ORDER BY
+    CASE WHEN $6 = 'name' THEN name END,
+    CASE WHEN $6 = '-name' THEN name END DESC,
+    CASE WHEN $6 = 'price' THEN ep.effective_price END,
+    CASE WHEN $6 = '-price' THEN ep.effective_price END DESC,
+    CASE WHEN $6 LIKE '-%' THEN id END DESC,
+    id
+LIMIT $8 OFFSET $9
+`
+
+type ListProductsParams struct {
+	At         pgtype.Timestamptz  `json:"at"`
+	Name       pgtype.Text         `json:"name"`
+	MinPrice   decimal.NullDecimal `json:"min_price"`
+	MaxPrice   decimal.NullDecimal `json:"max_price"`
+	AfterID    pgtype.UUID         `json:"after_id"`
+	Sort       string              `json:"sort"`
+	AfterValue pgtype.Text         `json:"after_value"`
+	PageLimit  int32               `json:"page_limit"`
+	PageOffset int32               `json:"page_offset"`
+}
+
+type ListProductsRow struct {
+	ID             pgtype.UUID        `json:"id"`
+	Name           string             `json:"name"`
+	Quantity       int32              `json:"quantity"`
+	Price          decimal.Decimal    `json:"price"`
+	Category       pgtype.Text        `json:"category"`
+	ArchivedAt     pgtype.Timestamptz `json:"archived_at"`
+	Description    string             `json:"description"`
+	Tags           []string           `json:"tags"`
+	EffectivePrice decimal.Decimal    `json:"effective_price"`
+}
+
+// Every filter is optional. sort is ”, 'name' or 'price', prefixed with '-'
+// for descending order; ties and the default order go by ID. With after_id
+// the list continues after that row, after_value being its sort value. The
+// price filters and the price sort use effective_price, the price in force
+// at the given moment. name matches part of the name, with \, % and _
+// escaped by a backslash.
+func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]ListProductsRow, error) {
+	rows, err := q.db.Query(ctx, listProducts,
+		arg.At,
+		arg.Name,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterValue,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProductsRow{}
+	for rows.Next() {
+		var i ListProductsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Quantity,
+			&i.Price,
+			&i.Category,
+			&i.ArchivedAt,
+			&i.Description,
+			&i.Tags,
+			&i.EffectivePrice,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchProducts = `-- name: SearchProducts :many
+SELECT id, name, quantity, price, category, archived_at, description, tags, ep.effective_price,
+    COALESCE(ts_rank(to_tsvector('english', name || ' ' || description), websearch_to_tsquery('english', $1::text)), 0)::real AS rank
+FROM products
+CROSS JOIN LATERAL (
+    SELECT COALESCE((SELECT pp.price FROM product_price_in_force(products.id, $2) pp), products.price)::numeric AS effective_price
+) ep
+WHERE archived_at IS NULL
+    AND ($1::text IS NULL OR to_tsvector('english', name || ' ' || description) @@ websearch_to_tsquery('english', $1))
+    AND ($3::text IS NULL OR category = $3)
+    AND ($4::text IS NULL OR $4 = ANY(tags))
+    AND ($5::numeric IS NULL OR ep.effective_price >= $5)
+    AND ($6::numeric IS NULL OR ep.effective_price <= $6)
+    AND (NOT $7::bool OR quantity > (
+        SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r
+        WHERE r.product_id = products.id AND r.status = 'active' AND r.expires_at > now()
+    ))
+ORDER BY rank DESC, name, id
+LIMIT $8 OFFSET $9
 `
 
-func (q *Queries) ListProducts(ctx context.Context) ([]Product, error) {
-	rows, err := q.db.Query(ctx, listProducts)
+type SearchProductsParams struct {
+	Query      pgtype.Text         `json:"query"`
+	At         pgtype.Timestamptz  `json:"at"`
+	Category   pgtype.Text         `json:"category"`
+	Tag        pgtype.Text         `json:"tag"`
+	MinPrice   decimal.NullDecimal `json:"min_price"`
+	MaxPrice   decimal.NullDecimal `json:"max_price"`
+	InStock    bool                `json:"in_stock"`
+	PageLimit  int32               `json:"page_limit"`
+	PageOffset int32               `json:"page_offset"`
+}
+
+type SearchProductsRow struct {
+	ID             pgtype.UUID        `json:"id"`
+	Name           string             `json:"name"`
+	Quantity       int32              `json:"quantity"`
+	Price          decimal.Decimal    `json:"price"`
+	Category       pgtype.Text        `json:"category"`
+	ArchivedAt     pgtype.Timestamptz `json:"archived_at"`
+	Description    string             `json:"description"`
+	Tags           []string           `json:"tags"`
+	EffectivePrice decimal.Decimal    `json:"effective_price"`
+	Rank           float32            `json:"rank"`
+}
+
+// Full-text search over the name and the description, best matches first.
+// Every filter is optional; without a query every product matches with a
+// rank of 0 and the products are ordered by name. in_stock keeps the
+// products with stock left after active reservations. The price filters use
+// effective_price, the price in force at the given moment.
+func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error) {
+	rows, err := q.db.Query(ctx, searchProducts,
+		arg.Query,
+		arg.At,
+		arg.Category,
+		arg.Tag,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.InStock,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Product{}
+	items := []SearchProductsRow{}
 	for rows.Next() {
-		var i Product
+		var i SearchProductsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
 			&i.Quantity,
 			&i.Price,
+			&i.Category,
+			&i.ArchivedAt,
+			&i.Description,
+			&i.Tags,
+			&i.EffectivePrice,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -94,3 +471,47 @@ func (q *Queries) ListProducts(ctx context.Context) ([]Product, error) {
 	}
 	return items, nil
 }
+
+const updateProduct = `-- name: UpdateProduct :one
+UPDATE products
+SET name = COALESCE($1, name),
+    price = COALESCE($2, price),
+    category = CASE WHEN $3::text = '' THEN NULL ELSE COALESCE($3, category) END,
+    description = COALESCE($4, description),
+    tags = COALESCE($5::text[], tags)
+WHERE id = $6
+RETURNING id, name, quantity, price, category, archived_at, description, tags
+`
+
+type UpdateProductParams struct {
+	Name        pgtype.Text         `json:"name"`
+	Price       decimal.NullDecimal `json:"price"`
+	Category    pgtype.Text         `json:"category"`
+	Description pgtype.Text         `json:"description"`
+	Tags        []string            `json:"tags"`
+	ID          pgtype.UUID         `json:"id"`
+}
+
+// An empty category removes the product from its category.
+func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error) {
+	row := q.db.QueryRow(ctx, updateProduct,
+		arg.Name,
+		arg.Price,
+		arg.Category,
+		arg.Description,
+		arg.Tags,
+		arg.ID,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Quantity,
+		&i.Price,
+		&i.Category,
+		&i.ArchivedAt,
+		&i.Description,
+		&i.Tags,
+	)
+	return i, err
+}
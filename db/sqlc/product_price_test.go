@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomProductPrice(t *testing.T, productID pgtype.UUID, price int64, effectiveFrom time.Time, effectiveTo pgtype.Timestamptz) ProductPrice {
+	arg := CreateProductPriceParams{
+		ProductID:     productID,
+		Price:         decimal.NewFromInt(price),
+		EffectiveFrom: pgtype.Timestamptz{Time: effectiveFrom, Valid: true},
+		EffectiveTo:   effectiveTo,
+	}
+
+	productPrice, err := testQueries.CreateProductPrice(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, productPrice.ID)
+	require.Equal(t, productID, productPrice.ProductID)
+	require.True(t, arg.Price.Equal(productPrice.Price))
+	require.WithinDuration(t, effectiveFrom, productPrice.EffectiveFrom.Time, time.Second)
+
+	return productPrice
+}
+
+func TestGetProductPriceInForce(t *testing.T) {
+	product := createRandomProduct(t)
+	now := time.Now()
+
+	_, err := testQueries.GetProductPriceInForce(context.Background(), GetProductPriceInForceParams{
+		ProductID: product.ID,
+		At:        pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// A permanent change last month, with a sale this week on top of it and
+	// a price change planned for next month.
+	base := createRandomProductPrice(t, product.ID, 45, now.AddDate(0, -1, 0), pgtype.Timestamptz{})
+	sale := createRandomProductPrice(t, product.ID, 30, now.AddDate(0, 0, -1), pgtype.Timestamptz{Time: now.AddDate(0, 0, 6), Valid: true})
+	planned := createRandomProductPrice(t, product.ID, 55, now.AddDate(0, 1, 0), pgtype.Timestamptz{})
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected ProductPrice
+	}{
+		{name: "During the sale", at: now, expected: sale},
+		{name: "After the sale", at: now.AddDate(0, 0, 7), expected: base},
+		{name: "After the planned change", at: now.AddDate(0, 2, 0), expected: planned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := testQueries.GetProductPriceInForce(context.Background(), GetProductPriceInForceParams{
+				ProductID: product.ID,
+				At:        pgtype.Timestamptz{Time: tt.at, Valid: true},
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected.ID, price.ID)
+		})
+	}
+
+	prices, err := testQueries.ListProductPrices(context.Background(), product.ID)
+	require.NoError(t, err)
+	require.Len(t, prices, 3)
+	require.Equal(t, planned.ID, prices[0].ID)
+}
+
+func TestDeleteProductPrice(t *testing.T) {
+	product := createRandomProduct(t)
+	inForce := createRandomProductPrice(t, product.ID, 40, time.Now().Add(-time.Hour), pgtype.Timestamptz{})
+	planned := createRandomProductPrice(t, product.ID, 60, time.Now().Add(time.Hour), pgtype.Timestamptz{})
+
+	rows, err := testQueries.DeleteProductPrice(context.Background(), DeleteProductPriceParams{ID: inForce.ID, ProductID: product.ID})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	rows, err = testQueries.DeleteProductPrice(context.Background(), DeleteProductPriceParams{ID: planned.ID, ProductID: product.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	_, err = testQueries.GetProductPriceByID(context.Background(), planned.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -3,8 +3,12 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/buranasakS/trading_application/helpers"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,7 +19,7 @@ func createRandomProduct(t *testing.T) Product {
 	arg := CreateProductParams{
 		Name:     name,
 		Quantity: 100,
-		Price:    50.0,
+		Price:    decimal.NewFromInt(50),
 	}
 
 	product, err := testQueries.CreateProduct(context.Background(), arg)
@@ -24,7 +28,7 @@ func createRandomProduct(t *testing.T) Product {
 
 	require.Equal(t, arg.Name, product.Name)
 	require.Equal(t, arg.Quantity, product.Quantity)
-	require.Equal(t, arg.Price, product.Price)
+	require.True(t, arg.Price.Equal(product.Price))
 
 	require.NotZero(t, product.ID)
 
@@ -80,16 +84,270 @@ func TestDeductProductQuantity(t *testing.T) {
 	require.Equal(t, int64(1), affectedRows)
 }
 
+func TestAddProductQuantity(t *testing.T) {
+	product := createRandomProduct(t)
+
+	affectedRows, err := testQueries.AddProductQuantity(context.Background(), AddProductQuantityParams{
+		ID:       product.ID,
+		Quantity: 5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affectedRows)
+
+	updatedProduct, err := testQueries.GetProductByID(context.Background(), product.ID)
+	require.NoError(t, err)
+	require.Equal(t, product.Quantity+5, updatedProduct.Quantity)
+}
+
 func TestListProducts(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		createRandomProduct(t)
 	}
 
-	products, err := testQueries.ListProducts(context.Background())
+	products, err := testQueries.ListProducts(context.Background(), ListProductsParams{PageLimit: 5})
 	require.NoError(t, err)
-	require.NotEmpty(t, products)
+	require.Len(t, products, 5)
 
 	for _, product := range products {
 		require.NotEmpty(t, product)
 	}
 }
+
+func TestListProductsFilteredAndSorted(t *testing.T) {
+	prefix, err := helpers.GenerateRandomString(10)
+	require.NoError(t, err)
+
+	var created []Product
+	for _, price := range []int64{30, 10, 20, 40} {
+		product, err := testQueries.CreateProduct(context.Background(), CreateProductParams{
+			Name:     prefix + "-" + decimal.NewFromInt(price).String(),
+			Quantity: 1,
+			Price:    decimal.NewFromInt(price),
+		})
+		require.NoError(t, err)
+		created = append(created, product)
+	}
+
+	name := pgtype.Text{String: prefix, Valid: true}
+	minPrice := decimal.NewNullDecimal(decimal.NewFromInt(15))
+
+	count, err := testQueries.CountProducts(context.Background(), CountProductsParams{Name: name, MinPrice: minPrice})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	// Walk the 3 matching products two at a time, most expensive first,
+	// continuing after the last row of each page.
+	products, err := testQueries.ListProducts(context.Background(), ListProductsParams{
+		Name:      name,
+		MinPrice:  minPrice,
+		Sort:      "-price",
+		PageLimit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	require.Equal(t, created[3].ID, products[0].ID)
+	require.Equal(t, created[0].ID, products[1].ID)
+
+	last := products[1]
+	products, err = testQueries.ListProducts(context.Background(), ListProductsParams{
+		Name:       name,
+		MinPrice:   minPrice,
+		AfterID:    last.ID,
+		Sort:       "-price",
+		AfterValue: pgtype.Text{String: last.EffectivePrice.String(), Valid: true},
+		PageLimit:  2,
+	})
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	require.Equal(t, created[2].ID, products[0].ID)
+}
+
+func TestListProductsNameMatchesWildcardsLiterally(t *testing.T) {
+	prefix, err := helpers.GenerateRandomString(10)
+	require.NoError(t, err)
+
+	var created []Product
+	for _, name := range []string{prefix + "_a", prefix + "xa"} {
+		product, err := testQueries.CreateProduct(context.Background(), CreateProductParams{
+			Name:     name,
+			Quantity: 1,
+			Price:    decimal.NewFromInt(10),
+		})
+		require.NoError(t, err)
+		created = append(created, product)
+	}
+
+	// An unescaped _ would match both products.
+	name := pgtype.Text{String: prefix + `\_a`, Valid: true}
+
+	products, err := testQueries.ListProducts(context.Background(), ListProductsParams{Name: name, PageLimit: 10})
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	require.Equal(t, created[0].ID, products[0].ID)
+
+	count, err := testQueries.CountProducts(context.Background(), CountProductsParams{Name: name})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestListProductsAtPriceInForce(t *testing.T) {
+	product := createRandomProduct(t)
+	now := time.Now()
+	sale := createRandomProductPrice(t, product.ID, 1, now.Add(-time.Hour), pgtype.Timestamptz{Time: now.Add(time.Hour), Valid: true})
+	name := pgtype.Text{String: product.Name, Valid: true}
+
+	// While the sale runs the product is filtered and sorted by its sale
+	// price, and is listed at its list price once the sale is over.
+	products, err := testQueries.ListProducts(context.Background(), ListProductsParams{
+		At:        pgtype.Timestamptz{Time: now, Valid: true},
+		Name:      name,
+		MaxPrice:  decimal.NewNullDecimal(sale.Price),
+		Sort:      "price",
+		PageLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	require.True(t, sale.Price.Equal(products[0].EffectivePrice))
+	require.True(t, product.Price.Equal(products[0].Price))
+
+	count, err := testQueries.CountProducts(context.Background(), CountProductsParams{
+		At:       pgtype.Timestamptz{Time: now.Add(2 * time.Hour), Valid: true},
+		Name:     name,
+		MaxPrice: decimal.NewNullDecimal(sale.Price),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestSearchProducts(t *testing.T) {
+	word, err := helpers.GenerateRandomString(12)
+	require.NoError(t, err)
+	category, err := helpers.GenerateRandomString(10)
+	require.NoError(t, err)
+
+	// The word is in the name of the first product and only in the
+	// description of the others, so the first ranks highest.
+	inName, err := testQueries.CreateProduct(context.Background(), CreateProductParams{
+		Name:     word + " lamp",
+		Quantity: 5,
+		Price:    decimal.NewFromInt(30),
+		Category: pgtype.Text{String: category, Valid: true},
+		Tags:     []string{"sale"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"sale"}, inName.Tags)
+
+	soldOut, err := testQueries.CreateProduct(context.Background(), CreateProductParams{
+		Name:        "desk",
+		Quantity:    0,
+		Price:       decimal.NewFromInt(80),
+		Category:    pgtype.Text{String: category, Valid: true},
+		Description: "goes well with a " + word,
+	})
+	require.NoError(t, err)
+	require.Empty(t, soldOut.Tags)
+
+	uncategorized, err := testQueries.CreateProduct(context.Background(), CreateProductParams{
+		Name:        "chair",
+		Quantity:    2,
+		Price:       decimal.NewFromInt(20),
+		Description: "the " + word + " of chairs",
+	})
+	require.NoError(t, err)
+
+	query := pgtype.Text{String: word, Valid: true}
+
+	products, err := testQueries.SearchProducts(context.Background(), SearchProductsParams{Query: query, PageLimit: 10})
+	require.NoError(t, err)
+	require.Len(t, products, 3)
+	require.Equal(t, inName.ID, products[0].ID)
+	require.Greater(t, products[0].Rank, products[1].Rank)
+
+	count, err := testQueries.CountSearchProducts(context.Background(), CountSearchProductsParams{
+		Query:    query,
+		Category: pgtype.Text{String: category, Valid: true},
+		InStock:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	products, err = testQueries.SearchProducts(context.Background(), SearchProductsParams{
+		Query:     query,
+		Tag:       pgtype.Text{String: "sale", Valid: true},
+		PageLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	require.Equal(t, inName.ID, products[0].ID)
+
+	facets, err := testQueries.ListProductCategoryFacets(context.Background(), ListProductCategoryFacetsParams{Query: query})
+	require.NoError(t, err)
+	require.Equal(t, []ListProductCategoryFacetsRow{
+		{Category: pgtype.Text{String: category, Valid: true}, Count: 2},
+		{Count: 1},
+	}, facets)
+
+	// A reservation of all the stock left takes the product out of stock.
+	user := createRandomUser(t)
+	_, err = testQueries.CreateStockReservation(context.Background(), CreateStockReservationParams{
+		ProductID: uncategorized.ID,
+		UserID:    user.ID,
+		Quantity:  2,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	count, err = testQueries.CountSearchProducts(context.Background(), CountSearchProductsParams{Query: query, InStock: true})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestUpdateProduct(t *testing.T) {
+	product := createRandomProduct(t)
+
+	updated, err := testQueries.UpdateProduct(context.Background(), UpdateProductParams{
+		Price:    decimal.NullDecimal{Decimal: decimal.RequireFromString("12.50"), Valid: true},
+		Category: pgtype.Text{String: "books", Valid: true},
+		ID:       product.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, product.Name, updated.Name)
+	require.Equal(t, product.Quantity, updated.Quantity)
+	require.True(t, decimal.RequireFromString("12.50").Equal(updated.Price))
+	require.Equal(t, pgtype.Text{String: "books", Valid: true}, updated.Category)
+
+	// An empty category clears it.
+	updated, err = testQueries.UpdateProduct(context.Background(), UpdateProductParams{
+		Name:     pgtype.Text{String: "renamed", Valid: true},
+		Category: pgtype.Text{String: "", Valid: true},
+		ID:       product.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "renamed", updated.Name)
+	require.False(t, updated.Category.Valid)
+}
+
+func TestArchiveProduct(t *testing.T) {
+	product := createRandomProduct(t)
+
+	archived, err := testQueries.ArchiveProduct(context.Background(), product.ID)
+	require.NoError(t, err)
+	require.True(t, archived.ArchivedAt.Valid)
+
+	_, err = testQueries.ArchiveProduct(context.Background(), product.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// Archived products stay readable but are no longer listed.
+	product, err = testQueries.GetProductByID(context.Background(), product.ID)
+	require.NoError(t, err)
+	require.True(t, product.ArchivedAt.Valid)
+
+	products, err := testQueries.ListProducts(context.Background(), ListProductsParams{
+		Name:      pgtype.Text{String: product.Name, Valid: true},
+		PageLimit: 10,
+	})
+	require.NoError(t, err)
+	for _, p := range products {
+		require.NotEqual(t, product.ID, p.ID)
+	}
+}
@@ -8,31 +8,175 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
 type Querier interface {
 	AddAffiliateBalance(ctx context.Context, arg AddAffiliateBalanceParams) error
+	// Adding a product that is already in the cart increases its quantity.
+	AddCartItem(ctx context.Context, arg AddCartItemParams) (CartItem, error)
+	AddOrderItemRefundedQuantity(ctx context.Context, arg AddOrderItemRefundedQuantityParams) (int64, error)
+	AddProductQuantity(ctx context.Context, arg AddProductQuantityParams) (int64, error)
 	AddUserBalance(ctx context.Context, arg AddUserBalanceParams) (int64, error)
+	ArchiveProduct(ctx context.Context, id pgtype.UUID) (Product, error)
+	CheckOutCart(ctx context.Context, arg CheckOutCartParams) (int64, error)
 	CheckUserExists(ctx context.Context, id pgtype.UUID) (bool, error)
-	CountUsers(ctx context.Context) (int64, error)
+	ConfirmStockReservation(ctx context.Context, arg ConfirmStockReservationParams) (int64, error)
+	CountAccountTransactions(ctx context.Context, arg CountAccountTransactionsParams) (int64, error)
+	CountAffiliates(ctx context.Context, arg CountAffiliatesParams) (int64, error)
+	CountCommissions(ctx context.Context, arg CountCommissionsParams) (int64, error)
+	CountCommissionsByPlanID(ctx context.Context, planID pgtype.UUID) (int64, error)
+	CountInventoryMovements(ctx context.Context, productID pgtype.UUID) (int64, error)
+	CountOrdersByUserID(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CountProducts(ctx context.Context, arg CountProductsParams) (int64, error)
+	CountSearchProducts(ctx context.Context, arg CountSearchProductsParams) (int64, error)
+	CountUsers(ctx context.Context, arg CountUsersParams) (int64, error)
 	CreateAffiliate(ctx context.Context, arg CreateAffiliateParams) (Affiliate, error)
+	CreateAuthSession(ctx context.Context, userID pgtype.UUID) (AuthSession, error)
+	CreateCart(ctx context.Context, userID pgtype.UUID) (Cart, error)
 	CreateCommission(ctx context.Context, arg CreateCommissionParams) (Commission, error)
+	CreateCommissionPlan(ctx context.Context, arg CreateCommissionPlanParams) (CommissionPlan, error)
+	CreateCommissionReversal(ctx context.Context, arg CreateCommissionReversalParams) (Commission, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error)
+	CreateInventoryMovement(ctx context.Context, arg CreateInventoryMovementParams) (InventoryMovement, error)
+	CreateLedgerEntry(ctx context.Context, arg CreateLedgerEntryParams) (LedgerEntry, error)
+	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
+	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (OrderItem, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
+	CreateProductPrice(ctx context.Context, arg CreateProductPriceParams) (ProductPrice, error)
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	CreateStockReservation(ctx context.Context, arg CreateStockReservationParams) (StockReservation, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeductAffiliateBalance(ctx context.Context, arg DeductAffiliateBalanceParams) error
 	DeductProductQuantity(ctx context.Context, arg DeductProductQuantityParams) (int64, error)
 	DeductUserBalance(ctx context.Context, arg DeductUserBalanceParams) (int64, error)
+	DeleteCategoryCommissionOverride(ctx context.Context, category pgtype.Text) (int64, error)
+	DeleteCommissionPlan(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteProductCommissionOverride(ctx context.Context, productID pgtype.UUID) (int64, error)
+	// Only prices that have not come into force yet can be cancelled; the
+	// others are history that orders point to.
+	DeleteProductPrice(ctx context.Context, arg DeleteProductPriceParams) (int64, error)
+	// Run by the sweeper. Forgets the counts whose last failure is before cutoff
+	// and the rows logins created without failing, so the table does not grow
+	// with every username tried.
+	DeleteStaleLoginFailures(ctx context.Context, cutoff pgtype.Timestamptz) (int64, error)
+	DeleteUsernameLoginFailures(ctx context.Context, key string) (int64, error)
+	// Run by the sweeper. Marks every active reservation past its TTL as expired.
+	ExpireStockReservations(ctx context.Context) (int64, error)
 	GetAffiliateByID(ctx context.Context, id pgtype.UUID) (Affiliate, error)
 	GetAffiliateByUserID(ctx context.Context, id pgtype.UUID) (GetAffiliateByUserIDRow, error)
+	GetAuthSessionByID(ctx context.Context, id pgtype.UUID) (AuthSession, error)
+	GetCartByID(ctx context.Context, id pgtype.UUID) (Cart, error)
+	// Locks the cart so two checkouts of the same cart cannot both go through.
+	GetCartByIDForUpdate(ctx context.Context, id pgtype.UUID) (Cart, error)
 	GetCommissionByID(ctx context.Context, id pgtype.UUID) (Commission, error)
 	GetCommissionByOrderID(ctx context.Context, orderID pgtype.UUID) ([]GetCommissionByOrderIDRow, error)
+	GetCommissionOverrideByCategory(ctx context.Context, category pgtype.Text) (CommissionOverride, error)
+	GetCommissionOverrideByProductID(ctx context.Context, productID pgtype.UUID) (CommissionOverride, error)
+	GetCommissionPlanByID(ctx context.Context, id pgtype.UUID) (CommissionPlan, error)
+	GetCommissionPlanInForce(ctx context.Context, at pgtype.Timestamptz) (CommissionPlan, error)
+	GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error)
+	GetLedgerBalance(ctx context.Context, arg GetLedgerBalanceParams) (decimal.Decimal, error)
+	GetOrderByID(ctx context.Context, id pgtype.UUID) (Order, error)
+	// Refunds and cancellations of an order take its lock, so each decides
+	// what is left to refund from the items as the previous one left them.
+	GetOrderByIDForUpdate(ctx context.Context, id pgtype.UUID) (Order, error)
 	GetProductByID(ctx context.Context, id pgtype.UUID) (Product, error)
-	GetTotalCommission(ctx context.Context, orderID pgtype.UUID) (float64, error)
+	// Locks the product row until the end of the transaction so concurrent
+	// orders see each other's stock deductions.
+	GetProductByIDForUpdate(ctx context.Context, id pgtype.UUID) (Product, error)
+	GetProductPriceByID(ctx context.Context, id pgtype.UUID) (ProductPrice, error)
+	// The scheduled price of the product at the given moment.
+	GetProductPriceInForce(ctx context.Context, arg GetProductPriceInForceParams) (ProductPrice, error)
+	// Locks the token so two refreshes with the same token cannot both rotate
+	// it. The session and user come along to issue the next access token.
+	GetRefreshTokenByHashForUpdate(ctx context.Context, tokenHash string) (GetRefreshTokenByHashForUpdateRow, error)
+	// Stock held by active, unexpired reservations of the product. exclude_id
+	// leaves out the reservation that is being consumed.
+	GetReservedProductQuantity(ctx context.Context, arg GetReservedProductQuantityParams) (int32, error)
+	GetStockReservationByID(ctx context.Context, id pgtype.UUID) (StockReservation, error)
+	// Locks the reservation so confirming, releasing and expiring it cannot
+	// overlap.
+	GetStockReservationByIDForUpdate(ctx context.Context, id pgtype.UUID) (StockReservation, error)
+	GetTotalCommission(ctx context.Context, orderID pgtype.UUID) (decimal.Decimal, error)
 	GetUserByUsernameForLogin(ctx context.Context, username string) (GetUserByUsernameForLoginRow, error)
 	GetUserDetailByID(ctx context.Context, id pgtype.UUID) (GetUserDetailByIDRow, error)
-	ListAffiliates(ctx context.Context) ([]Affiliate, error)
-	ListCommissions(ctx context.Context) ([]Commission, error)
-	ListProducts(ctx context.Context) ([]Product, error)
+	// Locks the user row until the end of the transaction so concurrent orders
+	// see each other's balance deductions.
+	GetUserDetailByIDForUpdate(ctx context.Context, id pgtype.UUID) (GetUserDetailByIDForUpdateRow, error)
+	// The running balance is taken over the whole history before filtering, so
+	// every row shows the balance right after it was posted.
+	ListAccountTransactions(ctx context.Context, arg ListAccountTransactionsParams) ([]ListAccountTransactionsRow, error)
+	ListAffiliateBalanceMismatches(ctx context.Context) ([]ListAffiliateBalanceMismatchesRow, error)
+	// Every filter is optional. sort is '', 'name' or 'balance', prefixed with
+	// '-' for descending order; ties and the default order go by ID. With
+	// after_id the list continues after that row, after_value being its sort
+	// value. name matches part of the name, with \, % and _ escaped by a
+	// backslash.
+	ListAffiliates(ctx context.Context, arg ListAffiliatesParams) ([]Affiliate, error)
+	// Ordered by product ID, the order in which checkout locks the products.
+	// unit_price is the scheduled price in force at the given moment, or the
+	// list price.
+	ListCartItems(ctx context.Context, arg ListCartItemsParams) ([]ListCartItemsRow, error)
+	ListCommissionBalancesByOrderID(ctx context.Context, orderID pgtype.UUID) ([]ListCommissionBalancesByOrderIDRow, error)
+	ListCommissionPlans(ctx context.Context) ([]CommissionPlan, error)
+	// Every filter is optional. sort is '', 'created_at' or 'amount', prefixed
+	// with '-' for descending order; ties and the default order go by ID. With
+	// after_id the list continues after that row, after_value being its sort
+	// value.
+	ListCommissions(ctx context.Context, arg ListCommissionsParams) ([]Commission, error)
+	// Products whose quantity differs from the sum of their movements.
+	ListInventoryMismatches(ctx context.Context) ([]ListInventoryMismatchesRow, error)
+	// Newest first. running_quantity is the stock of the product right after the
+	// movement.
+	ListInventoryMovements(ctx context.Context, arg ListInventoryMovementsParams) ([]ListInventoryMovementsRow, error)
+	ListLedgerEntriesByTransactionID(ctx context.Context, transactionID pgtype.UUID) ([]LedgerEntry, error)
+	ListOrderItemsByOrderID(ctx context.Context, orderID pgtype.UUID) ([]ListOrderItemsByOrderIDRow, error)
+	ListOrdersByUserID(ctx context.Context, arg ListOrdersByUserIDParams) ([]Order, error)
+	// Counts the search matches per category, products without a category
+	// under NULL. The category filter is left out so the counts show what
+	// choosing another category would return.
+	ListProductCategoryFacets(ctx context.Context, arg ListProductCategoryFacetsParams) ([]ListProductCategoryFacetsRow, error)
+	ListProductPrices(ctx context.Context, productID pgtype.UUID) ([]ProductPrice, error)
+	// Every filter is optional. sort is '', 'name' or 'price', prefixed with '-'
+	// for descending order; ties and the default order go by ID. With after_id
+	// the list continues after that row, after_value being its sort value. The
+	// price filters and the price sort use effective_price, the price in force
+	// at the given moment. name matches part of the name, with \, % and _
+	// escaped by a backslash.
+	ListProducts(ctx context.Context, arg ListProductsParams) ([]ListProductsRow, error)
+	ListUserBalanceMismatches(ctx context.Context) ([]ListUserBalanceMismatchesRow, error)
+	// Every filter is optional. sort is '', 'username' or 'balance', prefixed
+	// with '-' for descending order; ties and the default order go by ID. With
+	// after_id the list continues after that row, after_value being its sort
+	// value. username matches part of the username, with \, % and _ escaped
+	// by a backslash.
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	// Creates the counts of the username and the client IP when missing and
+	// locks both until the login's transaction ends.
+	LockLoginFailures(ctx context.Context, arg LockLoginFailuresParams) ([]LoginFailure, error)
+	// Counts a failure against both the username and the client IP. Counts
+	// whose last failure is before window_start start over.
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) error
+	ReleaseStockReservation(ctx context.Context, id pgtype.UUID) (StockReservation, error)
+	RemoveCartItem(ctx context.Context, arg RemoveCartItemParams) (int64, error)
+	RevokeAuthSession(ctx context.Context, arg RevokeAuthSessionParams) (int64, error)
+	RevokeUserAuthSessions(ctx context.Context, userID pgtype.UUID) (int64, error)
+	RotateRefreshToken(ctx context.Context, id pgtype.UUID) (int64, error)
+	// Full-text search over the name and the description, best matches first.
+	// Every filter is optional; without a query every product matches with a
+	// rank of 0 and the products are ordered by name. in_stock keeps the
+	// products with stock left after active reservations. The price filters use
+	// effective_price, the price in force at the given moment.
+	SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error)
+	TouchCart(ctx context.Context, id pgtype.UUID) (Cart, error)
+	UpdateCommissionPlan(ctx context.Context, arg UpdateCommissionPlanParams) (CommissionPlan, error)
+	UpdateOrderRefund(ctx context.Context, arg UpdateOrderRefundParams) (Order, error)
+	// An empty category removes the product from its category.
+	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (UpdateUserRoleRow, error)
+	UpsertCategoryCommissionOverride(ctx context.Context, arg UpsertCategoryCommissionOverrideParams) (CommissionOverride, error)
+	UpsertProductCommissionOverride(ctx context.Context, arg UpsertProductCommissionOverrideParams) (CommissionOverride, error)
 	UserBalance(ctx context.Context, id pgtype.UUID) (UserBalanceRow, error)
 }
 
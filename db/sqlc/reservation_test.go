@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomStockReservation(t *testing.T, user User, product Product, quantity int32, ttl time.Duration) StockReservation {
+	reservation, err := testQueries.CreateStockReservation(context.Background(), CreateStockReservationParams{
+		ProductID: product.ID,
+		UserID:    user.ID,
+		Quantity:  quantity,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+	})
+	require.NoError(t, err)
+
+	require.NotZero(t, reservation.ID)
+	require.Equal(t, product.ID, reservation.ProductID)
+	require.Equal(t, user.ID, reservation.UserID)
+	require.Equal(t, quantity, reservation.Quantity)
+	require.Equal(t, "active", reservation.Status)
+	require.False(t, reservation.OrderID.Valid)
+
+	return reservation
+}
+
+func TestCreateStockReservation(t *testing.T) {
+	createRandomStockReservation(t, createRandomUser(t), createRandomProduct(t), 1, time.Minute)
+}
+
+func TestGetReservedProductQuantity(t *testing.T) {
+	user := createRandomUser(t)
+	product := createRandomProduct(t)
+
+	first := createRandomStockReservation(t, user, product, 2, time.Minute)
+	createRandomStockReservation(t, user, product, 3, time.Minute)
+	// Expired reservations no longer hold stock, even before the sweeper runs.
+	createRandomStockReservation(t, user, product, 4, -time.Minute)
+
+	reserved, err := testQueries.GetReservedProductQuantity(context.Background(), GetReservedProductQuantityParams{ProductID: product.ID})
+	require.NoError(t, err)
+	require.Equal(t, int32(5), reserved)
+
+	reserved, err = testQueries.GetReservedProductQuantity(context.Background(), GetReservedProductQuantityParams{
+		ProductID: product.ID,
+		ExcludeID: first.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), reserved)
+
+	_, err = testQueries.ReleaseStockReservation(context.Background(), first.ID)
+	require.NoError(t, err)
+
+	reserved, err = testQueries.GetReservedProductQuantity(context.Background(), GetReservedProductQuantityParams{ProductID: product.ID})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), reserved)
+}
+
+func TestConfirmStockReservation(t *testing.T) {
+	user := createRandomUser(t)
+	product := createRandomProduct(t)
+	reservation := createRandomStockReservation(t, user, product, 1, time.Minute)
+	order := createRandomOrder(t, user, product)
+
+	rows, err := testQueries.ConfirmStockReservation(context.Background(), ConfirmStockReservationParams{OrderID: order.ID, ID: reservation.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	confirmed, err := testQueries.GetStockReservationByID(context.Background(), reservation.ID)
+	require.NoError(t, err)
+	require.Equal(t, "confirmed", confirmed.Status)
+	require.Equal(t, order.ID, confirmed.OrderID)
+
+	// A confirmed reservation can be neither confirmed again nor released.
+	rows, err = testQueries.ConfirmStockReservation(context.Background(), ConfirmStockReservationParams{OrderID: order.ID, ID: reservation.ID})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	_, err = testQueries.ReleaseStockReservation(context.Background(), reservation.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestExpireStockReservations(t *testing.T) {
+	user := createRandomUser(t)
+	product := createRandomProduct(t)
+	active := createRandomStockReservation(t, user, product, 1, time.Minute)
+	overdue := createRandomStockReservation(t, user, product, 1, -time.Minute)
+
+	expired, err := testQueries.ExpireStockReservations(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, expired, int64(1))
+
+	reservation, err := testQueries.GetStockReservationByID(context.Background(), overdue.ID)
+	require.NoError(t, err)
+	require.Equal(t, "expired", reservation.Status)
+
+	reservation, err = testQueries.GetStockReservationByID(context.Background(), active.ID)
+	require.NoError(t, err)
+	require.Equal(t, "active", reservation.Status)
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store gives access to the queries and runs groups of them in a
+// transaction.
+type Store interface {
+	Querier
+	// ExecTx runs fn with queries bound to a new transaction. The transaction
+	// is committed when fn returns nil and rolled back otherwise.
+	ExecTx(ctx context.Context, fn func(Querier) error) error
+}
+
+// SQLStore is the Store backed by a pgx connection pool.
+type SQLStore struct {
+	*Queries
+	pool *pgxpool.Pool
+}
+
+func NewStore(pool *pgxpool.Pool) Store {
+	return &SQLStore{
+		Queries: New(pool),
+		pool:    pool,
+	}
+}
+
+func (s *SQLStore) ExecTx(ctx context.Context, fn func(Querier) error) error {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(s.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
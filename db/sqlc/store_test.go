@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecTx(t *testing.T) {
+	user := createRandomUser(t)
+
+	err := testStore.ExecTx(context.Background(), func(q Querier) error {
+		_, err := q.AddUserBalance(context.Background(), AddUserBalanceParams{
+			Balance: decimal.NewFromInt(10),
+			ID:      user.ID,
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	committed, err := testStore.GetUserDetailByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, user.Balance.Add(decimal.NewFromInt(10)).Equal(committed.Balance))
+
+	failure := errors.New("abort")
+	err = testStore.ExecTx(context.Background(), func(q Querier) error {
+		_, err := q.AddUserBalance(context.Background(), AddUserBalanceParams{
+			Balance: decimal.NewFromInt(5),
+			ID:      user.ID,
+		})
+		require.NoError(t, err)
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	rolledBack, err := testStore.GetUserDetailByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, committed.Balance.Equal(rolledBack.Balance))
+}
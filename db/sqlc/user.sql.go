@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
 const addUserBalance = `-- name: AddUserBalance :execrows
@@ -16,8 +17,8 @@ UPDATE users SET balance = balance + $1 WHERE id = $2
 `
 
 type AddUserBalanceParams struct {
-	Balance float64     `json:"balance"`
-	ID      pgtype.UUID `json:"id"`
+	Balance decimal.Decimal `json:"balance"`
+	ID      pgtype.UUID     `json:"id"`
 }
 
 func (q *Queries) AddUserBalance(ctx context.Context, arg AddUserBalanceParams) (int64, error) {
@@ -40,18 +41,26 @@ func (q *Queries) CheckUserExists(ctx context.Context, id pgtype.UUID) (bool, er
 }
 
 const countUsers = `-- name: CountUsers :one
-SELECT COUNT(*) FROM users
+SELECT COUNT(*)
+FROM users
+WHERE ($1::text IS NULL OR username ILIKE '%' || $1 || '%' ESCAPE '\')
+    AND ($2::uuid IS NULL OR affiliate_id = $2)
 `
 
-func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countUsers)
+type CountUsersParams struct {
+	Username    pgtype.Text `json:"username"`
+	AffiliateID pgtype.UUID `json:"affiliate_id"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.Username, arg.AffiliateID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (username, password, affiliate_id) VALUES ($1, $2, $3) RETURNING id, username, password, balance, affiliate_id
+INSERT INTO users (username, password, affiliate_id) VALUES ($1, $2, $3) RETURNING id, username, password, balance, affiliate_id, role
 `
 
 type CreateUserParams struct {
@@ -69,6 +78,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Password,
 		&i.Balance,
 		&i.AffiliateID,
+		&i.Role,
 	)
 	return i, err
 }
@@ -78,8 +88,8 @@ UPDATE users SET balance = balance - $1 WHERE id = $2 AND balance >= $1
 `
 
 type DeductUserBalanceParams struct {
-	Balance float64     `json:"balance"`
-	ID      pgtype.UUID `json:"id"`
+	Balance decimal.Decimal `json:"balance"`
+	ID      pgtype.UUID     `json:"id"`
 }
 
 func (q *Queries) DeductUserBalance(ctx context.Context, arg DeductUserBalanceParams) (int64, error) {
@@ -91,18 +101,19 @@ func (q *Queries) DeductUserBalance(ctx context.Context, arg DeductUserBalancePa
 }
 
 const getUserByUsernameForLogin = `-- name: GetUserByUsernameForLogin :one
-SELECT id, username, password, affiliate_id, balance
+SELECT id, username, password, affiliate_id, balance, role
 FROM users
 WHERE username = $1
 LIMIT 1
 `
 
 type GetUserByUsernameForLoginRow struct {
-	ID          pgtype.UUID `json:"id"`
-	Username    string      `json:"username"`
-	Password    string      `json:"password"`
-	AffiliateID pgtype.UUID `json:"affiliate_id"`
-	Balance     float64     `json:"balance"`
+	ID          pgtype.UUID     `json:"id"`
+	Username    string          `json:"username"`
+	Password    string          `json:"password"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
+	Balance     decimal.Decimal `json:"balance"`
+	Role        string          `json:"role"`
 }
 
 func (q *Queries) GetUserByUsernameForLogin(ctx context.Context, username string) (GetUserByUsernameForLoginRow, error) {
@@ -114,6 +125,7 @@ func (q *Queries) GetUserByUsernameForLogin(ctx context.Context, username string
 		&i.Password,
 		&i.AffiliateID,
 		&i.Balance,
+		&i.Role,
 	)
 	return i, err
 }
@@ -123,10 +135,10 @@ SELECT id, username, balance, affiliate_id FROM users WHERE id = $1
 `
 
 type GetUserDetailByIDRow struct {
-	ID          pgtype.UUID `json:"id"`
-	Username    string      `json:"username"`
-	Balance     float64     `json:"balance"`
-	AffiliateID pgtype.UUID `json:"affiliate_id"`
+	ID          pgtype.UUID     `json:"id"`
+	Username    string          `json:"username"`
+	Balance     decimal.Decimal `json:"balance"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
 }
 
 func (q *Queries) GetUserDetailByID(ctx context.Context, id pgtype.UUID) (GetUserDetailByIDRow, error) {
@@ -141,27 +153,85 @@ func (q *Queries) GetUserDetailByID(ctx context.Context, id pgtype.UUID) (GetUse
 	return i, err
 }
 
+const getUserDetailByIDForUpdate = `-- name: GetUserDetailByIDForUpdate :one
+SELECT id, username, balance, affiliate_id FROM users WHERE id = $1 FOR UPDATE
+`
+
+type GetUserDetailByIDForUpdateRow struct {
+	ID          pgtype.UUID     `json:"id"`
+	Username    string          `json:"username"`
+	Balance     decimal.Decimal `json:"balance"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
+}
+
+// Locks the user row until the end of the transaction so concurrent orders
+// see each other's balance deductions.
+func (q *Queries) GetUserDetailByIDForUpdate(ctx context.Context, id pgtype.UUID) (GetUserDetailByIDForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getUserDetailByIDForUpdate, id)
+	var i GetUserDetailByIDForUpdateRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Balance,
+		&i.AffiliateID,
+	)
+	return i, err
+}
+
 const listUsers = `-- name: ListUsers :many
 SELECT id, username, balance, affiliate_id
 FROM users
-ORDER BY id
-LIMIT $1 OFFSET $2
+WHERE ($1::text IS NULL OR username ILIKE '%' || $1 || '%' ESCAPE '\')
+    AND ($2::uuid IS NULL OR affiliate_id = $2)
+    AND ($3::uuid IS NULL OR CASE $4::text
+        WHEN 'username' THEN (username, id) > ($5::text, $3)
+        WHEN '-username' THEN (username, id) < ($5::text, $3)
+        WHEN 'balance' THEN (balance, id) > ($5::text::numeric, $3)
+        WHEN '-balance' THEN (balance, id) < ($5::text::numeric, $3)
+        ELSE id > $3
+    END)
+ORDER BY
+    CASE WHEN $4 = 'username' THEN username END,
+    CASE WHEN $4 = '-username' THEN username END DESC,
+    CASE WHEN $4 = 'balance' THEN balance END,
+    CASE WHEN $4 = '-balance' THEN balance END DESC,
+    CASE WHEN $4 LIKE '-%' THEN id END DESC,
+    id
+LIMIT $6 OFFSET $7
 `
 
 type ListUsersParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Username    pgtype.Text `json:"username"`
+	AffiliateID pgtype.UUID `json:"affiliate_id"`
+	AfterID     pgtype.UUID `json:"after_id"`
+	Sort        string      `json:"sort"`
+	AfterValue  pgtype.Text `json:"after_value"`
+	PageLimit   int32       `json:"page_limit"`
+	PageOffset  int32       `json:"page_offset"`
 }
 
 type ListUsersRow struct {
-	ID          pgtype.UUID `json:"id"`
-	Username    string      `json:"username"`
-	Balance     float64     `json:"balance"`
-	AffiliateID pgtype.UUID `json:"affiliate_id"`
+	ID          pgtype.UUID     `json:"id"`
+	Username    string          `json:"username"`
+	Balance     decimal.Decimal `json:"balance"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
 }
 
+// Every filter is optional. sort is ”, 'username' or 'balance', prefixed
+// with '-' for descending order; ties and the default order go by ID. With
+// after_id the list continues after that row, after_value being its sort
+// value. username matches part of the username, with \, % and _ escaped
+// by a backslash.
 func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
-	rows, err := q.db.Query(ctx, listUsers, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Username,
+		arg.AffiliateID,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterValue,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -185,13 +255,36 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUse
 	return items, nil
 }
 
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users SET role = $1 WHERE id = $2
+RETURNING id, username, role
+`
+
+type UpdateUserRoleParams struct {
+	Role string      `json:"role"`
+	ID   pgtype.UUID `json:"id"`
+}
+
+type UpdateUserRoleRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Username string      `json:"username"`
+	Role     string      `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (UpdateUserRoleRow, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.Role, arg.ID)
+	var i UpdateUserRoleRow
+	err := row.Scan(&i.ID, &i.Username, &i.Role)
+	return i, err
+}
+
 const userBalance = `-- name: UserBalance :one
 SELECT id, balance FROM users WHERE id = $1
 `
 
 type UserBalanceRow struct {
-	ID      pgtype.UUID `json:"id"`
-	Balance float64     `json:"balance"`
+	ID      pgtype.UUID     `json:"id"`
+	Balance decimal.Decimal `json:"balance"`
 }
 
 func (q *Queries) UserBalance(ctx context.Context, id pgtype.UUID) (UserBalanceRow, error) {
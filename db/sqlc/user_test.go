@@ -7,6 +7,7 @@ import (
 	"github.com/buranasakS/trading_application/helpers"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,6 +31,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.Username, user.Username)
 	require.Equal(t, arg.Password, user.Password)
 	require.Equal(t, arg.AffiliateID, user.AffiliateID)
+	require.Equal(t, "customer", user.Role)
 
 	require.NotZero(t, user.ID)
 
@@ -45,7 +47,7 @@ func TestAddUserBalance(t *testing.T) {
 
 	arg := AddUserBalanceParams{
 		ID:      user.ID,
-		Balance: 100,
+		Balance: decimal.NewFromInt(100),
 	}
 
 	affectedRows, err := testQueries.AddUserBalance(context.Background(), arg)
@@ -54,13 +56,13 @@ func TestAddUserBalance(t *testing.T) {
 
 	userBalance, err := testQueries.UserBalance(context.Background(), user.ID)
 	require.NoError(t, err)
-	require.Equal(t, arg.Balance, userBalance.Balance)
+	require.True(t, arg.Balance.Equal(userBalance.Balance))
 }
 
 func TestDeductUserBalance(t *testing.T) {
 	user := createRandomUser(t)
 
-	initialBalance := float64(200)
+	initialBalance := decimal.NewFromInt(200)
 	addBalanceArg := AddUserBalanceParams{
 		ID:      user.ID,
 		Balance: initialBalance,
@@ -68,7 +70,7 @@ func TestDeductUserBalance(t *testing.T) {
 	_, err := testQueries.AddUserBalance(context.Background(), addBalanceArg)
 	require.NoError(t, err)
 
-	deductAmount := float64(100)
+	deductAmount := decimal.NewFromInt(100)
 	arg := DeductUserBalanceParams{
 		ID:      user.ID,
 		Balance: deductAmount,
@@ -79,7 +81,7 @@ func TestDeductUserBalance(t *testing.T) {
 
 	userBalance, err := testQueries.UserBalance(context.Background(), user.ID)
 	require.NoError(t, err)
-	require.Equal(t, initialBalance-deductAmount, userBalance.Balance)
+	require.True(t, initialBalance.Sub(deductAmount).Equal(userBalance.Balance))
 
 	arg = DeductUserBalanceParams{
 		ID:      user.ID,
@@ -105,15 +107,21 @@ func TestCheckUserExists(t *testing.T) {
 }
 
 func TestCountUsers(t *testing.T) {
-	initialCount, err := testQueries.CountUsers(context.Background())
+	initialCount, err := testQueries.CountUsers(context.Background(), CountUsersParams{})
 	require.NoError(t, err)
 
-	createRandomUser(t)
+	user := createRandomUser(t)
 	createRandomUser(t)
 
-	newCount, err := testQueries.CountUsers(context.Background())
+	newCount, err := testQueries.CountUsers(context.Background(), CountUsersParams{})
 	require.NoError(t, err)
 	require.Equal(t, initialCount+2, newCount)
+
+	filteredCount, err := testQueries.CountUsers(context.Background(), CountUsersParams{
+		Username: pgtype.Text{String: user.Username, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), filteredCount)
 }
 
 func TestGetUserByUsernameForLogin(t *testing.T) {
@@ -149,8 +157,8 @@ func TestListUsers(t *testing.T) {
 	}
 
 	arg := ListUsersParams{
-		Limit:  5,
-		Offset: 5,
+		PageLimit:  5,
+		PageOffset: 5,
 	}
 
 	users, err := testQueries.ListUsers(context.Background(), arg)
@@ -172,3 +180,19 @@ func TestUserBalance(t *testing.T) {
 	require.Equal(t, user.ID, userBalance.ID)
 	require.Equal(t, user.Balance, userBalance.Balance)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	user := createRandomUser(t)
+
+	updated, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{Role: "support", ID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, updated.ID)
+	require.Equal(t, "support", updated.Role)
+
+	login, err := testQueries.GetUserByUsernameForLogin(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, "support", login.Role)
+
+	_, err = testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{Role: "owner", ID: user.ID})
+	require.Error(t, err)
+}
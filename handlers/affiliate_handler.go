@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -46,23 +47,69 @@ func (h *Handler) CreateAffiliateHandler(c *gin.Context) {
 }
 
 // ListAffiliatesHandler godoc
-// @Summary      List all affiliates
-// @Description  List all affiliates
+// @Summary      List affiliates
+// @Description  List affiliates, filtered and sorted, by page or by cursor
 // @Tags         Affiliates
 // @Security BearerAuth
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  []db.Affiliate "List of affiliates"
+// @Param        limit             query   int     false  "Number of affiliates per page (default 10, at most 100)"
+// @Param        page              query   int     false  "Page number (default 1)"
+// @Param        cursor            query   string  false  "next_cursor of the previous page, instead of page"
+// @Param        sort              query   string  false  "name or balance, prefixed with - for descending order"
+// @Param        name              query   string  false  "Part of the affiliate name"
+// @Param        master_affiliate  query   string  false  "Master affiliate ID (UUID)"
+// @Success      200  {object}  pagination.Page[db.Affiliate] "List of affiliates"
 // @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
 // @Router       /affiliates/list [get]
 func (h *Handler) ListAffiliatesHandler(c *gin.Context) {
-	affiliates, err := h.db.ListAffiliates(context.Background())
+	params, err := pagination.Parse(c.Request.URL.Query(), pagination.Text("name"), pagination.Number("balance"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	masterAffiliate, err := queryUUID(c, "master_affiliate", errInvalidAffiliateID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := queryContains(c, "name")
+
+	affiliates, err := h.db.ListAffiliates(context.Background(), db.ListAffiliatesParams{
+		Name:            name,
+		MasterAffiliate: masterAffiliate,
+		AfterID:         params.AfterID(),
+		Sort:            params.SortKey(),
+		AfterValue:      params.AfterValue(),
+		PageLimit:       params.FetchLimit(),
+		PageOffset:      params.Offset(),
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch affiliates"})
 		return
 	}
 
-	c.JSON(http.StatusOK, affiliates)
+	totalCount, err := h.db.CountAffiliates(context.Background(), db.CountAffiliatesParams{
+		Name:            name,
+		MasterAffiliate: masterAffiliate,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count affiliates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewPage(params, affiliates, totalCount, func(affiliate db.Affiliate) pagination.Cursor {
+		cursor := pagination.Cursor{ID: affiliate.ID}
+		switch params.Sort {
+		case "name":
+			cursor.Value = affiliate.Name
+		case "balance":
+			cursor.Value = affiliate.Balance.String()
+		}
+		return cursor
+	}))
 }
 
 // GetAffiliateByIDHandler godoc
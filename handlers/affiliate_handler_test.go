@@ -11,9 +11,11 @@ import (
 	mockdb "github.com/buranasakS/trading_application/db/mocks"
 	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,7 +45,7 @@ func TestCreateAffiliateHandler(t *testing.T) {
 				ID:              affiliateId,
 				Name:            "Affiliate 1",
 				MasterAffiliate: pgtype.UUID{Valid: false},
-				Balance:         0,
+				Balance:         decimal.NewFromInt(0),
 			},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusCreated,
@@ -95,7 +97,7 @@ func TestCreateAffiliateHandler(t *testing.T) {
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
 			router.POST("/affiliates", func(c *gin.Context) {
-				NewHandler(mockDB).CreateAffiliateHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).CreateAffiliateHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodPost, "/affiliates", bytes.NewBuffer(body))
@@ -146,13 +148,13 @@ func TestListAffiliatesHandler(t *testing.T) {
 					ID:              affiliateId1,
 					Name:            "Affiliate 1",
 					MasterAffiliate: pgtype.UUID{},
-					Balance:         0,
+					Balance:         decimal.NewFromInt(0),
 				},
 				{
 					ID:              affiliate2Id,
 					Name:            "Affiliate 2",
 					MasterAffiliate: pgtype.UUID{},
-					Balance:         10,
+					Balance:         decimal.NewFromInt(10),
 				},
 			},
 			mockReturnErr:  nil,
@@ -180,12 +182,15 @@ func TestListAffiliatesHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockDB.EXPECT().ListAffiliates(gomock.Any()).Return(tt.mockReturnData, tt.mockReturnErr).Times(1)
+			mockDB.EXPECT().ListAffiliates(gomock.Any(), db.ListAffiliatesParams{PageLimit: 11}).Return(tt.mockReturnData, tt.mockReturnErr).Times(1)
+			if tt.mockReturnErr == nil {
+				mockDB.EXPECT().CountAffiliates(gomock.Any(), db.CountAffiliatesParams{}).Return(int64(len(tt.mockReturnData)), nil).Times(1)
+			}
 
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
 			router.GET("/affiliates/list", func(c *gin.Context) {
-				NewHandler(mockDB).ListAffiliatesHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListAffiliatesHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodGet, "/affiliates/list", nil)
@@ -202,10 +207,11 @@ func TestListAffiliatesHandler(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, tt.expectedError, response["error"])
 			} else {
-				var response []db.Affiliate
+				var response pagination.Page[db.Affiliate]
 				err := json.Unmarshal(recorder.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedLength, len(response))
+				require.Equal(t, tt.expectedLength, len(response.Data))
+				require.Equal(t, int32(tt.expectedLength), response.TotalCount)
 			}
 		})
 	}
@@ -223,7 +229,7 @@ func TestGetAffiliateDetailHandler(t *testing.T) {
 		ID:              affiliateId,
 		Name:            "Affiliate 1",
 		MasterAffiliate: pgtype.UUID{},
-		Balance:         0,
+		Balance:         decimal.NewFromInt(0),
 	}
 
 	tests := []struct {
@@ -272,7 +278,7 @@ func TestGetAffiliateDetailHandler(t *testing.T) {
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
 			router.GET("/affiliates/:id", func(c *gin.Context) {
-				NewHandler(mockDB).GetAffiliateDetailHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetAffiliateDetailHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodGet, "/affiliates/"+tt.paramID, nil)
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/buranasakS/trading_application/auth"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// TokenResponse is returned by login and refresh. The access token goes in
+// the Authorization header; the refresh token is exchanged at /auth/refresh
+// for a new pair once the access token expires, and is then no longer valid.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RequestRefreshToken struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// issueTokens stores a new refresh token for the session and signs an access
+// token for it.
+func issueTokens(ctx context.Context, q db.Querier, keys *auth.KeySet, sessionID, userID pgtype.UUID, username, role string, now time.Time) (TokenResponse, error) {
+	refreshToken, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	_, err = q.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		SessionID: sessionID,
+		TokenHash: hash,
+		ExpiresAt: pgtype.Timestamptz{Time: now.Add(auth.RefreshTokenTTL), Valid: true},
+	})
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	accessToken, err := auth.NewAccessToken(keys, userID, sessionID, username, role, now)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	return TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(auth.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshTokenHandler godoc
+// @Summary      Refresh an access token
+// @Description  Exchange a refresh token for a new access token and refresh token. The refresh token can be used once; using it again revokes its session
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  RequestRefreshToken  true  "Refresh token"
+// @Success      200  {object}  TokenResponse
+// @Failure 401 {object} handlers.ErrorResponse "Invalid, expired or reused refresh token"
+// @Router       /auth/refresh [post]
+func (h *Handler) RefreshTokenHandler(c *gin.Context) {
+	var req RequestRefreshToken
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	var tokens TokenResponse
+	reused := false
+
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		token, err := qtx.GetRefreshTokenByHashForUpdate(context.Background(), auth.HashRefreshToken(req.RefreshToken))
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return errResponded
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get refresh token"})
+			return errResponded
+		}
+
+		if token.SessionRevokedAt.Valid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			return errResponded
+		}
+
+		// A rotated token is only presented again if it was stolen, by the
+		// thief or by the client it was stolen from. Revoke the session so
+		// neither can keep using it; the revocation must be committed.
+		if token.RotatedAt.Valid {
+			if _, err := qtx.RevokeAuthSession(context.Background(), db.RevokeAuthSessionParams{
+				ID:     token.SessionID,
+				UserID: token.UserID,
+			}); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+				return errResponded
+			}
+			reused = true
+			return nil
+		}
+
+		if !token.ExpiresAt.Time.After(now) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has expired"})
+			return errResponded
+		}
+
+		if _, err := qtx.RotateRefreshToken(context.Background(), token.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate refresh token"})
+			return errResponded
+		}
+
+		tokens, err = issueTokens(context.Background(), qtx, h.keys, token.SessionID, token.UserID, token.Username, token.Role, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	if reused {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token has already been used, the session has been revoked"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// LogoutHandler godoc
+// @Summary      Log out
+// @Description  Revoke the session of the access token. Its access and refresh tokens stop working immediately
+// @Tags         Auth
+// @Security BearerAuth
+// @Produce      json
+// @Success      200  {object}  map[string]string  "Logged out"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /auth/logout [post]
+func (h *Handler) LogoutHandler(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+
+	_, err := h.db.RevokeAuthSession(context.Background(), db.RevokeAuthSessionParams{
+		ID:     principal.SessionID,
+		UserID: principal.UserID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAllHandler godoc
+// @Summary      Log out of all sessions
+// @Description  Revoke every session of the user, including the current one
+// @Tags         Auth
+// @Security BearerAuth
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "Logged out of all sessions"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /auth/logout/all [post]
+func (h *Handler) LogoutAllHandler(c *gin.Context) {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.db.RevokeUserAuthSessions(context.Background(), principal.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Logged out of all sessions",
+		"revoked_sessions": revoked,
+	})
+}
+
+// JWKSHandler godoc
+// @Summary      Public keys of access tokens
+// @Description  The JSON Web Key Set that access tokens can be verified with, matched by the kid header of a token. Keys being rotated in or out are listed alongside the signing key
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  auth.JWKS
+// @Router       /.well-known/jwks.json [get]
+func (h *Handler) JWKSHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/buranasakS/trading_application/auth"
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshTokenHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	sessionId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	tokenId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+
+	refreshToken := "refresh-token"
+	tokenHash := auth.HashRefreshToken(refreshToken)
+	stored := db.GetRefreshTokenByHashForUpdateRow{
+		ID:        tokenId,
+		SessionID: sessionId,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		UserID:    userId,
+		Username:  "testuser",
+	}
+	rotated := stored
+	rotated.RotatedAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	expired := stored
+	expired.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	revoked := stored
+	revoked.SessionRevokedAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+
+	tests := []struct {
+		name           string
+		reqBody        interface{}
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+		expectCommit   bool
+	}{
+		{
+			name:    "Token rotated",
+			reqBody: RequestRefreshToken{RefreshToken: refreshToken},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetRefreshTokenByHashForUpdate(gomock.Any(), tokenHash).Return(stored, nil).Times(1)
+				mockDB.EXPECT().RotateRefreshToken(gomock.Any(), tokenId).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.CreateRefreshTokenParams) (db.RefreshToken, error) {
+						require.Equal(t, sessionId, arg.SessionID)
+						require.NotEqual(t, tokenHash, arg.TokenHash)
+						require.WithinDuration(t, time.Now().Add(auth.RefreshTokenTTL), arg.ExpiresAt.Time, time.Minute)
+						return db.RefreshToken{SessionID: sessionId, TokenHash: arg.TokenHash}, nil
+					}).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectCommit:   true,
+		},
+		{
+			name:           "Missing token",
+			reqBody:        gin.H{},
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Unknown token",
+			reqBody: RequestRefreshToken{RefreshToken: refreshToken},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetRefreshTokenByHashForUpdate(gomock.Any(), tokenHash).
+					Return(db.GetRefreshTokenByHashForUpdateRow{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Invalid refresh token",
+		},
+		{
+			name:    "Token reused",
+			reqBody: RequestRefreshToken{RefreshToken: refreshToken},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetRefreshTokenByHashForUpdate(gomock.Any(), tokenHash).Return(rotated, nil).Times(1)
+				mockDB.EXPECT().RevokeAuthSession(gomock.Any(), db.RevokeAuthSessionParams{ID: sessionId, UserID: userId}).
+					Return(int64(1), nil).Times(1)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Refresh token has already been used, the session has been revoked",
+			expectCommit:   true,
+		},
+		{
+			name:    "Token expired",
+			reqBody: RequestRefreshToken{RefreshToken: refreshToken},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetRefreshTokenByHashForUpdate(gomock.Any(), tokenHash).Return(expired, nil).Times(1)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Refresh token has expired",
+		},
+		{
+			name:    "Session revoked",
+			reqBody: RequestRefreshToken{RefreshToken: refreshToken},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetRefreshTokenByHashForUpdate(gomock.Any(), tokenHash).Return(revoked, nil).Times(1)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "Session has been revoked",
+		},
+		{
+			name:    "New token cannot be stored",
+			reqBody: RequestRefreshToken{RefreshToken: refreshToken},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetRefreshTokenByHashForUpdate(gomock.Any(), tokenHash).Return(stored, nil).Times(1)
+				mockDB.EXPECT().RotateRefreshToken(gomock.Any(), tokenId).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(db.RefreshToken{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to generate token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+			store := mockdb.NewFakeStore(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/auth/refresh", NewHandler(store, testKeys).RefreshTokenHandler)
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectCommit {
+				require.Equal(t, 1, store.Commits)
+			} else {
+				require.Zero(t, store.Commits)
+			}
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response TokenResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.NotEqual(t, refreshToken, response.RefreshToken)
+			require.Equal(t, "Bearer", response.TokenType)
+
+			claims, err := auth.ParseAccessToken(testKeys, response.AccessToken)
+			require.NoError(t, err)
+			require.Equal(t, sessionId.String(), claims.ID)
+			require.Equal(t, userId.String(), claims.Subject)
+		})
+	}
+}
+
+func TestLogoutHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	sessionId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	caller := &auth.Principal{UserID: userId, SessionID: sessionId, Username: "testuser", Role: auth.RoleCustomer}
+
+	tests := []struct {
+		name           string
+		path           string
+		principal      *auth.Principal
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "Current session revoked",
+			path:      "/auth/logout",
+			principal: caller,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().RevokeAuthSession(gomock.Any(), db.RevokeAuthSessionParams{ID: sessionId, UserID: userId}).
+					Return(int64(1), nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"Logged out"}`,
+		},
+		{
+			name:           "No principal",
+			path:           "/auth/logout",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"Unauthorized"}`,
+		},
+		{
+			name:      "Revoke fails",
+			path:      "/auth/logout",
+			principal: caller,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().RevokeAuthSession(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"Failed to revoke session"}`,
+		},
+		{
+			name:      "All sessions revoked",
+			path:      "/auth/logout/all",
+			principal: caller,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().RevokeUserAuthSessions(gomock.Any(), userId).Return(int64(3), nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"Logged out of all sessions","revoked_sessions":3}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+			h := NewHandler(mockdb.NewFakeStore(mockDB), testKeys)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			if tt.principal != nil {
+				router.Use(withPrincipal(*tt.principal))
+			}
+			router.POST("/auth/logout", h.LogoutHandler)
+			router.POST("/auth/logout/all", h.LogoutAllHandler)
+
+			req, err := http.NewRequest(http.MethodPost, tt.path, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.JSONEq(t, tt.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/.well-known/jwks.json", NewHandler(nil, testKeys).JWKSHandler)
+
+	req, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "public, max-age=300", recorder.Header().Get("Cache-Control"))
+
+	var response auth.JWKS
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Keys, 1)
+	require.Equal(t, testKeys.SigningKeyID(), response.Keys[0].Kid)
+	require.Equal(t, "EdDSA", response.Keys[0].Alg)
+	require.NotEmpty(t, response.Keys[0].X)
+}
@@ -0,0 +1,559 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/inventory"
+	"github.com/buranasakS/trading_application/ledger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	CartStatusOpen       = "open"
+	CartStatusCheckedOut = "checked_out"
+)
+
+// CreateCartRequest names the owner of the cart, the caller when UserID is
+// left out.
+type CreateCartRequest struct {
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+type CartItemRequest struct {
+	ProductID pgtype.UUID `json:"product_id"`
+	Quantity  int32       `json:"quantity"`
+}
+
+type CartItemResponse struct {
+	ProductID   pgtype.UUID     `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	Quantity    int32           `json:"quantity"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	TotalPrice  decimal.Decimal `json:"total_price"`
+}
+
+type CartResponse struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Status    string             `json:"status"`
+	OrderID   pgtype.UUID        `json:"order_id"`
+	TotalCost decimal.Decimal    `json:"total_cost"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	Items     []CartItemResponse `json:"items"`
+}
+
+// CheckoutLineError explains why one line of a cart cannot be checked out.
+type CheckoutLineError struct {
+	ProductID pgtype.UUID `json:"product_id"`
+	Requested int32       `json:"requested"`
+	Available int32       `json:"available"`
+	Error     string      `json:"error"`
+}
+
+type CheckoutErrorResponse struct {
+	Error string              `json:"error"`
+	Lines []CheckoutLineError `json:"lines"`
+}
+
+// CreateCartHandler godoc
+// @Summary      Create a cart
+// @Description  Create an empty cart for a user, the caller by default
+// @Tags         Carts
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body    CreateCartRequest true "Cart owner"
+// @Success      201  {object}   CartResponse
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /carts [post]
+func (h *Handler) CreateCartHandler(c *gin.Context) {
+	var req CreateCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+
+	if !req.UserID.Valid {
+		req.UserID = principal.UserID
+	}
+
+	if !authorizeUser(c, req.UserID) {
+		return
+	}
+
+	if _, err := h.db.GetUserDetailByID(context.Background(), req.UserID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+		return
+	}
+
+	cart, err := h.db.CreateCart(context.Background(), req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create cart"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newCartResponse(cart, nil))
+}
+
+// GetCartHandler godoc
+// @Summary      Get a cart
+// @Description  Get a cart with its items at the current product prices
+// @Tags         Carts
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Cart ID (UUID)"
+// @Success      200  {object}  CartResponse
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /carts/{id} [get]
+func (h *Handler) GetCartHandler(c *gin.Context) {
+	var cartId pgtype.UUID
+	if err := cartId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart ID"})
+		return
+	}
+
+	cart, err := h.db.GetCartByID(context.Background(), cartId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+
+	if !authorizeUser(c, cart.UserID) {
+		return
+	}
+
+	items, err := h.db.ListCartItems(context.Background(), db.ListCartItemsParams{
+		At:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		CartID: cartId,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cart items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCartResponse(cart, items))
+}
+
+// AddCartItemHandler godoc
+// @Summary      Add a product to a cart
+// @Description  Add a product to an open cart. Adding a product already in the cart increases its quantity
+// @Tags         Carts
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path    string           true  "Cart ID (UUID)"
+// @Param        request body    CartItemRequest  true  "Product and quantity"
+// @Success      200  {object}   CartResponse
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /carts/{id}/items [post]
+func (h *Handler) AddCartItemHandler(c *gin.Context) {
+	var cartId pgtype.UUID
+	if err := cartId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart ID"})
+		return
+	}
+
+	var req CartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be more than 0"})
+		return
+	}
+
+	cart, err := h.db.GetCartByID(context.Background(), cartId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+
+	if !authorizeUser(c, cart.UserID) {
+		return
+	}
+
+	if cart.Status != CartStatusOpen {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cart is already checked out"})
+		return
+	}
+
+	product, err := h.db.GetProductByID(context.Background(), req.ProductID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product not found"})
+		return
+	}
+
+	if product.ArchivedAt.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product is no longer available"})
+		return
+	}
+
+	_, err = h.db.AddCartItem(context.Background(), db.AddCartItemParams{
+		CartID:    cartId,
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add cart item"})
+		return
+	}
+
+	h.writeUpdatedCart(c, cart)
+}
+
+// RemoveCartItemHandler godoc
+// @Summary      Remove a product from a cart
+// @Description  Remove a product and its whole quantity from an open cart
+// @Tags         Carts
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id          path  string  true  "Cart ID (UUID)"
+// @Param        product_id  path  string  true  "Product ID (UUID)"
+// @Success      200  {object}  CartResponse
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /carts/{id}/items/{product_id} [delete]
+func (h *Handler) RemoveCartItemHandler(c *gin.Context) {
+	var cartId pgtype.UUID
+	if err := cartId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart ID"})
+		return
+	}
+
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("product_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	cart, err := h.db.GetCartByID(context.Background(), cartId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+
+	if !authorizeUser(c, cart.UserID) {
+		return
+	}
+
+	if cart.Status != CartStatusOpen {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cart is already checked out"})
+		return
+	}
+
+	rows, err := h.db.RemoveCartItem(context.Background(), db.RemoveCartItemParams{
+		CartID:    cartId,
+		ProductID: productId,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove cart item"})
+		return
+	}
+
+	if rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product is not in the cart"})
+		return
+	}
+
+	h.writeUpdatedCart(c, cart)
+}
+
+// CheckoutCartHandler godoc
+// @Summary      Check out a cart
+// @Description  Buy every item of a cart as one order. Stock of every line is deducted, the user is charged the total and commission is distributed once over the whole order. When any line cannot be fulfilled nothing is bought and the failing lines are listed
+// @Tags         Carts
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Cart ID (UUID)"
+// @Success      201  {object}  OrderResponse  "Checkout completed"
+// @Failure      400  {object}  CheckoutErrorResponse  "Some lines cannot be fulfilled"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /carts/{id}/checkout [post]
+func (h *Handler) CheckoutCartHandler(c *gin.Context) {
+	var cartId pgtype.UUID
+	if err := cartId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cart ID"})
+		return
+	}
+
+	var response gin.H
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		cart, err := qtx.GetCartByIDForUpdate(context.Background(), cartId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+			return errResponded
+		}
+
+		if !authorizeUser(c, cart.UserID) {
+			return errResponded
+		}
+
+		if cart.Status != CartStatusOpen {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cart is already checked out"})
+			return errResponded
+		}
+
+		// Every line is priced at the same moment.
+		now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		items, err := qtx.ListCartItems(context.Background(), db.ListCartItemsParams{
+			At:     now,
+			CartID: cartId,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cart items"})
+			return errResponded
+		}
+
+		if len(items) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cart is empty"})
+			return errResponded
+		}
+
+		// Lock the user before the products, and the products in ID order,
+		// like every other write path.
+		user, err := qtx.GetUserDetailByIDForUpdate(context.Background(), cart.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+			return errResponded
+		}
+
+		lines := make([]orderLine, 0, len(items))
+		lineErrors := []CheckoutLineError{}
+		totalPrice := decimal.Zero
+		for _, item := range items {
+			product, err := qtx.GetProductByIDForUpdate(context.Background(), item.ProductID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				lineErrors = append(lineErrors, CheckoutLineError{
+					ProductID: item.ProductID,
+					Requested: item.Quantity,
+					Error:     "Product not found",
+				})
+				continue
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product"})
+				return errResponded
+			}
+
+			if product.ArchivedAt.Valid {
+				lineErrors = append(lineErrors, CheckoutLineError{
+					ProductID: item.ProductID,
+					Requested: item.Quantity,
+					Error:     "Product is no longer available",
+				})
+				continue
+			}
+
+			reserved, err := qtx.GetReservedProductQuantity(context.Background(), db.GetReservedProductQuantityParams{
+				ProductID: item.ProductID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reserved quantity"})
+				return errResponded
+			}
+
+			available := product.Quantity - reserved
+			if available < item.Quantity {
+				lineErrors = append(lineErrors, CheckoutLineError{
+					ProductID: item.ProductID,
+					Requested: item.Quantity,
+					Available: max(available, 0),
+					Error:     "Not enough product in stock",
+				})
+				continue
+			}
+
+			unitPrice, priceId, err := resolveProductPrice(context.Background(), qtx, product, now)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product price"})
+				return errResponded
+			}
+
+			lineTotal := unitPrice.Mul(decimal.NewFromInt32(item.Quantity))
+			lines = append(lines, orderLine{product: product, quantity: item.Quantity, unitPrice: unitPrice, priceID: priceId, total: lineTotal})
+			totalPrice = totalPrice.Add(lineTotal)
+		}
+
+		if len(lineErrors) > 0 {
+			c.JSON(http.StatusBadRequest, CheckoutErrorResponse{
+				Error: "Some items cannot be checked out",
+				Lines: lineErrors,
+			})
+			return errResponded
+		}
+
+		if user.Balance.LessThan(totalPrice) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough balance"})
+			return errResponded
+		}
+
+		result, err := qtx.DeductUserBalance(context.Background(), db.DeductUserBalanceParams{
+			Balance: totalPrice,
+			ID:      user.ID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct balance"})
+			return errResponded
+		}
+
+		if result == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough balance"})
+			return errResponded
+		}
+
+		order, err := qtx.CreateOrder(context.Background(), db.CreateOrderParams{
+			UserID:    user.ID,
+			TotalCost: totalPrice,
+			Status:    OrderStatusCompleted,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+			return errResponded
+		}
+
+		err = ledger.Record(context.Background(), qtx, ledger.Purchase(user.ID, order.ID, totalPrice))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record ledger entry"})
+			return errResponded
+		}
+
+		for _, line := range lines {
+			result, err := qtx.DeductProductQuantity(context.Background(), db.DeductProductQuantityParams{
+				Quantity: line.quantity,
+				ID:       line.product.ID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct product quantity"})
+				return errResponded
+			}
+
+			if result == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough product in stock"})
+				return errResponded
+			}
+
+			_, err = qtx.CreateOrderItem(context.Background(), db.CreateOrderItemParams{
+				OrderID:    order.ID,
+				ProductID:  line.product.ID,
+				Quantity:   line.quantity,
+				UnitPrice:  line.unitPrice,
+				TotalPrice: line.total,
+				ListPrice:  line.product.Price,
+				PriceID:    line.priceID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order item"})
+				return errResponded
+			}
+
+			if err := recordInventory(c, qtx, inventory.Sale(line.product.ID, order.ID, line.quantity)); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record inventory movement"})
+				return errResponded
+			}
+		}
+
+		if err := payOrderCommissions(c, qtx, order, user.AffiliateID, lines); err != nil {
+			return err
+		}
+
+		result, err = qtx.CheckOutCart(context.Background(), db.CheckOutCartParams{
+			OrderID: order.ID,
+			ID:      cartId,
+		})
+		if err != nil || result == 0 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check out cart"})
+			return errResponded
+		}
+
+		response = gin.H{
+			"status":     "success",
+			"message":    "Checkout completed",
+			"order_id":   uuid.UUID(order.ID.Bytes).String(),
+			"total_cost": totalPrice,
+		}
+		if !saveIdempotentResponse(c, qtx, http.StatusCreated, response) {
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// writeUpdatedCart bumps the cart's updated_at and writes it with its items.
+func (h *Handler) writeUpdatedCart(c *gin.Context, cart db.Cart) {
+	cart, err := h.db.TouchCart(context.Background(), cart.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
+		return
+	}
+
+	items, err := h.db.ListCartItems(context.Background(), db.ListCartItemsParams{
+		At:     pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		CartID: cart.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cart items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCartResponse(cart, items))
+}
+
+func newCartResponse(cart db.Cart, items []db.ListCartItemsRow) CartResponse {
+	response := CartResponse{
+		ID:        cart.ID,
+		UserID:    cart.UserID,
+		Status:    cart.Status,
+		OrderID:   cart.OrderID,
+		TotalCost: decimal.Zero,
+		CreatedAt: cart.CreatedAt,
+		UpdatedAt: cart.UpdatedAt,
+		Items:     make([]CartItemResponse, 0, len(items)),
+	}
+
+	for _, item := range items {
+		total := item.UnitPrice.Mul(decimal.NewFromInt32(item.Quantity))
+		response.Items = append(response.Items, CartItemResponse{
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TotalPrice:  total,
+		})
+		response.TotalCost = response.TotalCost.Add(total)
+	}
+
+	return response
+}
@@ -0,0 +1,520 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/inventory"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddCartItemHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cartId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	openCart := db.Cart{ID: cartId, Status: CartStatusOpen}
+	items := []db.ListCartItemsRow{
+		{ProductID: productId, ProductName: "Product", Quantity: 3, UnitPrice: decimal.NewFromInt(10)},
+	}
+
+	tests := []struct {
+		name           string
+		reqBody        CartItemRequest
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:    "Success",
+			reqBody: CartItemRequest{ProductID: productId, Quantity: 3},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByID(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{ID: productId}, nil).Times(1)
+				mockDB.EXPECT().AddCartItem(gomock.Any(), db.AddCartItemParams{
+					CartID:    cartId,
+					ProductID: productId,
+					Quantity:  3,
+				}).Return(db.CartItem{}, nil).Times(1)
+				mockDB.EXPECT().TouchCart(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+				mockDB.EXPECT().ListCartItems(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.ListCartItemsParams) ([]db.ListCartItemsRow, error) {
+						require.Equal(t, cartId, arg.CartID)
+						require.True(t, arg.At.Valid)
+						return items, nil
+					}).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid quantity",
+			reqBody:        CartItemRequest{ProductID: productId, Quantity: 0},
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Quantity must be more than 0",
+		},
+		{
+			name:    "Cart not found",
+			reqBody: CartItemRequest{ProductID: productId, Quantity: 1},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByID(gomock.Any(), cartId).Return(db.Cart{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Cart not found",
+		},
+		{
+			name:    "Cart already checked out",
+			reqBody: CartItemRequest{ProductID: productId, Quantity: 1},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByID(gomock.Any(), cartId).Return(db.Cart{ID: cartId, Status: CartStatusCheckedOut}, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Cart is already checked out",
+		},
+		{
+			name:    "Product not found",
+			reqBody: CartItemRequest{ProductID: productId, Quantity: 1},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByID(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product not found",
+		},
+		{
+			name:    "Archived product",
+			reqBody: CartItemRequest{ProductID: productId, Quantity: 1},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByID(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{
+					ID:         productId,
+					ArchivedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+				}, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product is no longer available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/carts/:id/items", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).AddCartItemHandler)
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/carts/"+cartId.String()+"/items", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response CartResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Len(t, response.Items, 1)
+			require.True(t, decimal.NewFromInt(30).Equal(response.Items[0].TotalPrice))
+			require.True(t, decimal.NewFromInt(30).Equal(response.TotalCost))
+		})
+	}
+}
+
+func TestRemoveCartItemHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cartId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	openCart := db.Cart{ID: cartId, Status: CartStatusOpen}
+	removeParams := db.RemoveCartItemParams{CartID: cartId, ProductID: productId}
+
+	tests := []struct {
+		name           string
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Success",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByID(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+				mockDB.EXPECT().RemoveCartItem(gomock.Any(), removeParams).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().TouchCart(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+				mockDB.EXPECT().ListCartItems(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.ListCartItemsParams) ([]db.ListCartItemsRow, error) {
+						require.Equal(t, cartId, arg.CartID)
+						require.True(t, arg.At.Valid)
+						return []db.ListCartItemsRow{}, nil
+					}).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Product not in cart",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByID(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+				mockDB.EXPECT().RemoveCartItem(gomock.Any(), removeParams).Return(int64(0), nil).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product is not in the cart",
+		},
+		{
+			name: "Cart already checked out",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByID(gomock.Any(), cartId).Return(db.Cart{ID: cartId, Status: CartStatusCheckedOut}, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Cart is already checked out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.DELETE("/carts/:id/items/:product_id", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).RemoveCartItemHandler)
+
+			req, err := http.NewRequest(http.MethodDelete, "/carts/"+cartId.String()+"/items/"+productId.String(), nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestCheckoutCartHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cartId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	affiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	masterAffiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+	productAId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174004")
+	productBId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174005")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174006")
+	planId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174007")
+
+	openCart := db.Cart{ID: cartId, UserID: userId, Status: CartStatusOpen}
+	user := db.GetUserDetailByIDForUpdateRow{ID: userId, Balance: decimal.NewFromInt(1000), AffiliateID: affiliateId}
+	productA := db.Product{ID: productAId, Name: "A", Quantity: 10, Price: decimal.NewFromInt(50)}
+	productB := db.Product{ID: productBId, Name: "B", Quantity: 10, Price: decimal.NewFromInt(100)}
+	items := []db.ListCartItemsRow{
+		{ProductID: productAId, Quantity: 2, UnitPrice: productA.Price},
+		{ProductID: productBId, Quantity: 2, UnitPrice: productB.Price},
+	}
+	affiliates := []db.Affiliate{
+		{ID: affiliateId, MasterAffiliate: masterAffiliateId},
+		{ID: masterAffiliateId},
+	}
+	plan := db.CommissionPlan{ID: planId, Rates: []float64{0.10, 0.05}}
+
+	// expectCheckoutReserved locks the cart, the user and both products, in
+	// that order. reserved is the stock other reservations hold per product.
+	// No scheduled price is in force, the list prices apply.
+	expectCheckoutReserved := func(mockDB *mockdb.MockQuerier, user db.GetUserDetailByIDForUpdateRow, reserved map[pgtype.UUID]int32, products ...db.Product) {
+		mockDB.EXPECT().GetCartByIDForUpdate(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+		var pricedAt pgtype.Timestamptz
+		mockDB.EXPECT().ListCartItems(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, arg db.ListCartItemsParams) ([]db.ListCartItemsRow, error) {
+				require.Equal(t, cartId, arg.CartID)
+				pricedAt = arg.At
+				return items, nil
+			}).Times(1)
+		calls := []*gomock.Call{
+			mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(user, nil).Times(1),
+		}
+		for _, product := range products {
+			calls = append(calls, mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), product.ID).Return(product, nil).Times(1))
+			if !product.ArchivedAt.Valid {
+				calls = append(calls, mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), db.GetReservedProductQuantityParams{ProductID: product.ID}).Return(reserved[product.ID], nil).Times(1))
+			}
+			if !product.ArchivedAt.Valid && product.Quantity-reserved[product.ID] >= 2 {
+				calls = append(calls, mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.GetProductPriceInForceParams) (db.ProductPrice, error) {
+						// The order is priced at the moment the cart was read.
+						require.Equal(t, pricedAt, arg.At)
+						return db.ProductPrice{}, pgx.ErrNoRows
+					}).Times(1))
+			}
+		}
+		gomock.InOrder(calls...)
+	}
+
+	expectCheckout := func(mockDB *mockdb.MockQuerier, user db.GetUserDetailByIDForUpdateRow, productA, productB db.Product) {
+		expectCheckoutReserved(mockDB, user, nil, productA, productB)
+	}
+
+	expectOrder := func(mockDB *mockdb.MockQuerier) {
+		mockDB.EXPECT().DeductUserBalance(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, params db.DeductUserBalanceParams) (int64, error) {
+				require.True(t, decimal.NewFromInt(300).Equal(params.Balance))
+				return 1, nil
+			}).Times(1)
+		mockDB.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(db.Order{ID: orderId, UserID: userId}, nil).Times(1)
+		mockDB.EXPECT().DeductProductQuantity(gomock.Any(), db.DeductProductQuantityParams{Quantity: 2, ID: productAId}).Return(int64(1), nil).Times(1)
+		mockDB.EXPECT().DeductProductQuantity(gomock.Any(), db.DeductProductQuantityParams{Quantity: 2, ID: productBId}).Return(int64(1), nil).Times(1)
+		mockDB.EXPECT().CreateOrderItem(gomock.Any(), gomock.Any()).Return(db.OrderItem{}, nil).Times(2)
+		mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, arg db.CreateInventoryMovementParams) (db.InventoryMovement, error) {
+				require.Equal(t, inventory.KindSale, arg.Kind)
+				require.Equal(t, int32(-2), arg.Quantity)
+				require.Equal(t, orderId, arg.OrderID)
+				return db.InventoryMovement{}, nil
+			}).Times(2)
+		for _, affiliate := range affiliates {
+			mockDB.EXPECT().GetAffiliateByID(gomock.Any(), affiliate.ID).Return(affiliate, nil).Times(1)
+		}
+	}
+
+	tests := []struct {
+		name               string
+		buildStubs         func(mockDB *mockdb.MockQuerier)
+		commitErr          error
+		expectedStatus     int
+		expectedError      string
+		expectedLines      []CheckoutLineError
+		expectedCommission []decimal.Decimal
+		expectedRates      [][]float64
+	}{
+		{
+			name: "Success with one commission over the whole order",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				expectCheckout(mockDB, user, productA, productB)
+				expectOrder(mockDB)
+				mockDB.EXPECT().GetCommissionOverrideByProductID(gomock.Any(), gomock.Any()).Return(db.CommissionOverride{}, pgx.ErrNoRows).Times(2)
+				mockDB.EXPECT().GetCommissionPlanInForce(gomock.Any(), gomock.Any()).Return(plan, nil).Times(2)
+				mockDB.EXPECT().AddAffiliateBalance(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				mockDB.EXPECT().CheckOutCart(gomock.Any(), db.CheckOutCartParams{OrderID: orderId, ID: cartId}).Return(int64(1), nil).Times(1)
+			},
+			expectedStatus:     http.StatusCreated,
+			expectedCommission: []decimal.Decimal{decimal.NewFromInt(15), decimal.NewFromInt(15)},
+			expectedRates:      [][]float64{plan.Rates, plan.Rates},
+		},
+		{
+			name: "Success with lines under different rules",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				expectCheckout(mockDB, user, productA, productB)
+				expectOrder(mockDB)
+				mockDB.EXPECT().GetCommissionOverrideByProductID(gomock.Any(), productAId).Return(db.CommissionOverride{Rates: []float64{0.20, 0.10}}, nil).Times(1)
+				mockDB.EXPECT().GetCommissionOverrideByProductID(gomock.Any(), productBId).Return(db.CommissionOverride{}, pgx.ErrNoRows).Times(1)
+				mockDB.EXPECT().GetCommissionPlanInForce(gomock.Any(), gomock.Any()).Return(plan, nil).Times(1)
+				mockDB.EXPECT().AddAffiliateBalance(gomock.Any(), gomock.Any()).Return(nil).Times(4)
+				mockDB.EXPECT().CheckOutCart(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedCommission: []decimal.Decimal{
+				decimal.NewFromInt(10), decimal.NewFromInt(10),
+				decimal.NewFromInt(10), decimal.NewFromInt(10),
+			},
+			expectedRates: [][]float64{{0.20, 0.10}, {0.20, 0.10}, plan.Rates, plan.Rates},
+		},
+		{
+			name: "Stock short on one line",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				short := productB
+				short.Quantity = 1
+				expectCheckout(mockDB, user, productA, short)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Some items cannot be checked out",
+			expectedLines: []CheckoutLineError{
+				{ProductID: productBId, Requested: 2, Available: 1, Error: "Not enough product in stock"},
+			},
+		},
+		{
+			name: "Stock short on every line",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				shortA := productA
+				shortA.Quantity = 0
+				shortB := productB
+				shortB.Quantity = 1
+				expectCheckout(mockDB, user, shortA, shortB)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Some items cannot be checked out",
+			expectedLines: []CheckoutLineError{
+				{ProductID: productAId, Requested: 2, Available: 0, Error: "Not enough product in stock"},
+				{ProductID: productBId, Requested: 2, Available: 1, Error: "Not enough product in stock"},
+			},
+		},
+		{
+			name: "Stock held by reservations",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				expectCheckoutReserved(mockDB, user, map[pgtype.UUID]int32{productBId: 9}, productA, productB)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Some items cannot be checked out",
+			expectedLines: []CheckoutLineError{
+				{ProductID: productBId, Requested: 2, Available: 1, Error: "Not enough product in stock"},
+			},
+		},
+		{
+			name: "Archived product on one line",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				retired := productA
+				retired.ArchivedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+				expectCheckout(mockDB, user, retired, productB)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Some items cannot be checked out",
+			expectedLines: []CheckoutLineError{
+				{ProductID: productAId, Requested: 2, Error: "Product is no longer available"},
+			},
+		},
+		{
+			name: "Not enough balance",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				poor := user
+				poor.Balance = decimal.NewFromInt(299)
+				expectCheckout(mockDB, poor, productA, productB)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Not enough balance",
+		},
+		{
+			name: "Cart already checked out",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByIDForUpdate(gomock.Any(), cartId).Return(db.Cart{ID: cartId, Status: CartStatusCheckedOut}, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Cart is already checked out",
+		},
+		{
+			name: "Empty cart",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetCartByIDForUpdate(gomock.Any(), cartId).Return(openCart, nil).Times(1)
+				mockDB.EXPECT().ListCartItems(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.ListCartItemsParams) ([]db.ListCartItemsRow, error) {
+						require.Equal(t, cartId, arg.CartID)
+						require.True(t, arg.At.Valid)
+						return []db.ListCartItemsRow{}, nil
+					}).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Cart is empty",
+		},
+		{
+			name: "Commit fails",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				expectCheckout(mockDB, db.GetUserDetailByIDForUpdateRow{ID: userId, Balance: decimal.NewFromInt(1000)}, productA, productB)
+				mockDB.EXPECT().DeductUserBalance(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(db.Order{ID: orderId}, nil).Times(1)
+				mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+				mockDB.EXPECT().DeductProductQuantity(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(2)
+				mockDB.EXPECT().CreateOrderItem(gomock.Any(), gomock.Any()).Return(db.OrderItem{}, nil).Times(2)
+				mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, nil).Times(2)
+				mockDB.EXPECT().CheckOutCart(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+			},
+			commitErr:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to commit transaction",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			var commissions []decimal.Decimal
+			var rates [][]float64
+			if tt.expectedCommission != nil {
+				mockDB.EXPECT().CreateCommission(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, params db.CreateCommissionParams) (db.Commission, error) {
+						require.Equal(t, orderId, params.OrderID)
+						commissions = append(commissions, params.Amount)
+						rates = append(rates, params.Rates)
+						return db.Commission{}, nil
+					}).Times(len(tt.expectedCommission))
+				// One purchase entry and one entry per commission, two postings each.
+				mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2 + 2*len(tt.expectedCommission))
+			}
+
+			store := mockdb.NewFakeStore(mockDB)
+			store.CommitErr = tt.commitErr
+
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/carts/:id/checkout", NewHandler(store, testKeys).CheckoutCartHandler)
+
+			req, err := http.NewRequest(http.MethodPost, "/carts/"+cartId.String()+"/checkout", nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusCreated {
+				require.Equal(t, 1, store.Commits)
+				require.Len(t, commissions, len(tt.expectedCommission))
+				for i, amount := range tt.expectedCommission {
+					require.True(t, amount.Equal(commissions[i]), "commission %d: got %s", i, commissions[i])
+				}
+				require.Equal(t, tt.expectedRates, rates)
+
+				var response OrderResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, decimal.NewFromInt(300).Equal(response.TotalCost))
+				return
+			}
+
+			require.Equal(t, 0, store.Commits)
+
+			var response CheckoutErrorResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, tt.expectedError, response.Error)
+			require.Equal(t, tt.expectedLines, response.Lines)
+		})
+	}
+}
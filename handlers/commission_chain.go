@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/buranasakS/trading_application/commission"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/ledger"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// loadAffiliateChain follows master_affiliate links from the given affiliate
+// up to the top of the tree. The first element is the affiliate closest to
+// the buyer.
+func loadAffiliateChain(ctx context.Context, q db.Querier, affiliateID pgtype.UUID) []db.Affiliate {
+	affiliates := []db.Affiliate{}
+	currentAffiliateID := affiliateID
+
+	for currentAffiliateID.Valid {
+		affiliate, err := q.GetAffiliateByID(ctx, currentAffiliateID)
+		if err != nil || !affiliate.ID.Valid {
+			break
+		}
+		affiliates = append(affiliates, affiliate)
+		currentAffiliateID = affiliate.MasterAffiliate
+	}
+
+	return affiliates
+}
+
+// affiliateChainIDs returns the IDs of a chain loaded by loadAffiliateChain,
+// in the same order, as expected by commission.Calculate.
+func affiliateChainIDs(affiliates []db.Affiliate) []pgtype.UUID {
+	ids := make([]pgtype.UUID, len(affiliates))
+	for i, affiliate := range affiliates {
+		ids[i] = affiliate.ID
+	}
+	return ids
+}
+
+// orderLine is one product of an order with its line total.
+type orderLine struct {
+	product   db.Product
+	quantity  int32
+	unitPrice decimal.Decimal
+	priceID   pgtype.UUID
+	total     decimal.Decimal
+}
+
+// payOrderCommissions distributes the commission of an order over the
+// buyer's affiliate chain in one pass. Lines sharing a commission rule are
+// added up and paid together, so an order whose products all use the same
+// rule gets one commission per affiliate. It writes the error response and
+// returns errResponded when a step fails.
+func payOrderCommissions(c *gin.Context, q db.Querier, order db.Order, affiliateID pgtype.UUID, lines []orderLine) error {
+	if !affiliateID.Valid {
+		return nil
+	}
+
+	affiliates := loadAffiliateChain(context.Background(), q, affiliateID)
+
+	type ruleTotal struct {
+		rule  commissionRule
+		total decimal.Decimal
+	}
+	var totals []ruleTotal
+	for _, line := range lines {
+		rule, err := resolveCommissionRule(context.Background(), q, line.product, order.CreatedAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve commission rule"})
+			return errResponded
+		}
+
+		found := false
+		for i := range totals {
+			if totals[i].rule.Rule == rule.Rule && totals[i].rule.PlanID == rule.PlanID && slices.Equal(totals[i].rule.Rates, rule.Rates) {
+				totals[i].total = totals[i].total.Add(line.total)
+				found = true
+				break
+			}
+		}
+		if !found {
+			totals = append(totals, ruleTotal{rule: rule, total: line.total})
+		}
+	}
+
+	for _, t := range totals {
+		allocations := commission.Calculate(affiliateChainIDs(affiliates), t.total, commission.Plan{Rates: t.rule.Rates})
+		for _, allocation := range allocations {
+			commissionRow, err := q.CreateCommission(context.Background(), db.CreateCommissionParams{
+				OrderID:     order.ID,
+				AffiliateID: allocation.AffiliateID,
+				Amount:      allocation.Amount,
+				PlanID:      t.rule.PlanID,
+				Rule:        pgtype.Text{String: t.rule.Rule, Valid: true},
+				Rates:       t.rule.Rates,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create commission"})
+				return errResponded
+			}
+
+			err = q.AddAffiliateBalance(context.Background(), db.AddAffiliateBalanceParams{
+				ID:      allocation.AffiliateID,
+				Balance: allocation.Amount,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add affiliate balance"})
+				return errResponded
+			}
+
+			err = ledger.Record(context.Background(), q, ledger.Commission(allocation.AffiliateID, order.ID, commissionRow.ID, allocation.Amount))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record ledger entry"})
+				return errResponded
+			}
+		}
+	}
+
+	return nil
+}
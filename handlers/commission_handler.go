@@ -2,42 +2,127 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/buranasakS/trading_application/commission"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/pagination"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
 type CommissionAffiliateDetail struct {
-	AffiliateID   pgtype.UUID `json:"affiliate_id"`
-	AffiliateName string      `json:"affiliate_name"`
-	Commission    float64     `json:"commission"`
+	AffiliateID   pgtype.UUID        `json:"affiliate_id"`
+	AffiliateName string             `json:"affiliate_name"`
+	Commission    decimal.Decimal    `json:"commission"`
+	Type          string             `json:"type"`
+	PlanID        pgtype.UUID        `json:"plan_id"`
+	Rule          pgtype.Text        `json:"rule"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
 }
 
 type CommsisionDistributionResponse struct {
 	OrderID         pgtype.UUID                 `json:"order_id"`
-	TotalCommission float64                     `json:"total_commission"`
+	TotalCommission decimal.Decimal             `json:"total_commission"`
 	Details         []CommissionAffiliateDetail `json:"details"`
 }
 
+type RequestCommissionSimulation struct {
+	UserID      pgtype.UUID     `json:"user_id"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
+	ProductID   pgtype.UUID     `json:"product_id"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+type CommissionSimulationLevel struct {
+	Level         int             `json:"level"`
+	AffiliateID   pgtype.UUID     `json:"affiliate_id"`
+	AffiliateName string          `json:"affiliate_name"`
+	Commission    decimal.Decimal `json:"commission"`
+}
+
+type CommissionSimulationResponse struct {
+	Amount          decimal.Decimal             `json:"amount"`
+	Rule            string                      `json:"rule"`
+	PlanID          pgtype.UUID                 `json:"plan_id"`
+	Rates           []float64                   `json:"rates"`
+	TotalCommission decimal.Decimal             `json:"total_commission"`
+	Levels          []CommissionSimulationLevel `json:"levels"`
+}
+
 // ListCommissionsHandler godoc
-// @Summary      List all commissions
-// @Description  List all commissions
+// @Summary      List commissions
+// @Description  List commissions, filtered and sorted, by page or by cursor
 // @Tags         Commissions
 // @Security BearerAuth
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  []db.Commission "List of commissions"
+// @Param        limit         query   int     false  "Number of commissions per page (default 10, at most 100)"
+// @Param        page          query   int     false  "Page number (default 1)"
+// @Param        cursor        query   string  false  "next_cursor of the previous page, instead of page"
+// @Param        sort          query   string  false  "created_at or amount, prefixed with - for descending order"
+// @Param        affiliate_id  query   string  false  "Affiliate ID (UUID)"
+// @Param        order_id      query   string  false  "Order ID (UUID)"
+// @Success      200  {object}  pagination.Page[db.Commission] "List of commissions"
 // @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
 // @Router       /commissions/list [get]
 func (h *Handler) ListCommissionsHandler(c *gin.Context) {
-	commissions, err := h.db.ListCommissions(context.Background())
+	params, err := pagination.Parse(c.Request.URL.Query(), pagination.Time("created_at"), pagination.Number("amount"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	affiliateId, err := queryUUID(c, "affiliate_id", errInvalidAffiliateID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orderId, err := queryUUID(c, "order_id", errInvalidOrderID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commissions, err := h.db.ListCommissions(context.Background(), db.ListCommissionsParams{
+		AffiliateID: affiliateId,
+		OrderID:     orderId,
+		AfterID:     params.AfterID(),
+		Sort:        params.SortKey(),
+		AfterValue:  params.AfterValue(),
+		PageLimit:   params.FetchLimit(),
+		PageOffset:  params.Offset(),
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch commissions"})
 		return
 	}
 
-	c.JSON(http.StatusOK, commissions)
+	totalCount, err := h.db.CountCommissions(context.Background(), db.CountCommissionsParams{
+		AffiliateID: affiliateId,
+		OrderID:     orderId,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count commissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewPage(params, commissions, totalCount, func(commission db.Commission) pagination.Cursor {
+		cursor := pagination.Cursor{ID: commission.ID}
+		switch params.Sort {
+		case "created_at":
+			cursor.Value = commission.CreatedAt.Time.Format(time.RFC3339Nano)
+		case "amount":
+			cursor.Value = commission.Amount.String()
+		}
+		return cursor
+	}))
 }
 
 // GetCommissionByIDHandler godoc
@@ -107,11 +192,114 @@ func (h *Handler) GetCommissionDistributionHandler(c *gin.Context) {
 			AffiliateID:   commission.ID,
 			AffiliateName: commission.Name,
 			Commission:    commission.Amount,
+			Type:          commission.Type,
+			PlanID:        commission.PlanID,
+			Rule:          commission.Rule,
+			CreatedAt:     commission.CreatedAt,
 		})
 	}
 
-	c.JSON(http.StatusOK, CommsisionDistributionResponse{OrderID: orderId, TotalCommission: float64(totalCommission), Details: commissionAffiliateDetails})
+	c.JSON(http.StatusOK, CommsisionDistributionResponse{OrderID: orderId, TotalCommission: totalCommission, Details: commissionAffiliateDetails})
 }
 
+// SimulateCommissionHandler godoc
+// @Summary      Preview the commission payout for an amount
+// @Description  Run the order commission calculation without writing anything. Give either a user_id, whose affiliate starts the chain, or an affiliate_id to start the chain from directly. With product_id the product and category overrides apply; otherwise the commission plan in force now is used. Level 0 is the top of the chain.
+// @Tags         Commissions
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body RequestCommissionSimulation true "Simulation input"
+// @Success      200 {object} CommissionSimulationResponse "Per-level breakdown"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /commissions/simulate [post]
+func (h *Handler) SimulateCommissionHandler(c *gin.Context) {
+	var req RequestCommissionSimulation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
+	if !req.Amount.IsPositive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be more than 0"})
+		return
+	}
+
+	if !helpers.RoundMoney(req.Amount).Equal(req.Amount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must not have more than 2 decimal places"})
+		return
+	}
+
+	if req.UserID.Valid == req.AffiliateID.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either user_id or affiliate_id"})
+		return
+	}
+
+	affiliateID := req.AffiliateID
+	if req.UserID.Valid {
+		user, err := h.db.GetUserDetailByID(context.Background(), req.UserID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		affiliateID = user.AffiliateID
+	}
 
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	var rule commissionRule
+	if req.ProductID.Valid {
+		product, err := h.db.GetProductByID(context.Background(), req.ProductID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+
+		rule, err = resolveCommissionRule(context.Background(), h.db, product, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve commission rule"})
+			return
+		}
+	} else {
+		plan, err := h.db.GetCommissionPlanInForce(context.Background(), now)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve commission rule"})
+			return
+		}
+		if err == nil {
+			rule = commissionRule{Rule: CommissionRulePlan, Rates: plan.Rates, PlanID: plan.ID}
+		}
+	}
+
+	affiliates := []db.Affiliate{}
+	if affiliateID.Valid {
+		affiliates = loadAffiliateChain(context.Background(), h.db, affiliateID)
+		if len(affiliates) == 0 && req.AffiliateID.Valid {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Affiliate not found"})
+			return
+		}
+	}
+
+	response := CommissionSimulationResponse{
+		Amount: req.Amount,
+		Rule:   rule.Rule,
+		PlanID: rule.PlanID,
+		Rates:  rule.Rates,
+		Levels: []CommissionSimulationLevel{},
+	}
+	names := make(map[pgtype.UUID]string, len(affiliates))
+	for _, affiliate := range affiliates {
+		names[affiliate.ID] = affiliate.Name
+	}
+
+	for _, allocation := range commission.Calculate(affiliateChainIDs(affiliates), req.Amount, commission.Plan{Rates: rule.Rates}) {
+		response.TotalCommission = response.TotalCommission.Add(allocation.Amount)
+		response.Levels = append(response.Levels, CommissionSimulationLevel{
+			Level:         allocation.Level,
+			AffiliateID:   allocation.AffiliateID,
+			AffiliateName: names[allocation.AffiliateID],
+			Commission:    allocation.Amount,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
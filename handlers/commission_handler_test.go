@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -11,9 +12,11 @@ import (
 	mockdb "github.com/buranasakS/trading_application/db/mocks"
 	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,8 +32,8 @@ func TestListCommissionsHandler(t *testing.T) {
 	affiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
 
 	commissions := []db.Commission{
-		{ID: commissionId1, OrderID: orderId, AffiliateID: affiliateId, Amount: 100.0},
-		{ID: commissionId2, OrderID: orderId, AffiliateID: affiliateId, Amount: 150.0},
+		{ID: commissionId1, OrderID: orderId, AffiliateID: affiliateId, Amount: decimal.NewFromInt(100)},
+		{ID: commissionId2, OrderID: orderId, AffiliateID: affiliateId, Amount: decimal.NewFromInt(150)},
 	}
 
 	tests := []struct {
@@ -69,12 +72,15 @@ func TestListCommissionsHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockDB.EXPECT().ListCommissions(gomock.Any()).Return(tt.mockReturnData, tt.mockReturnErr).Times(1)
+			mockDB.EXPECT().ListCommissions(gomock.Any(), db.ListCommissionsParams{PageLimit: 11}).Return(tt.mockReturnData, tt.mockReturnErr).Times(1)
+			if tt.mockReturnErr == nil {
+				mockDB.EXPECT().CountCommissions(gomock.Any(), db.CountCommissionsParams{}).Return(int64(len(tt.mockReturnData)), nil).Times(1)
+			}
 
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
 			router.GET("/commissions/list", func(c *gin.Context) {
-				NewHandler(mockDB).ListCommissionsHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListCommissionsHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodGet, "/commissions/list", nil)
@@ -91,10 +97,11 @@ func TestListCommissionsHandler(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, tt.expectedError, response["error"])
 			} else {
-				var response []db.Commission
+				var response pagination.Page[db.Commission]
 				err := json.Unmarshal(recorder.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.expectedLength, len(response))
+				require.Equal(t, tt.expectedLength, len(response.Data))
+				require.Equal(t, int32(tt.expectedLength), response.TotalCount)
 			}
 		})
 	}
@@ -114,7 +121,7 @@ func TestGetCommissionDetailHandler(t *testing.T) {
 		ID:          commissionId,
 		OrderID:     orderId,
 		AffiliateID: affiliateId,
-		Amount:      100.0,
+		Amount:      decimal.NewFromInt(100),
 	}
 
 	tests := []struct {
@@ -163,7 +170,7 @@ func TestGetCommissionDetailHandler(t *testing.T) {
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
 			router.GET("/commissions/:id", func(c *gin.Context) {
-				NewHandler(mockDB).GetCommissionDetailHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetCommissionDetailHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodGet, "/commissions/"+tt.commissionID, nil)
@@ -199,14 +206,14 @@ func TestGetCommissionDistributionHandler(t *testing.T) {
     affiliateId2 := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174004")
 
     commissions := []db.GetCommissionByOrderIDRow{
-        {ID: masteraffiliateId1, Name: "MasterAffiliate1", Amount: 20.0},
-        {ID: affiliateId2, Name: "Affiliate2", Amount: 5.0},
+        {ID: masteraffiliateId1, Name: "MasterAffiliate1", Amount: decimal.NewFromInt(20)},
+        {ID: affiliateId2, Name: "Affiliate2", Amount: decimal.NewFromInt(5)},
     }
 
     tests := []struct {
         name               string
         orderID            string
-        mockTotal          float64
+        mockTotal          decimal.Decimal
         mockCommissions    []db.GetCommissionByOrderIDRow
         mockTotalErr       error
         mockCommissionsErr error
@@ -216,24 +223,24 @@ func TestGetCommissionDistributionHandler(t *testing.T) {
         {
             name:            "Success",
             orderID:         "123e4567-e89b-12d3-a456-426614174002",
-            mockTotal:       25,
+            mockTotal:       decimal.NewFromInt(25),
             mockCommissions: commissions,
             mockTotalErr:    nil,
             mockCommissionsErr: nil,
             expectedStatus:  http.StatusOK,
             expectedBody: CommsisionDistributionResponse{
                 OrderID:         orderId,
-                TotalCommission: 25.0,
+                TotalCommission: decimal.NewFromInt(25),
                 Details: []CommissionAffiliateDetail{
-                    {AffiliateID: masteraffiliateId1, AffiliateName: "MasterAffiliate1", Commission: 20.0},
-                    {AffiliateID: affiliateId2, AffiliateName: "Affiliate2", Commission: 5.0},
+                    {AffiliateID: masteraffiliateId1, AffiliateName: "MasterAffiliate1", Commission: decimal.NewFromInt(20)},
+                    {AffiliateID: affiliateId2, AffiliateName: "Affiliate2", Commission: decimal.NewFromInt(5)},
                 },
             },
         },
         {
             name:            "Invalid Order ID",
             orderID:         "invalid-uuid",
-            mockTotal:       0.0,
+            mockTotal:       decimal.Zero,
             mockCommissions: nil,
             mockTotalErr:    nil,
             mockCommissionsErr: nil,
@@ -243,7 +250,7 @@ func TestGetCommissionDistributionHandler(t *testing.T) {
         {
             name:            "Get Total Commission Error",
             orderID:         "123e4567-e89b-12d3-a456-426614174002",
-            mockTotal:       0.0,
+            mockTotal:       decimal.Zero,
             mockCommissions: nil,
             mockTotalErr:    sql.ErrNoRows,
             mockCommissionsErr: nil,
@@ -253,7 +260,7 @@ func TestGetCommissionDistributionHandler(t *testing.T) {
         {
             name:            "Get Commissions Error",
             orderID:         "123e4567-e89b-12d3-a456-426614174002",
-            mockTotal:       100,
+            mockTotal:       decimal.NewFromInt(100),
             mockCommissions: nil,
             mockTotalErr:    nil,
             mockCommissionsErr: sql.ErrNoRows,
@@ -279,7 +286,7 @@ func TestGetCommissionDistributionHandler(t *testing.T) {
             gin.SetMode(gin.TestMode)
             router := gin.New()
             router.GET("/commissions/distribution/:order_id", func(c *gin.Context) {
-                NewHandler(mockDB).GetCommissionDistributionHandler(c)
+                NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetCommissionDistributionHandler(c)
             })
 
             req, err := http.NewRequest(http.MethodGet, "/commissions/distribution/"+tt.orderID, nil)
@@ -304,3 +311,122 @@ func TestGetCommissionDistributionHandler(t *testing.T) {
         })
     }
 }
+
+func TestSimulateCommissionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	planId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	top := db.Affiliate{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003"), Name: "top"}
+	direct := db.Affiliate{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174004"), Name: "direct", MasterAffiliate: top.ID}
+	plan := db.CommissionPlan{ID: planId, Rates: []float64{0.20, 0.15, 0.10, 0.05}}
+
+	expectChain := func() {
+		mockDB.EXPECT().GetAffiliateByID(gomock.Any(), direct.ID).Return(direct, nil).Times(1)
+		mockDB.EXPECT().GetAffiliateByID(gomock.Any(), top.ID).Return(top, nil).Times(1)
+	}
+
+	tests := []struct {
+		name           string
+		reqBody        RequestCommissionSimulation
+		setupMocks     func()
+		expectedStatus int
+		expectedRule   string
+		expectedTotal  decimal.Decimal
+		expectedLevels int
+		expectedError  string
+	}{
+		{
+			name:    "From user with plan in force",
+			reqBody: RequestCommissionSimulation{UserID: userId, Amount: decimal.NewFromInt(1000)},
+			setupMocks: func() {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId, AffiliateID: direct.ID}, nil).Times(1)
+				mockDB.EXPECT().GetCommissionPlanInForce(gomock.Any(), gomock.Any()).Return(plan, nil).Times(1)
+				expectChain()
+			},
+			expectedStatus: http.StatusOK,
+			expectedRule:   CommissionRulePlan,
+			expectedTotal:  decimal.NewFromInt(200),
+			expectedLevels: 2,
+		},
+		{
+			name:    "From affiliate with product override",
+			reqBody: RequestCommissionSimulation{AffiliateID: direct.ID, ProductID: productId, Amount: decimal.NewFromInt(1000)},
+			setupMocks: func() {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{ID: productId}, nil).Times(1)
+				mockDB.EXPECT().GetCommissionOverrideByProductID(gomock.Any(), productId).Return(db.CommissionOverride{Rates: []float64{0.10, 0.05}}, nil).Times(1)
+				expectChain()
+			},
+			expectedStatus: http.StatusOK,
+			expectedRule:   CommissionRuleProduct,
+			expectedTotal:  decimal.NewFromInt(100),
+			expectedLevels: 2,
+		},
+		{
+			name:           "Both user and affiliate",
+			reqBody:        RequestCommissionSimulation{UserID: userId, AffiliateID: direct.ID, Amount: decimal.NewFromInt(1000)},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Provide either user_id or affiliate_id",
+		},
+		{
+			name:           "Negative amount",
+			reqBody:        RequestCommissionSimulation{UserID: userId, Amount: decimal.NewFromInt(-5)},
+			setupMocks:     func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Amount must be more than 0",
+		},
+		{
+			name:    "User not found",
+			reqBody: RequestCommissionSimulation{UserID: userId, Amount: decimal.NewFromInt(1000)},
+			setupMocks: func() {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{}, sql.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "User not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/commissions/simulate", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).SimulateCommissionHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodPost, "/commissions/simulate", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response CommissionSimulationResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedRule, response.Rule)
+				require.True(t, tt.expectedTotal.Equal(response.TotalCommission), "got %s", response.TotalCommission)
+				require.Len(t, response.Levels, tt.expectedLevels)
+				require.Equal(t, top.ID, response.Levels[len(response.Levels)-1].AffiliateID)
+				require.Equal(t, 0, response.Levels[len(response.Levels)-1].Level)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
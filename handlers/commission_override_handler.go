@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/buranasakS/trading_application/commission"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	CommissionRuleProduct  = "product"
+	CommissionRuleCategory = "category"
+	CommissionRulePlan     = "plan"
+)
+
+type RequestCommissionOverride struct {
+	Rates []float64 `json:"rates" binding:"required,min=1"`
+	Scope string    `json:"scope" binding:"omitempty,oneof=product category"`
+}
+
+type CommissionRuleResponse struct {
+	ProductID pgtype.UUID `json:"product_id"`
+	Category  pgtype.Text `json:"category"`
+	Rule      string      `json:"rule"`
+	Rates     []float64   `json:"rates"`
+	PlanID    pgtype.UUID `json:"plan_id"`
+}
+
+// commissionRule is the set of level rates that applies to a product, and
+// where they came from.
+type commissionRule struct {
+	Rule   string
+	Rates  []float64
+	PlanID pgtype.UUID
+}
+
+// resolveCommissionRule picks the rates for a product at the given time. A
+// product override wins over a category override, which wins over the plan
+// in force. An empty rule means no commission is paid.
+func resolveCommissionRule(ctx context.Context, q db.Querier, product db.Product, at pgtype.Timestamptz) (commissionRule, error) {
+	override, err := q.GetCommissionOverrideByProductID(ctx, product.ID)
+	if err == nil {
+		return commissionRule{Rule: CommissionRuleProduct, Rates: override.Rates}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return commissionRule{}, err
+	}
+
+	if product.Category.Valid {
+		override, err = q.GetCommissionOverrideByCategory(ctx, product.Category)
+		if err == nil {
+			return commissionRule{Rule: CommissionRuleCategory, Rates: override.Rates}, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return commissionRule{}, err
+		}
+	}
+
+	plan, err := q.GetCommissionPlanInForce(ctx, at)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return commissionRule{}, nil
+		}
+		return commissionRule{}, err
+	}
+
+	return commissionRule{Rule: CommissionRulePlan, Rates: plan.Rates, PlanID: plan.ID}, nil
+}
+
+// GetProductCommissionHandler godoc
+// @Summary      Get the commission rule for a product
+// @Description  Get the level rates currently applied to a product and whether they come from a product override, a category override or the commission plan in force
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Product ID (UUID)"
+// @Success      200  {object}  CommissionRuleResponse
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/commission [get]
+func (h *Handler) GetProductCommissionHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	product, err := h.db.GetProductByID(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	rule, err := resolveCommissionRule(context.Background(), h.db, product, pgtype.Timestamptz{Time: time.Now(), Valid: true})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve commission rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CommissionRuleResponse{
+		ProductID: product.ID,
+		Category:  product.Category,
+		Rule:      rule.Rule,
+		Rates:     rule.Rates,
+		PlanID:    rule.PlanID,
+	})
+}
+
+// SetProductCommissionHandler godoc
+// @Summary      Override commission rates for a product or its category
+// @Description  Set the level rates paid on this product, replacing any existing override. With scope "category" the rates apply to every product in the product's category that has no product override of its own.
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Product ID (UUID)"
+// @Param        request body RequestCommissionOverride true "Override rates"
+// @Success      200  {object}  db.CommissionOverride
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/commission [put]
+func (h *Handler) SetProductCommissionHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req RequestCommissionOverride
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := (commission.Plan{Rates: req.Rates}).Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := h.db.GetProductByID(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var override db.CommissionOverride
+	if req.Scope == CommissionRuleCategory {
+		if !product.Category.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product has no category"})
+			return
+		}
+		override, err = h.db.UpsertCategoryCommissionOverride(context.Background(), db.UpsertCategoryCommissionOverrideParams{
+			Category: product.Category,
+			Rates:    req.Rates,
+		})
+	} else {
+		override, err = h.db.UpsertProductCommissionOverride(context.Background(), db.UpsertProductCommissionOverrideParams{
+			ProductID: product.ID,
+			Rates:     req.Rates,
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save commission override"})
+		return
+	}
+
+	c.JSON(http.StatusOK, override)
+}
+
+// DeleteProductCommissionHandler godoc
+// @Summary      Remove a commission override
+// @Description  Remove the product override, or the override on the product's category with scope=category, so the next rule in line applies again
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path   string  true   "Product ID (UUID)"
+// @Param        scope query  string  false  "product (default) or category"
+// @Success      200  {object}  map[string]string "Commission override removed"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/commission [delete]
+func (h *Handler) DeleteProductCommissionHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	scope := c.DefaultQuery("scope", CommissionRuleProduct)
+	if scope != CommissionRuleProduct && scope != CommissionRuleCategory {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope"})
+		return
+	}
+
+	product, err := h.db.GetProductByID(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var result int64
+	if scope == CommissionRuleCategory {
+		if !product.Category.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product has no category"})
+			return
+		}
+		result, err = h.db.DeleteCategoryCommissionOverride(context.Background(), product.Category)
+	} else {
+		result, err = h.db.DeleteProductCommissionOverride(context.Background(), product.ID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove commission override"})
+		return
+	}
+
+	if result == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Commission override not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Commission override removed"})
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetProductCommissionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	planId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	category := pgtype.Text{String: "electronics", Valid: true}
+
+	tests := []struct {
+		name             string
+		product          db.Product
+		productErr       error
+		productOverride  []float64
+		categoryOverride []float64
+		planErr          error
+		expectedStatus   int
+		expectedRule     string
+		expectedRates    []float64
+		expectedError    string
+	}{
+		{
+			name:            "Product override wins",
+			product:         db.Product{ID: productId, Category: category},
+			productOverride: []float64{0.05},
+			expectedStatus:  http.StatusOK,
+			expectedRule:    CommissionRuleProduct,
+			expectedRates:   []float64{0.05},
+		},
+		{
+			name:             "Category override when product has none",
+			product:          db.Product{ID: productId, Category: category},
+			categoryOverride: []float64{0.30, 0.20},
+			expectedStatus:   http.StatusOK,
+			expectedRule:     CommissionRuleCategory,
+			expectedRates:    []float64{0.30, 0.20},
+		},
+		{
+			name:           "Plan in force without overrides",
+			product:        db.Product{ID: productId, Category: category},
+			expectedStatus: http.StatusOK,
+			expectedRule:   CommissionRulePlan,
+			expectedRates:  []float64{0.20, 0.15, 0.10, 0.05},
+		},
+		{
+			name:           "Uncategorised product skips category lookup",
+			product:        db.Product{ID: productId},
+			expectedStatus: http.StatusOK,
+			expectedRule:   CommissionRulePlan,
+			expectedRates:  []float64{0.20, 0.15, 0.10, 0.05},
+		},
+		{
+			name:           "No plan in force",
+			product:        db.Product{ID: productId},
+			planErr:        pgx.ErrNoRows,
+			expectedStatus: http.StatusOK,
+			expectedRule:   "",
+		},
+		{
+			name:           "Product not found",
+			productErr:     errors.New("no rows in result set"),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(tt.product, tt.productErr).Times(1)
+
+			if tt.productErr == nil {
+				if tt.productOverride != nil {
+					mockDB.EXPECT().GetCommissionOverrideByProductID(gomock.Any(), productId).Return(db.CommissionOverride{Rates: tt.productOverride}, nil).Times(1)
+				} else {
+					mockDB.EXPECT().GetCommissionOverrideByProductID(gomock.Any(), productId).Return(db.CommissionOverride{}, pgx.ErrNoRows).Times(1)
+
+					if tt.product.Category.Valid {
+						if tt.categoryOverride != nil {
+							mockDB.EXPECT().GetCommissionOverrideByCategory(gomock.Any(), category).Return(db.CommissionOverride{Rates: tt.categoryOverride}, nil).Times(1)
+						} else {
+							mockDB.EXPECT().GetCommissionOverrideByCategory(gomock.Any(), category).Return(db.CommissionOverride{}, pgx.ErrNoRows).Times(1)
+						}
+					}
+
+					if tt.categoryOverride == nil {
+						mockDB.EXPECT().GetCommissionPlanInForce(gomock.Any(), gomock.Any()).Return(db.CommissionPlan{
+							ID:    planId,
+							Rates: []float64{0.20, 0.15, 0.10, 0.05},
+						}, tt.planErr).Times(1)
+					}
+				}
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/products/:id/commission", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetProductCommissionHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/products/"+productId.String()+"/commission", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response CommissionRuleResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedRule, response.Rule)
+				require.Equal(t, tt.expectedRates, response.Rates)
+				require.Equal(t, tt.expectedRule == CommissionRulePlan, response.PlanID.Valid)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestSetProductCommissionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	category := pgtype.Text{String: "electronics", Valid: true}
+	rates := []float64{0.10, 0.05}
+
+	tests := []struct {
+		name           string
+		reqBody        RequestCommissionOverride
+		product        db.Product
+		expectProduct  bool
+		expectUpsert   string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Product override",
+			reqBody:        RequestCommissionOverride{Rates: rates},
+			product:        db.Product{ID: productId},
+			expectProduct:  true,
+			expectUpsert:   CommissionRuleProduct,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Category override",
+			reqBody:        RequestCommissionOverride{Rates: rates, Scope: CommissionRuleCategory},
+			product:        db.Product{ID: productId, Category: category},
+			expectProduct:  true,
+			expectUpsert:   CommissionRuleCategory,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Category override without category",
+			reqBody:        RequestCommissionOverride{Rates: rates, Scope: CommissionRuleCategory},
+			product:        db.Product{ID: productId},
+			expectProduct:  true,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product has no category",
+		},
+		{
+			name:           "Invalid scope",
+			reqBody:        RequestCommissionOverride{Rates: rates, Scope: "brand"},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Key: 'RequestCommissionOverride.Scope' Error:Field validation for 'Scope' failed on the 'oneof' tag",
+		},
+		{
+			name:           "Increasing rates",
+			reqBody:        RequestCommissionOverride{Rates: []float64{0.05, 0.10}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Commission rates must not increase with level",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectProduct {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(tt.product, nil).Times(1)
+			}
+			switch tt.expectUpsert {
+			case CommissionRuleProduct:
+				mockDB.EXPECT().UpsertProductCommissionOverride(gomock.Any(), db.UpsertProductCommissionOverrideParams{
+					ProductID: productId,
+					Rates:     rates,
+				}).Return(db.CommissionOverride{ProductID: productId, Rates: rates}, nil).Times(1)
+			case CommissionRuleCategory:
+				mockDB.EXPECT().UpsertCategoryCommissionOverride(gomock.Any(), db.UpsertCategoryCommissionOverrideParams{
+					Category: category,
+					Rates:    rates,
+				}).Return(db.CommissionOverride{Category: category, Rates: rates}, nil).Times(1)
+			}
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.PUT("/products/:id/commission", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).SetProductCommissionHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodPut, "/products/"+productId.String()+"/commission", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response db.CommissionOverride
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, rates, response.Rates)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestDeleteProductCommissionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	category := pgtype.Text{String: "electronics", Valid: true}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockRows       int64
+		expectDelete   string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "Remove product override", mockRows: 1, expectDelete: CommissionRuleProduct, expectedStatus: http.StatusOK},
+		{name: "Remove category override", query: "?scope=category", mockRows: 1, expectDelete: CommissionRuleCategory, expectedStatus: http.StatusOK},
+		{name: "No override", expectDelete: CommissionRuleProduct, expectedStatus: http.StatusNotFound, expectedError: "Commission override not found"},
+		{name: "Invalid scope", query: "?scope=brand", expectedStatus: http.StatusBadRequest, expectedError: "Invalid scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			switch tt.expectDelete {
+			case CommissionRuleProduct:
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{ID: productId, Category: category}, nil).Times(1)
+				mockDB.EXPECT().DeleteProductCommissionOverride(gomock.Any(), productId).Return(tt.mockRows, nil).Times(1)
+			case CommissionRuleCategory:
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{ID: productId, Category: category}, nil).Times(1)
+				mockDB.EXPECT().DeleteCategoryCommissionOverride(gomock.Any(), category).Return(tt.mockRows, nil).Times(1)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.DELETE("/products/:id/commission", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).DeleteProductCommissionHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodDelete, "/products/"+productId.String()+"/commission"+tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			var response map[string]string
+			err = json.Unmarshal(recorder.Body.Bytes(), &response)
+			require.NoError(t, err)
+			if tt.expectedError != "" {
+				require.Equal(t, tt.expectedError, response["error"])
+			} else {
+				require.Equal(t, "Commission override removed", response["message"])
+			}
+		})
+	}
+}
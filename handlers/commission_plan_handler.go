@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/buranasakS/trading_application/commission"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type RequestCommissionPlan struct {
+	Name          string     `json:"name" binding:"required"`
+	Rates         []float64  `json:"rates" binding:"required,min=1"`
+	EffectiveFrom time.Time  `json:"effective_from" binding:"required"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+}
+
+type RequestUpdateCommissionPlan struct {
+	Name        *string    `json:"name"`
+	EffectiveTo *time.Time `json:"effective_to"`
+}
+
+// CreateCommissionPlanHandler godoc
+// @Summary      Create a commission plan
+// @Description  Create a commission plan with per-level rates and an effective window. When windows overlap, the plan with the latest effective_from is in force.
+// @Tags         Commission Plans
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body   RequestCommissionPlan true "Commission plan details"
+// @Success      201  {object}  db.CommissionPlan "Commission plan created successfully"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /commission-plans [post]
+func (h *Handler) CreateCommissionPlanHandler(c *gin.Context) {
+	var req RequestCommissionPlan
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := (commission.Plan{Rates: req.Rates}).Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	effectiveTo := pgtype.Timestamptz{}
+	if req.EffectiveTo != nil {
+		if !req.EffectiveTo.After(req.EffectiveFrom) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_to must be after effective_from"})
+			return
+		}
+		effectiveTo = pgtype.Timestamptz{Time: *req.EffectiveTo, Valid: true}
+	}
+
+	plan, err := h.db.CreateCommissionPlan(context.Background(), db.CreateCommissionPlanParams{
+		Name:          req.Name,
+		Rates:         req.Rates,
+		EffectiveFrom: pgtype.Timestamptz{Time: req.EffectiveFrom, Valid: true},
+		EffectiveTo:   effectiveTo,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create commission plan"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// ListCommissionPlansHandler godoc
+// @Summary      List all commission plans
+// @Description  List all commission plans, latest effective first
+// @Tags         Commission Plans
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  []db.CommissionPlan "List of commission plans"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /commission-plans/list [get]
+func (h *Handler) ListCommissionPlansHandler(c *gin.Context) {
+	plans, err := h.db.ListCommissionPlans(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch commission plans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// GetCommissionPlanDetailHandler godoc
+// @Summary      Get commission plan by ID
+// @Description  Get commission plan by ID
+// @Tags         Commission Plans
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Commission plan ID"
+// @Success      200 {object} db.CommissionPlan "Commission plan details"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /commission-plans/{id} [get]
+func (h *Handler) GetCommissionPlanDetailHandler(c *gin.Context) {
+	var planId pgtype.UUID
+	if err := planId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid commission plan ID"})
+		return
+	}
+
+	plan, err := h.db.GetCommissionPlanByID(context.Background(), planId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Commission plan not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// UpdateCommissionPlanHandler godoc
+// @Summary      Update a commission plan
+// @Description  Rename a commission plan or close its effective window. Rates cannot be changed so that historical orders keep the rates they were paid with; create a new plan instead.
+// @Tags         Commission Plans
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Commission plan ID"
+// @Param        request body RequestUpdateCommissionPlan true "Fields to update"
+// @Success      200 {object} db.CommissionPlan "Commission plan updated"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /commission-plans/{id} [patch]
+func (h *Handler) UpdateCommissionPlanHandler(c *gin.Context) {
+	var planId pgtype.UUID
+	if err := planId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid commission plan ID"})
+		return
+	}
+
+	var req RequestUpdateCommissionPlan
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.db.GetCommissionPlanByID(context.Background(), planId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Commission plan not found"})
+		return
+	}
+
+	arg := db.UpdateCommissionPlanParams{ID: planId}
+	if req.Name != nil {
+		if *req.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing name"})
+			return
+		}
+		arg.Name = pgtype.Text{String: *req.Name, Valid: true}
+	}
+	if req.EffectiveTo != nil {
+		if !req.EffectiveTo.After(plan.EffectiveFrom.Time) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_to must be after effective_from"})
+			return
+		}
+		arg.EffectiveTo = pgtype.Timestamptz{Time: *req.EffectiveTo, Valid: true}
+	}
+
+	plan, err = h.db.UpdateCommissionPlan(context.Background(), arg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update commission plan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// DeleteCommissionPlanHandler godoc
+// @Summary      Delete a commission plan
+// @Description  Delete a commission plan that has never been used to pay a commission
+// @Tags         Commission Plans
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Commission plan ID"
+// @Success      200 {object} map[string]string "Commission plan deleted"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /commission-plans/{id} [delete]
+func (h *Handler) DeleteCommissionPlanHandler(c *gin.Context) {
+	var planId pgtype.UUID
+	if err := planId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid commission plan ID"})
+		return
+	}
+
+	used, err := h.db.CountCommissionsByPlanID(context.Background(), planId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check commission plan usage"})
+		return
+	}
+
+	if used > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Commission plan is used by existing commissions"})
+		return
+	}
+
+	result, err := h.db.DeleteCommissionPlan(context.Background(), planId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete commission plan"})
+		return
+	}
+
+	if result == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Commission plan not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Commission plan deleted"})
+}
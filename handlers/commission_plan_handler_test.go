@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateCommissionPlanHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	planId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	effectiveFrom := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	effectiveTo := effectiveFrom.AddDate(1, 0, 0)
+	beforeFrom := effectiveFrom.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name           string
+		reqBody        interface{}
+		mockReturnErr  error
+		expectCreate   bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Success Commission Plan Created",
+			reqBody: RequestCommissionPlan{
+				Name:          "2026",
+				Rates:         []float64{0.25, 0.15},
+				EffectiveFrom: effectiveFrom,
+				EffectiveTo:   &effectiveTo,
+			},
+			expectCreate:   true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Missing rates",
+			reqBody:        RequestCommissionPlan{Name: "2026", EffectiveFrom: effectiveFrom},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Key: 'RequestCommissionPlan.Rates' Error:Field validation for 'Rates' failed on the 'required' tag",
+		},
+		{
+			name:           "Increasing rates",
+			reqBody:        RequestCommissionPlan{Name: "2026", Rates: []float64{0.05, 0.10}, EffectiveFrom: effectiveFrom},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Commission rates must not increase with level",
+		},
+		{
+			name: "Effective window ends before it starts",
+			reqBody: RequestCommissionPlan{
+				Name:          "2026",
+				Rates:         []float64{0.25},
+				EffectiveFrom: effectiveFrom,
+				EffectiveTo:   &beforeFrom,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "effective_to must be after effective_from",
+		},
+		{
+			name:           "Database error",
+			reqBody:        RequestCommissionPlan{Name: "2026", Rates: []float64{0.25}, EffectiveFrom: effectiveFrom},
+			mockReturnErr:  errors.New("db error"),
+			expectCreate:   true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to create commission plan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectCreate {
+				req := tt.reqBody.(RequestCommissionPlan)
+				effectiveTo := pgtype.Timestamptz{}
+				if req.EffectiveTo != nil {
+					effectiveTo = pgtype.Timestamptz{Time: *req.EffectiveTo, Valid: true}
+				}
+				mockDB.EXPECT().CreateCommissionPlan(gomock.Any(), db.CreateCommissionPlanParams{
+					Name:          req.Name,
+					Rates:         req.Rates,
+					EffectiveFrom: pgtype.Timestamptz{Time: req.EffectiveFrom, Valid: true},
+					EffectiveTo:   effectiveTo,
+				}).Return(db.CommissionPlan{ID: planId, Name: req.Name, Rates: req.Rates}, tt.mockReturnErr).Times(1)
+			}
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/commission-plans", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).CreateCommissionPlanHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodPost, "/commission-plans", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusCreated {
+				var response db.CommissionPlan
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, planId, response.ID)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestListCommissionPlansHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	plans := []db.CommissionPlan{
+		{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000"), Name: "2026", Rates: []float64{0.25}},
+		{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001"), Name: "Default", Rates: []float64{0.20, 0.15}},
+	}
+
+	mockDB.EXPECT().ListCommissionPlans(gomock.Any()).Return(plans, nil).Times(1)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/commission-plans/list", func(c *gin.Context) {
+		NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListCommissionPlansHandler(c)
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "/commission-plans/list", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response []db.CommissionPlan
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Equal(t, plans, response)
+}
+
+func TestGetCommissionPlanDetailHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	planId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	plan := db.CommissionPlan{ID: planId, Name: "Default", Rates: []float64{0.20, 0.15}}
+
+	tests := []struct {
+		name           string
+		paramID        string
+		mockReturnErr  error
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "Success", paramID: planId.String(), expectedStatus: http.StatusOK},
+		{name: "Not Found", paramID: planId.String(), mockReturnErr: errors.New("no rows in result set"), expectedStatus: http.StatusNotFound, expectedError: "Commission plan not found"},
+		{name: "Invalid ID", paramID: "invalid-uuid", expectedStatus: http.StatusBadRequest, expectedError: "Invalid commission plan ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectedStatus != http.StatusBadRequest {
+				mockDB.EXPECT().GetCommissionPlanByID(gomock.Any(), planId).Return(plan, tt.mockReturnErr).Times(1)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/commission-plans/:id", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetCommissionPlanDetailHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/commission-plans/"+tt.paramID, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response db.CommissionPlan
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, plan, response)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestUpdateCommissionPlanHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	planId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	effectiveFrom := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	plan := db.CommissionPlan{
+		ID:            planId,
+		Name:          "Default",
+		Rates:         []float64{0.20, 0.15},
+		EffectiveFrom: pgtype.Timestamptz{Time: effectiveFrom, Valid: true},
+	}
+	newName := "Renamed"
+	emptyName := ""
+	closeAt := effectiveFrom.AddDate(0, 6, 0)
+	tooEarly := effectiveFrom.AddDate(0, -1, 0)
+
+	tests := []struct {
+		name           string
+		paramID        string
+		reqBody        RequestUpdateCommissionPlan
+		mockGetErr     error
+		expectUpdate   bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Success rename and close",
+			paramID:        planId.String(),
+			reqBody:        RequestUpdateCommissionPlan{Name: &newName, EffectiveTo: &closeAt},
+			expectUpdate:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid ID",
+			paramID:        "invalid-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid commission plan ID",
+		},
+		{
+			name:           "Not Found",
+			paramID:        planId.String(),
+			reqBody:        RequestUpdateCommissionPlan{Name: &newName},
+			mockGetErr:     errors.New("no rows in result set"),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Commission plan not found",
+		},
+		{
+			name:           "Empty name",
+			paramID:        planId.String(),
+			reqBody:        RequestUpdateCommissionPlan{Name: &emptyName},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Missing name",
+		},
+		{
+			name:           "Close before start",
+			paramID:        planId.String(),
+			reqBody:        RequestUpdateCommissionPlan{EffectiveTo: &tooEarly},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "effective_to must be after effective_from",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.paramID == planId.String() {
+				mockDB.EXPECT().GetCommissionPlanByID(gomock.Any(), planId).Return(plan, tt.mockGetErr).Times(1)
+			}
+			if tt.expectUpdate {
+				mockDB.EXPECT().UpdateCommissionPlan(gomock.Any(), db.UpdateCommissionPlanParams{
+					Name:        pgtype.Text{String: newName, Valid: true},
+					EffectiveTo: pgtype.Timestamptz{Time: closeAt, Valid: true},
+					ID:          planId,
+				}).Return(db.CommissionPlan{ID: planId, Name: newName}, nil).Times(1)
+			}
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.PATCH("/commission-plans/:id", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).UpdateCommissionPlanHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodPatch, "/commission-plans/"+tt.paramID, bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response db.CommissionPlan
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, newName, response.Name)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestDeleteCommissionPlanHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	planId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name           string
+		paramID        string
+		mockUsed       int64
+		mockDeleteRows int64
+		expectDelete   bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "Success", paramID: planId.String(), mockDeleteRows: 1, expectDelete: true, expectedStatus: http.StatusOK},
+		{name: "Plan in use", paramID: planId.String(), mockUsed: 3, expectedStatus: http.StatusConflict, expectedError: "Commission plan is used by existing commissions"},
+		{name: "Not Found", paramID: planId.String(), expectDelete: true, expectedStatus: http.StatusNotFound, expectedError: "Commission plan not found"},
+		{name: "Invalid ID", paramID: "invalid-uuid", expectedStatus: http.StatusBadRequest, expectedError: "Invalid commission plan ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.paramID == planId.String() {
+				mockDB.EXPECT().CountCommissionsByPlanID(gomock.Any(), planId).Return(tt.mockUsed, nil).Times(1)
+			}
+			if tt.expectDelete {
+				mockDB.EXPECT().DeleteCommissionPlan(gomock.Any(), planId).Return(tt.mockDeleteRows, nil).Times(1)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.DELETE("/commission-plans/:id", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).DeleteCommissionPlanHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodDelete, "/commission-plans/"+tt.paramID, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			var response map[string]string
+			err = json.Unmarshal(recorder.Body.Bytes(), &response)
+			require.NoError(t, err)
+			if tt.expectedError != "" {
+				require.Equal(t, tt.expectedError, response["error"])
+			} else {
+				require.Equal(t, "Commission plan deleted", response["message"])
+			}
+		})
+	}
+}
@@ -1,18 +1,26 @@
 package handlers
 
 import (
+	"errors"
+
+	"github.com/buranasakS/trading_application/auth"
 	db "github.com/buranasakS/trading_application/db/sqlc"
 )
 
 type Handler struct {
-	db db.Querier
+	db   db.Store
+	keys *auth.KeySet
 }
 
-func NewHandler(db db.Querier) *Handler {
-	return &Handler{db: db}
+func NewHandler(db db.Store, keys *auth.KeySet) *Handler {
+	return &Handler{db: db, keys: keys}
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// errResponded is returned from an ExecTx callback that has already written
+// the error response, so the transaction is rolled back without replying
+// twice.
+var errResponded = errors.New("response already written")
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// saveIdempotentResponse stores response under the request's Idempotency-Key
+// on the transaction q. It writes the error response and returns false when
+// the caller must abort and roll back.
+func saveIdempotentResponse(c *gin.Context, q db.Querier, status int, response any) bool {
+	err := middleware.SaveIdempotentResponse(context.Background(), q, c, status, response)
+	if errors.Is(err, middleware.ErrIdempotencyKeyUsed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key was already processed"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save idempotency key"})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/buranasakS/trading_application/auth"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/inventory"
+	"github.com/buranasakS/trading_application/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type RequestInventoryAdjustment struct {
+	Quantity int32  `json:"quantity" binding:"required"`
+	Reason   string `json:"reason" binding:"required,max=255"`
+}
+
+type ResponseInventoryMovements struct {
+	ProductID  pgtype.UUID                    `json:"product_id"`
+	Quantity   int32                          `json:"quantity"`
+	Page       int32                          `json:"page"`
+	TotalPage  int32                          `json:"total_page"`
+	Count      int32                          `json:"count"`
+	TotalCount int32                          `json:"total_count"`
+	Data       []db.ListInventoryMovementsRow `json:"data"`
+}
+
+type InventoryReconcileResponse struct {
+	Balanced bool                            `json:"balanced"`
+	Products []db.ListInventoryMismatchesRow `json:"products"`
+}
+
+// ListProductInventoryHandler godoc
+// @Summary      List inventory movements of a product
+// @Description  Fetch a paginated list of stock changes of a product, newest first, with the stock after each change
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path    string  true   "Product ID (UUID)"
+// @Param        limit  query   int     false  "Number of movements per page (default 10)"
+// @Param        page   query   int     false  "Page number (default 1)"
+// @Success      200  {object}  ResponseInventoryMovements
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/inventory [get]
+func (h *Handler) ListProductInventoryHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	params, err := pagination.ParseOffset(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := h.db.GetProductByID(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	movements, err := h.db.ListInventoryMovements(context.Background(), db.ListInventoryMovementsParams{
+		ProductID:  productId,
+		PageLimit:  params.Limit,
+		PageOffset: params.Offset(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inventory movements"})
+		return
+	}
+
+	totalCount, err := h.db.CountInventoryMovements(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count inventory movements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseInventoryMovements{
+		ProductID:  product.ID,
+		Quantity:   product.Quantity,
+		Page:       params.Page,
+		TotalPage:  pagination.TotalPages(totalCount, params.Limit),
+		Count:      int32(len(movements)),
+		TotalCount: int32(totalCount),
+		Data:       movements,
+	})
+}
+
+// AdjustProductInventoryHandler godoc
+// @Summary      Adjust the stock of a product
+// @Description  Correct the stock of a product by a signed quantity, e.g. after a stock count, and record why. Stock held by active reservations cannot be removed
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string                      true  "Product ID (UUID)"
+// @Param        request  body  RequestInventoryAdjustment  true  "Quantity to add, negative to remove, and the reason"
+// @Success      200  {object}  db.Product
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/inventory/adjustments [post]
+func (h *Handler) AdjustProductInventoryHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req RequestInventoryAdjustment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason must not be empty"})
+		return
+	}
+
+	var product db.Product
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		var err error
+		product, err = qtx.GetProductByIDForUpdate(context.Background(), productId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return errResponded
+		}
+
+		if product.Quantity+req.Quantity < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Adjustment would make the stock negative"})
+			return errResponded
+		}
+
+		// Stock held by active reservations cannot be taken away; restocking
+		// is always allowed.
+		if req.Quantity < 0 {
+			reserved, err := qtx.GetReservedProductQuantity(context.Background(), db.GetReservedProductQuantityParams{
+				ProductID: productId,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reserved quantity"})
+				return errResponded
+			}
+
+			if product.Quantity+req.Quantity < reserved {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Adjustment would remove reserved stock"})
+				return errResponded
+			}
+		}
+
+		if _, err := qtx.AddProductQuantity(context.Background(), db.AddProductQuantityParams{
+			Quantity: req.Quantity,
+			ID:       productId,
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust product quantity"})
+			return errResponded
+		}
+		product.Quantity += req.Quantity
+
+		if err := recordInventory(c, qtx, inventory.Adjustment(productId, req.Quantity, req.Reason)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record inventory movement"})
+			return errResponded
+		}
+
+		if !saveIdempotentResponse(c, qtx, http.StatusOK, product) {
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// ReconcileInventoryHandler godoc
+// @Summary      Reconcile stock against inventory movements
+// @Description  Recompute the stock of every product from its inventory movements and list the products whose quantity differs
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} InventoryReconcileResponse "Reconciliation result"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /inventory/reconcile [get]
+func (h *Handler) ReconcileInventoryHandler(c *gin.Context) {
+	products, err := h.db.ListInventoryMismatches(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile inventory"})
+		return
+	}
+
+	c.JSON(http.StatusOK, InventoryReconcileResponse{
+		Balanced: len(products) == 0,
+		Products: products,
+	})
+}
+
+// recordInventory writes m on q with the user of the request as its actor.
+func recordInventory(c *gin.Context, q db.Querier, m inventory.Movement) error {
+	m.Actor = requestActor(c)
+	return inventory.Record(context.Background(), q, m)
+}
+
+// requestActor is the user ID of the caller, empty on routes without
+// JwtMiddleware.
+func requestActor(c *gin.Context) string {
+	principal, ok := auth.PrincipalFrom(c.Request.Context())
+	if !ok {
+		return ""
+	}
+	return principal.UserID.String()
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buranasakS/trading_application/auth"
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/inventory"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListProductInventoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	movements := []db.ListInventoryMovementsRow{
+		{Kind: inventory.KindSale, Quantity: -2, RunningQuantity: 8, OrderID: orderId},
+		{Kind: inventory.KindInitialStock, Quantity: 10, RunningQuantity: 10},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:  "Success",
+			query: "?limit=2&page=1",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{ID: productId, Quantity: 8}, nil).Times(1)
+				mockDB.EXPECT().ListInventoryMovements(gomock.Any(), db.ListInventoryMovementsParams{
+					ProductID:  productId,
+					PageLimit:  2,
+					PageOffset: 0,
+				}).Return(movements, nil).Times(1)
+				mockDB.EXPECT().CountInventoryMovements(gomock.Any(), productId).Return(int64(3), nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Product not found",
+			query: "",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product not found",
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=abc",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid limit value. Must be a positive integer.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/products/:id/inventory", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListProductInventoryHandler)
+
+			req, err := http.NewRequest(http.MethodGet, "/products/"+productId.String()+"/inventory"+tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response ResponseInventoryMovements
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, int32(8), response.Quantity)
+			require.Equal(t, int32(3), response.TotalCount)
+			require.Equal(t, int32(2), response.TotalPage)
+			require.Equal(t, int32(2), response.Count)
+			require.Equal(t, int32(8), response.Data[0].RunningQuantity)
+		})
+	}
+}
+
+func TestAdjustProductInventoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	actorId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174009")
+	product := db.Product{ID: productId, Quantity: 10}
+
+	tests := []struct {
+		name             string
+		reqBody          string
+		buildStubs       func(mockDB *mockdb.MockQuerier)
+		expectedStatus   int
+		expectedError    string
+		expectedQuantity int32
+		expectedCommits  int
+	}{
+		{
+			name:    "Remove stock",
+			reqBody: `{"quantity": -3, "reason": "Damaged in storage"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), db.GetReservedProductQuantityParams{ProductID: productId}).Return(int32(7), nil).Times(1)
+				mockDB.EXPECT().AddProductQuantity(gomock.Any(), db.AddProductQuantityParams{Quantity: -3, ID: productId}).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), db.CreateInventoryMovementParams{
+					ProductID: productId,
+					Kind:      inventory.KindAdjustment,
+					Quantity:  -3,
+					Reason:    pgtype.Text{String: "Damaged in storage", Valid: true},
+					Actor:     pgtype.Text{String: "123e4567-e89b-12d3-a456-426614174009", Valid: true},
+				}).Return(db.InventoryMovement{}, nil).Times(1)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedQuantity: 7,
+			expectedCommits:  1,
+		},
+		{
+			name:    "Stock would go negative",
+			reqBody: `{"quantity": -11, "reason": "Stock count"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Adjustment would make the stock negative",
+		},
+		{
+			name:    "Stock is reserved",
+			reqBody: `{"quantity": -3, "reason": "Stock count"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), db.GetReservedProductQuantityParams{ProductID: productId}).Return(int32(8), nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Adjustment would remove reserved stock",
+		},
+		{
+			name:    "Reserved quantity fails",
+			reqBody: `{"quantity": -3, "reason": "Stock count"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), gomock.Any()).Return(int32(0), errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to get reserved quantity",
+		},
+		{
+			name:           "Missing reason",
+			reqBody:        `{"quantity": 1}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Key: 'RequestInventoryAdjustment.Reason' Error:Field validation for 'Reason' failed on the 'required' tag",
+		},
+		{
+			name:           "Blank reason",
+			reqBody:        `{"quantity": 1, "reason": "  "}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Reason must not be empty",
+		},
+		{
+			name:    "Movement fails",
+			reqBody: `{"quantity": 2, "reason": "Stock count"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().AddProductQuantity(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to record inventory movement",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+			store := mockdb.NewFakeStore(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(auth.Principal{UserID: actorId, Role: auth.RoleAdmin}))
+			router.POST("/products/:id/inventory/adjustments", NewHandler(store, testKeys).AdjustProductInventoryHandler)
+
+			req, err := http.NewRequest(http.MethodPost, "/products/"+productId.String()+"/inventory/adjustments", bytes.NewBufferString(tt.reqBody))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Equal(t, tt.expectedCommits, store.Commits)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+			} else {
+				var response db.Product
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedQuantity, response.Quantity)
+			}
+		})
+	}
+}
+
+func TestReconcileInventoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name             string
+		products         []db.ListInventoryMismatchesRow
+		productsErr      error
+		expectedStatus   int
+		expectedBalanced bool
+	}{
+		{
+			name:             "Balanced",
+			products:         []db.ListInventoryMismatchesRow{},
+			expectedStatus:   http.StatusOK,
+			expectedBalanced: true,
+		},
+		{
+			name: "Stock drifted from movements",
+			products: []db.ListInventoryMismatchesRow{
+				{ID: productId, Quantity: 10, MovementQuantity: 8},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Database error",
+			productsErr:    errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB.EXPECT().ListInventoryMismatches(gomock.Any()).Return(tt.products, tt.productsErr).Times(1)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/inventory/reconcile", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ReconcileInventoryHandler)
+
+			req, err := http.NewRequest(http.MethodGet, "/inventory/reconcile", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response InventoryReconcileResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedBalanced, response.Balanced)
+				require.Len(t, response.Products, len(tt.products))
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerReconcileResponse struct {
+	Balanced   bool                                   `json:"balanced"`
+	Users      []db.ListUserBalanceMismatchesRow      `json:"users"`
+	Affiliates []db.ListAffiliateBalanceMismatchesRow `json:"affiliates"`
+}
+
+// ReconcileLedgerHandler godoc
+// @Summary      Reconcile balances against the ledger
+// @Description  Compare every user and affiliate balance with the sum of its ledger postings and list the accounts that differ
+// @Tags         Ledger
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} LedgerReconcileResponse "Reconciliation result"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /ledger/reconcile [get]
+func (h *Handler) ReconcileLedgerHandler(c *gin.Context) {
+	users, err := h.db.ListUserBalanceMismatches(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile user balances"})
+		return
+	}
+
+	affiliates, err := h.db.ListAffiliateBalanceMismatches(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile affiliate balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LedgerReconcileResponse{
+		Balanced:   len(users) == 0 && len(affiliates) == 0,
+		Users:      users,
+		Affiliates: affiliates,
+	})
+}
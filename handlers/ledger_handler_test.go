@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileLedgerHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name             string
+		users            []db.ListUserBalanceMismatchesRow
+		usersErr         error
+		affiliates       []db.ListAffiliateBalanceMismatchesRow
+		expectAffiliates bool
+		expectedStatus   int
+		expectedBalanced bool
+		expectedError    string
+	}{
+		{
+			name:             "Balanced",
+			users:            []db.ListUserBalanceMismatchesRow{},
+			affiliates:       []db.ListAffiliateBalanceMismatchesRow{},
+			expectAffiliates: true,
+			expectedStatus:   http.StatusOK,
+			expectedBalanced: true,
+		},
+		{
+			name: "User balance drifted from ledger",
+			users: []db.ListUserBalanceMismatchesRow{
+				{ID: userId, Balance: decimal.NewFromInt(100), LedgerBalance: decimal.NewFromInt(90)},
+			},
+			affiliates:       []db.ListAffiliateBalanceMismatchesRow{},
+			expectAffiliates: true,
+			expectedStatus:   http.StatusOK,
+			expectedBalanced: false,
+		},
+		{
+			name:           "Database error",
+			usersErr:       errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to reconcile user balances",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB.EXPECT().ListUserBalanceMismatches(gomock.Any()).Return(tt.users, tt.usersErr).Times(1)
+			if tt.expectAffiliates {
+				mockDB.EXPECT().ListAffiliateBalanceMismatches(gomock.Any()).Return(tt.affiliates, nil).Times(1)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/ledger/reconcile", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ReconcileLedgerHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/ledger/reconcile", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response LedgerReconcileResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedBalanced, response.Balanced)
+				require.Len(t, response.Users, len(tt.users))
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
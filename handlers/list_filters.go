@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errInvalidMinPrice    = errors.New("Invalid min_price value")
+	errInvalidMaxPrice    = errors.New("Invalid max_price value")
+	errInvalidPriceRange  = errors.New("min_price must not be more than max_price")
+	errInvalidAffiliateID = errors.New("Invalid affiliate ID")
+	errInvalidOrderID     = errors.New("Invalid order ID")
+	errInvalidInStock     = errors.New("Invalid in_stock value")
+)
+
+// queryText is the query parameter key as a filter, NULL when it is absent
+// or empty.
+func queryText(c *gin.Context, key string) pgtype.Text {
+	value := c.Query(key)
+	if value == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: value, Valid: true}
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern with a backslash, so a
+// value matches only itself inside the pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// queryContains is the query parameter key as a filter for ILIKE ... ESCAPE
+// '\', with the wildcards in the value escaped. NULL when it is absent or
+// empty.
+func queryContains(c *gin.Context, key string) pgtype.Text {
+	text := queryText(c, key)
+	text.String = likeEscaper.Replace(text.String)
+	return text
+}
+
+// queryUUID is the query parameter key as a filter, NULL when it is absent.
+// invalid is returned when the value is not a UUID.
+func queryUUID(c *gin.Context, key string, invalid error) (pgtype.UUID, error) {
+	var id pgtype.UUID
+	value := c.Query(key)
+	if value == "" {
+		return id, nil
+	}
+	if err := id.Scan(value); err != nil {
+		return id, invalid
+	}
+	return id, nil
+}
+
+// queryDecimal is the query parameter key as a filter, NULL when it is
+// absent. invalid is returned when the value is not a number.
+func queryDecimal(c *gin.Context, key string, invalid error) (decimal.NullDecimal, error) {
+	value := c.Query(key)
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.NullDecimal{}, invalid
+	}
+	return decimal.NewNullDecimal(d), nil
+}
+
+// queryBool is the query parameter key as a flag, false when it is absent.
+// invalid is returned when the value is not a boolean.
+func queryBool(c *gin.Context, key string, invalid error) (bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, invalid
+	}
+	return b, nil
+}
+
+// queryPriceRange reads the min_price and max_price filters.
+func queryPriceRange(c *gin.Context) (decimal.NullDecimal, decimal.NullDecimal, error) {
+	minPrice, err := queryDecimal(c, "min_price", errInvalidMinPrice)
+	if err != nil {
+		return minPrice, minPrice, err
+	}
+
+	maxPrice, err := queryDecimal(c, "max_price", errInvalidMaxPrice)
+	if err != nil {
+		return minPrice, maxPrice, err
+	}
+
+	if minPrice.Valid && maxPrice.Valid && minPrice.Decimal.GreaterThan(maxPrice.Decimal) {
+		return minPrice, maxPrice, errInvalidPriceRange
+	}
+
+	return minPrice, maxPrice, nil
+}
@@ -0,0 +1,460 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/inventory"
+	"github.com/buranasakS/trading_application/ledger"
+	"github.com/buranasakS/trading_application/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	OrderStatusCompleted         = "completed"
+	OrderStatusPartiallyRefunded = "partially_refunded"
+	OrderStatusRefunded          = "refunded"
+	OrderStatusCancelled         = "cancelled"
+)
+
+// OrderCancelWindow is how long after ordering customers can cancel an order
+// themselves. Later, or once part of it is refunded, only staff can.
+const OrderCancelWindow = 30 * time.Minute
+
+type OrderDetailResponse struct {
+	ID             pgtype.UUID                     `json:"id"`
+	UserID         pgtype.UUID                     `json:"user_id"`
+	TotalCost      decimal.Decimal                 `json:"total_cost"`
+	RefundedAmount decimal.Decimal                 `json:"refunded_amount"`
+	Status         string                          `json:"status"`
+	CreatedAt      pgtype.Timestamptz              `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz              `json:"updated_at"`
+	Items          []db.ListOrderItemsByOrderIDRow `json:"items"`
+}
+
+type RefundItemRequest struct {
+	ProductID pgtype.UUID `json:"product_id"`
+	Quantity  int32       `json:"quantity" binding:"required"`
+}
+
+type RefundOrderRequest struct {
+	Items []RefundItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type RefundResponse struct {
+	Status             string          `json:"status"`
+	Message            string          `json:"message"`
+	OrderID            string          `json:"order_id"`
+	OrderStatus        string          `json:"order_status"`
+	RefundedAmount     decimal.Decimal `json:"refunded_amount"`
+	CommissionReversed decimal.Decimal `json:"commission_reversed"`
+}
+
+type refundLine struct {
+	item     db.ListOrderItemsByOrderIDRow
+	quantity int32
+}
+
+type ResponseOrders struct {
+	Page       int32      `json:"page"`
+	TotalPage  int32      `json:"total_page"`
+	Count      int32      `json:"count"`
+	TotalCount int32      `json:"total_count"`
+	Data       []db.Order `json:"data"`
+}
+
+// GetOrderDetailHandler godoc
+// @Summary      Get order details by ID
+// @Description  Retrieve an order with its line items
+// @Tags         Orders
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Order ID (UUID)"
+// @Success      200  {object}  OrderDetailResponse
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /orders/{id} [get]
+func (h *Handler) GetOrderDetailHandler(c *gin.Context) {
+	var orderId pgtype.UUID
+	if err := orderId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := h.db.GetOrderByID(context.Background(), orderId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	if !authorizeUser(c, order.UserID) {
+		return
+	}
+
+	items, err := h.db.ListOrderItemsByOrderID(context.Background(), orderId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch order items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, OrderDetailResponse{
+		ID:             order.ID,
+		UserID:         order.UserID,
+		TotalCost:      order.TotalCost,
+		RefundedAmount: order.RefundedAmount,
+		Status:         order.Status,
+		CreatedAt:      order.CreatedAt,
+		UpdatedAt:      order.UpdatedAt,
+		Items:          items,
+	})
+}
+
+// ListUserOrdersHandler godoc
+// @Summary      List orders of a user with pagination
+// @Description  Fetch a paginated list of orders placed by a user, newest first
+// @Tags         Orders
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path    string  true   "User ID (UUID)"
+// @Param        limit  query   int     false  "Number of orders per page (default 10)"
+// @Param        page   query   int     false  "Page number (default 1)"
+// @Success      200  {object}  ResponseOrders
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /users/{id}/orders [get]
+func (h *Handler) ListUserOrdersHandler(c *gin.Context) {
+	var userId pgtype.UUID
+	if err := userId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+		return
+	}
+
+	if !authorizeUser(c, userId) {
+		return
+	}
+
+	params, err := pagination.ParseOffset(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, err := h.db.ListOrdersByUserID(context.Background(), db.ListOrdersByUserIDParams{
+		UserID: userId,
+		Limit:  params.Limit,
+		Offset: params.Offset(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
+		return
+	}
+
+	totalCount, err := h.db.CountOrdersByUserID(context.Background(), userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponseOrders{
+		Page:       params.Page,
+		TotalPage:  pagination.TotalPages(totalCount, params.Limit),
+		Count:      int32(len(orders)),
+		TotalCount: int32(totalCount),
+		Data:       orders,
+	})
+}
+
+// CancelOrderHandler godoc
+// @Summary      Cancel an order
+// @Description  Refund every unrefunded item of an order, return the stock and claw back the commissions. Customers can only cancel their own orders within 30 minutes of ordering and before any refund; staff can cancel any order
+// @Tags         Orders
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Order ID (UUID)"
+// @Success      200  {object}  RefundResponse "Order cancelled"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /orders/{id}/cancel [post]
+func (h *Handler) CancelOrderHandler(c *gin.Context) {
+	var orderId pgtype.UUID
+	if err := orderId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+
+	h.refundOrder(c, orderId, "Order cancelled", func(order db.Order, items []db.ListOrderItemsByOrderIDRow) ([]refundLine, string, string) {
+		if !principal.Elevated() && (order.Status != OrderStatusCompleted || time.Since(order.CreatedAt.Time) > OrderCancelWindow) {
+			return nil, "", "Order can no longer be cancelled"
+		}
+
+		var lines []refundLine
+		for _, item := range items {
+			if remaining := item.Quantity - item.RefundedQuantity; remaining > 0 {
+				lines = append(lines, refundLine{item: item, quantity: remaining})
+			}
+		}
+
+		return lines, OrderStatusCancelled, ""
+	})
+}
+
+// RefundOrderHandler godoc
+// @Summary      Partially refund an order
+// @Description  Refund some quantity of the order items, return the stock and claw back the commissions proportionally
+// @Tags         Orders
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path    string              true  "Order ID (UUID)"
+// @Param        request  body    RefundOrderRequest  true  "Items to refund"
+// @Success      200  {object}  RefundResponse "Order refunded"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /orders/{id}/refund [post]
+func (h *Handler) RefundOrderHandler(c *gin.Context) {
+	var orderId pgtype.UUID
+	if err := orderId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var req RefundOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, reqItem := range req.Items {
+		if reqItem.Quantity <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be more than 0"})
+			return
+		}
+	}
+
+	h.refundOrder(c, orderId, "Order refunded", func(order db.Order, items []db.ListOrderItemsByOrderIDRow) ([]refundLine, string, string) {
+		requested := map[pgtype.UUID]int32{}
+		for _, reqItem := range req.Items {
+			requested[reqItem.ProductID] += reqItem.Quantity
+		}
+
+		var lines []refundLine
+		fullyRefunded := true
+		for _, item := range items {
+			remaining := item.Quantity - item.RefundedQuantity
+			quantity, ok := requested[item.ProductID]
+			if ok {
+				if quantity > remaining {
+					return nil, "", "Refund quantity exceeds purchased quantity"
+				}
+				lines = append(lines, refundLine{item: item, quantity: quantity})
+				delete(requested, item.ProductID)
+			}
+			if remaining-quantity > 0 {
+				fullyRefunded = false
+			}
+		}
+
+		if len(requested) > 0 {
+			return nil, "", "Product is not part of this order"
+		}
+
+		if fullyRefunded {
+			return lines, OrderStatusRefunded, ""
+		}
+		return lines, OrderStatusPartiallyRefunded, ""
+	})
+}
+
+// refundPlan picks the lines to refund from the locked order and its items
+// and the status the order ends in. A non-empty problem refuses the refund
+// with 400.
+type refundPlan func(order db.Order, items []db.ListOrderItemsByOrderIDRow) (lines []refundLine, status string, problem string)
+
+// refundOrder returns the money and stock for the lines picked by plan and
+// reverses the commissions paid on the order in a single transaction. The
+// order is locked first and plan sees its items as the previous refund left
+// them, so concurrent refunds cannot refund an item twice or both leave the
+// order partially refunded. Commissions are clawed back in proportion to the
+// refunded amount, rounded to the cent with helpers.RoundMoney, or in full
+// once nothing is left to refund, and every reversal is kept as a negative
+// commission row.
+func (h *Handler) refundOrder(c *gin.Context, orderId pgtype.UUID, message string, plan refundPlan) {
+	var order, updated db.Order
+	var refundAmount decimal.Decimal
+	commissionReversed := decimal.Zero
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		var err error
+		order, err = qtx.GetOrderByIDForUpdate(context.Background(), orderId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return errResponded
+		}
+
+		if !authorizeUser(c, order.UserID) {
+			return errResponded
+		}
+
+		if order.Status == OrderStatusCancelled || order.Status == OrderStatusRefunded {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Order is already cancelled or fully refunded"})
+			return errResponded
+		}
+
+		items, err := qtx.ListOrderItemsByOrderID(context.Background(), orderId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch order items"})
+			return errResponded
+		}
+
+		lines, status, problem := plan(order, items)
+		if problem != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+			return errResponded
+		}
+
+		if len(lines) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing left to refund"})
+			return errResponded
+		}
+
+		refundAmount = decimal.Zero
+		for _, line := range lines {
+			refundAmount = refundAmount.Add(line.item.UnitPrice.Mul(decimal.NewFromInt32(line.quantity)))
+		}
+
+		for _, line := range lines {
+			result, err := qtx.AddOrderItemRefundedQuantity(context.Background(), db.AddOrderItemRefundedQuantityParams{
+				RefundedQuantity: line.quantity,
+				ID:               line.item.ID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order item"})
+				return errResponded
+			}
+
+			if result == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Refund quantity exceeds purchased quantity"})
+				return errResponded
+			}
+		}
+
+		// Credit the user before restocking the products, the same lock order
+		// as UserOrderProductHandler.
+		_, err = qtx.AddUserBalance(context.Background(), db.AddUserBalanceParams{
+			Balance: refundAmount,
+			ID:      order.UserID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund balance"})
+			return errResponded
+		}
+
+		err = ledger.Record(context.Background(), qtx, ledger.Refund(order.UserID, order.ID, refundAmount))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record ledger entry"})
+			return errResponded
+		}
+
+		for _, line := range lines {
+			_, err = qtx.AddProductQuantity(context.Background(), db.AddProductQuantityParams{
+				Quantity: line.quantity,
+				ID:       line.item.ProductID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore product quantity"})
+				return errResponded
+			}
+
+			if err := recordInventory(c, qtx, inventory.Return(line.item.ProductID, order.ID, line.quantity, message)); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record inventory movement"})
+				return errResponded
+			}
+		}
+
+		balances, err := qtx.ListCommissionBalancesByOrderID(context.Background(), order.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch commissions"})
+			return errResponded
+		}
+
+		for _, balance := range balances {
+			reversal := balance.Outstanding
+			if status == OrderStatusPartiallyRefunded {
+				share := helpers.RoundMoney(balance.Earned.Mul(refundAmount).Div(order.TotalCost))
+				reversal = decimal.Min(share, balance.Outstanding)
+			}
+
+			if !reversal.IsPositive() {
+				continue
+			}
+
+			reversalRow, err := qtx.CreateCommissionReversal(context.Background(), db.CreateCommissionReversalParams{
+				OrderID:     order.ID,
+				AffiliateID: balance.AffiliateID,
+				Amount:      reversal.Neg(),
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reverse commission"})
+				return errResponded
+			}
+
+			err = qtx.DeductAffiliateBalance(context.Background(), db.DeductAffiliateBalanceParams{
+				Balance: reversal,
+				ID:      balance.AffiliateID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct affiliate balance"})
+				return errResponded
+			}
+
+			err = ledger.Record(context.Background(), qtx, ledger.CommissionReversal(balance.AffiliateID, order.ID, reversalRow.ID, reversal))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record ledger entry"})
+				return errResponded
+			}
+
+			commissionReversed = commissionReversed.Add(reversal)
+		}
+
+		updated, err = qtx.UpdateOrderRefund(context.Background(), db.UpdateOrderRefundParams{
+			RefundedAmount: refundAmount,
+			Status:         status,
+			ID:             order.ID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order"})
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, RefundResponse{
+		Status:             "success",
+		Message:            message,
+		OrderID:            uuid.UUID(order.ID.Bytes).String(),
+		OrderStatus:        updated.Status,
+		RefundedAmount:     refundAmount,
+		CommissionReversed: commissionReversed,
+	})
+}
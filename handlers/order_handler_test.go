@@ -0,0 +1,686 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/buranasakS/trading_application/auth"
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrderDetailHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+
+	order := db.Order{ID: orderId, UserID: userId, TotalCost: decimal.NewFromInt(200), Status: OrderStatusCompleted}
+	items := []db.ListOrderItemsByOrderIDRow{
+		{ProductID: productId, ProductName: "Product 1", Quantity: 2, UnitPrice: decimal.NewFromInt(100), TotalPrice: decimal.NewFromInt(200), ListPrice: decimal.NewFromInt(120)},
+	}
+
+	tests := []struct {
+		name           string
+		paramID        string
+		mockOrderErr   error
+		mockItemsErr   error
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Success Get Order Detail",
+			paramID:        orderId.String(),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Order Not Found",
+			paramID:        orderId.String(),
+			mockOrderErr:   errors.New("no rows in result set"),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Order not found",
+		},
+		{
+			name:           "Failed to fetch order items",
+			paramID:        orderId.String(),
+			mockItemsErr:   errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to fetch order items",
+		},
+		{
+			name:           "Invalid order ID",
+			paramID:        "invalid-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid order ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectedStatus != http.StatusBadRequest {
+				mockDB.EXPECT().GetOrderByID(gomock.Any(), orderId).Return(order, tt.mockOrderErr).Times(1)
+				if tt.mockOrderErr == nil {
+					mockDB.EXPECT().ListOrderItemsByOrderID(gomock.Any(), orderId).Return(items, tt.mockItemsErr).Times(1)
+				}
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.GET("/orders/:id", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetOrderDetailHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/orders/"+tt.paramID, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response OrderDetailResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, order.ID, response.ID)
+				require.Equal(t, order.TotalCost, response.TotalCost)
+				require.Equal(t, items, response.Items)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestListUserOrdersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	orders := []db.Order{
+		{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174010"), UserID: userId, TotalCost: decimal.NewFromInt(100), Status: OrderStatusCompleted},
+		{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174011"), UserID: userId, TotalCost: decimal.NewFromInt(50), Status: OrderStatusCompleted},
+	}
+
+	tests := []struct {
+		name           string
+		paramID        string
+		query          string
+		mockOrdersErr  error
+		mockCountErr   error
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Success List Orders",
+			paramID:        userId.String(),
+			query:          "?limit=2&page=1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid User ID",
+			paramID:        "invalid-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid User ID",
+		},
+		{
+			name:           "Invalid limit",
+			paramID:        userId.String(),
+			query:          "?limit=abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid limit value. Must be a positive integer.",
+		},
+		{
+			name:           "Invalid page",
+			paramID:        userId.String(),
+			query:          "?page=0",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid page value. Must be a positive integer.",
+		},
+		{
+			name:           "Failed to fetch orders",
+			paramID:        userId.String(),
+			mockOrdersErr:  errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to fetch orders",
+		},
+		{
+			name:           "Failed to count orders",
+			paramID:        userId.String(),
+			mockCountErr:   errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to count orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectedStatus != http.StatusBadRequest {
+				mockDB.EXPECT().ListOrdersByUserID(gomock.Any(), gomock.Any()).Return(orders, tt.mockOrdersErr).Times(1)
+				if tt.mockOrdersErr == nil {
+					mockDB.EXPECT().CountOrdersByUserID(gomock.Any(), userId).Return(int64(5), tt.mockCountErr).Times(1)
+				}
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.GET("/users/:id/orders", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListUserOrdersHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/users/"+tt.paramID+"/orders"+tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response ResponseOrders
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, int32(1), response.Page)
+				require.Equal(t, int32(3), response.TotalPage)
+				require.Equal(t, int32(2), response.Count)
+				require.Equal(t, int32(5), response.TotalCount)
+				require.Len(t, response.Data, 2)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestCancelOrderHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	customer := auth.Principal{UserID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001"), Role: auth.RoleCustomer}
+	recent := pgtype.Timestamptz{Time: time.Now().Add(-5 * time.Minute), Valid: true}
+	old := pgtype.Timestamptz{Time: time.Now().Add(-OrderCancelWindow - time.Minute), Valid: true}
+	unrefunded := []db.ListOrderItemsByOrderIDRow{
+		{ProductID: productId, Quantity: 2, UnitPrice: decimal.NewFromInt(100)},
+	}
+	refunded := []db.ListOrderItemsByOrderIDRow{
+		{ProductID: productId, Quantity: 2, RefundedQuantity: 2, UnitPrice: decimal.NewFromInt(100)},
+	}
+
+	tests := []struct {
+		name           string
+		paramID        string
+		principal      *auth.Principal
+		mockOrder      db.Order
+		mockOrderErr   error
+		mockItems      []db.ListOrderItemsByOrderIDRow
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Invalid order ID",
+			paramID:        "invalid-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid order ID",
+		},
+		{
+			name:           "Order Not Found",
+			paramID:        orderId.String(),
+			mockOrderErr:   errors.New("no rows in result set"),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Order not found",
+		},
+		{
+			name:           "Order already cancelled",
+			paramID:        orderId.String(),
+			mockOrder:      db.Order{ID: orderId, Status: OrderStatusCancelled},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Order is already cancelled or fully refunded",
+		},
+		{
+			name:      "Nothing left to refund",
+			paramID:   orderId.String(),
+			mockOrder: db.Order{ID: orderId, Status: OrderStatusPartiallyRefunded},
+			mockItems: []db.ListOrderItemsByOrderIDRow{
+				{ProductID: productId, Quantity: 2, RefundedQuantity: 2, UnitPrice: decimal.NewFromInt(100)},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Nothing left to refund",
+		},
+		{
+			name:           "Customer after the cancel window",
+			paramID:        orderId.String(),
+			principal:      &customer,
+			mockOrder:      db.Order{ID: orderId, UserID: customer.UserID, Status: OrderStatusCompleted, CreatedAt: old},
+			mockItems:      unrefunded,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Order can no longer be cancelled",
+		},
+		{
+			name:           "Customer after a partial refund",
+			paramID:        orderId.String(),
+			principal:      &customer,
+			mockOrder:      db.Order{ID: orderId, UserID: customer.UserID, Status: OrderStatusPartiallyRefunded, CreatedAt: recent},
+			mockItems:      unrefunded,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Order can no longer be cancelled",
+		},
+		{
+			// Past the window check, there is nothing left to cancel.
+			name:           "Customer within the cancel window",
+			paramID:        orderId.String(),
+			principal:      &customer,
+			mockOrder:      db.Order{ID: orderId, UserID: customer.UserID, Status: OrderStatusCompleted, CreatedAt: recent},
+			mockItems:      refunded,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Nothing left to refund",
+		},
+		{
+			name:           "Staff after the cancel window",
+			paramID:        orderId.String(),
+			mockOrder:      db.Order{ID: orderId, Status: OrderStatusCompleted, CreatedAt: old},
+			mockItems:      refunded,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Nothing left to refund",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.paramID == orderId.String() {
+				mockDB.EXPECT().GetOrderByIDForUpdate(gomock.Any(), orderId).Return(tt.mockOrder, tt.mockOrderErr).Times(1)
+				if tt.mockItems != nil {
+					mockDB.EXPECT().ListOrderItemsByOrderID(gomock.Any(), orderId).Return(tt.mockItems, nil).Times(1)
+				}
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			principal := testAdmin
+			if tt.principal != nil {
+				principal = *tt.principal
+			}
+			router.Use(withPrincipal(principal))
+			router.POST("/orders/:id/cancel", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).CancelOrderHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodPost, "/orders/"+tt.paramID+"/cancel", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			var response map[string]string
+			err = json.Unmarshal(recorder.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedError, response["error"])
+		})
+	}
+}
+
+func TestRefundOrderHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	otherProductId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+
+	order := db.Order{ID: orderId, TotalCost: decimal.NewFromInt(200), Status: OrderStatusCompleted}
+	items := []db.ListOrderItemsByOrderIDRow{
+		{ProductID: productId, Quantity: 2, RefundedQuantity: 1, UnitPrice: decimal.NewFromInt(100), TotalPrice: decimal.NewFromInt(200)},
+	}
+
+	tests := []struct {
+		name           string
+		paramID        string
+		reqBody        interface{}
+		mockOrder      db.Order
+		mockOrderErr   error
+		expectItems    bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Invalid order ID",
+			paramID:        "invalid-uuid",
+			reqBody:        RefundOrderRequest{Items: []RefundItemRequest{{ProductID: productId, Quantity: 1}}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid order ID",
+		},
+		{
+			name:           "Missing items",
+			paramID:        orderId.String(),
+			reqBody:        RefundOrderRequest{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Key: 'RefundOrderRequest.Items' Error:Field validation for 'Items' failed on the 'required' tag",
+		},
+		{
+			name:           "Order Not Found",
+			paramID:        orderId.String(),
+			reqBody:        RefundOrderRequest{Items: []RefundItemRequest{{ProductID: productId, Quantity: 1}}},
+			mockOrderErr:   errors.New("no rows in result set"),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Order not found",
+		},
+		{
+			name:           "Order already refunded",
+			paramID:        orderId.String(),
+			reqBody:        RefundOrderRequest{Items: []RefundItemRequest{{ProductID: productId, Quantity: 1}}},
+			mockOrder:      db.Order{ID: orderId, Status: OrderStatusRefunded},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Order is already cancelled or fully refunded",
+		},
+		{
+			name:           "Negative quantity",
+			paramID:        orderId.String(),
+			reqBody:        RefundOrderRequest{Items: []RefundItemRequest{{ProductID: productId, Quantity: -1}}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Quantity must be more than 0",
+		},
+		{
+			name:           "Quantity exceeds remaining",
+			paramID:        orderId.String(),
+			reqBody:        RefundOrderRequest{Items: []RefundItemRequest{{ProductID: productId, Quantity: 2}}},
+			mockOrder:      order,
+			expectItems:    true,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Refund quantity exceeds purchased quantity",
+		},
+		{
+			name:           "Product not in order",
+			paramID:        orderId.String(),
+			reqBody:        RefundOrderRequest{Items: []RefundItemRequest{{ProductID: otherProductId, Quantity: 1}}},
+			mockOrder:      order,
+			expectItems:    true,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product is not part of this order",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockOrderErr != nil || tt.mockOrder.ID.Valid {
+				mockDB.EXPECT().GetOrderByIDForUpdate(gomock.Any(), orderId).Return(tt.mockOrder, tt.mockOrderErr).Times(1)
+			}
+			if tt.expectItems {
+				mockDB.EXPECT().ListOrderItemsByOrderID(gomock.Any(), orderId).Return(items, nil).Times(1)
+			}
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/orders/:id/refund", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).RefundOrderHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodPost, "/orders/"+tt.paramID+"/refund", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			var response map[string]string
+			err = json.Unmarshal(recorder.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedError, response["error"])
+		})
+	}
+}
+
+func TestRefundOrderHandlerRollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	affiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+
+	order := db.Order{ID: orderId, UserID: userId, TotalCost: decimal.NewFromInt(200), Status: OrderStatusCompleted}
+	items := []db.ListOrderItemsByOrderIDRow{
+		{ProductID: productId, Quantity: 2, UnitPrice: decimal.NewFromInt(100), TotalPrice: decimal.NewFromInt(200)},
+	}
+	balances := []db.ListCommissionBalancesByOrderIDRow{
+		{AffiliateID: affiliateId, Earned: decimal.NewFromInt(20), Outstanding: decimal.NewFromInt(20)},
+	}
+	failure := errors.New("db error")
+
+	tests := []struct {
+		name            string
+		failAt          string
+		staleItem       bool
+		commitErr       error
+		expectedStatus  int
+		expectedError   string
+		expectedCommits int
+	}{
+		{name: "Success", expectedStatus: http.StatusOK, expectedCommits: 1},
+		{name: "Refunded concurrently", staleItem: true, expectedStatus: http.StatusBadRequest, expectedError: "Refund quantity exceeds purchased quantity"},
+		{name: "Update order item fails", failAt: "AddOrderItemRefundedQuantity", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to update order item"},
+		{name: "Refund balance fails", failAt: "AddUserBalance", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to refund balance"},
+		{name: "Refund ledger entry fails", failAt: "RecordRefund", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to record ledger entry"},
+		{name: "Restore stock fails", failAt: "AddProductQuantity", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to restore product quantity"},
+		{name: "Return inventory movement fails", failAt: "RecordReturn", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to record inventory movement"},
+		{name: "Commission lookup fails", failAt: "ListCommissionBalancesByOrderID", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to fetch commissions"},
+		{name: "Commission reversal fails", failAt: "CreateCommissionReversal", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to reverse commission"},
+		{name: "Deduct affiliate balance fails", failAt: "DeductAffiliateBalance", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to deduct affiliate balance"},
+		{name: "Reversal ledger entry fails", failAt: "RecordReversal", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to record ledger entry"},
+		{name: "Update order fails", failAt: "UpdateOrderRefund", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to update order"},
+		{name: "Commit fails", commitErr: failure, expectedStatus: http.StatusInternalServerError, expectedError: "Failed to commit transaction"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			store := mockdb.NewFakeStore(mockDB)
+			store.CommitErr = tt.commitErr
+
+			errAt := func(step string) error {
+				if step == tt.failAt {
+					return failure
+				}
+				return nil
+			}
+
+			mockDB.EXPECT().GetOrderByIDForUpdate(gomock.Any(), orderId).Return(order, nil).Times(1)
+			mockDB.EXPECT().ListOrderItemsByOrderID(gomock.Any(), orderId).Return(items, nil).Times(1)
+
+			func() {
+				refundedRows := int64(1)
+				if tt.staleItem {
+					refundedRows = 0
+				}
+				mockDB.EXPECT().AddOrderItemRefundedQuantity(gomock.Any(), gomock.Any()).Return(refundedRows, errAt("AddOrderItemRefundedQuantity")).Times(1)
+				if tt.failAt == "AddOrderItemRefundedQuantity" || tt.staleItem {
+					return
+				}
+
+				mockDB.EXPECT().AddUserBalance(gomock.Any(), gomock.Any()).Return(int64(1), errAt("AddUserBalance")).Times(1)
+				if tt.failAt == "AddUserBalance" {
+					return
+				}
+
+				if tt.failAt == "RecordRefund" {
+					mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, failure).Times(1)
+					return
+				}
+				mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+
+				mockDB.EXPECT().AddProductQuantity(gomock.Any(), gomock.Any()).Return(int64(1), errAt("AddProductQuantity")).Times(1)
+				if tt.failAt == "AddProductQuantity" {
+					return
+				}
+
+				mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, errAt("RecordReturn")).Times(1)
+				if tt.failAt == "RecordReturn" {
+					return
+				}
+
+				mockDB.EXPECT().ListCommissionBalancesByOrderID(gomock.Any(), orderId).Return(balances, errAt("ListCommissionBalancesByOrderID")).Times(1)
+				if tt.failAt == "ListCommissionBalancesByOrderID" {
+					return
+				}
+
+				mockDB.EXPECT().CreateCommissionReversal(gomock.Any(), gomock.Any()).Return(db.Commission{}, errAt("CreateCommissionReversal")).Times(1)
+				if tt.failAt == "CreateCommissionReversal" {
+					return
+				}
+
+				mockDB.EXPECT().DeductAffiliateBalance(gomock.Any(), gomock.Any()).Return(errAt("DeductAffiliateBalance")).Times(1)
+				if tt.failAt == "DeductAffiliateBalance" {
+					return
+				}
+
+				if tt.failAt == "RecordReversal" {
+					mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, failure).Times(1)
+					return
+				}
+				mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+
+				mockDB.EXPECT().UpdateOrderRefund(gomock.Any(), gomock.Any()).Return(db.Order{ID: orderId, Status: OrderStatusPartiallyRefunded}, errAt("UpdateOrderRefund")).Times(1)
+			}()
+
+			body, err := json.Marshal(RefundOrderRequest{Items: []RefundItemRequest{{ProductID: productId, Quantity: 1}}})
+			require.NoError(t, err)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/orders/:id/refund", func(c *gin.Context) {
+				NewHandler(store, testKeys).RefundOrderHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodPost, "/orders/"+orderId.String()+"/refund", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Equal(t, tt.expectedCommits, store.Commits)
+			require.Equal(t, 1-tt.expectedCommits, store.Rollbacks)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response RefundResponse
+				err = json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, OrderStatusPartiallyRefunded, response.OrderStatus)
+				require.True(t, decimal.NewFromInt(100).Equal(response.RefundedAmount))
+				require.True(t, decimal.NewFromInt(10).Equal(response.CommissionReversed))
+			} else {
+				var response map[string]string
+				err = json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestRefundOrderHandlerLastItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+	store := mockdb.NewFakeStore(mockDB)
+
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	affiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+
+	// An earlier partial refund already returned one of the two items, so
+	// refunding the other one refunds the whole order and reverses all of the
+	// commission still outstanding rather than a rounded share of it.
+	order := db.Order{ID: orderId, UserID: userId, TotalCost: decimal.NewFromInt(200), Status: OrderStatusPartiallyRefunded}
+	items := []db.ListOrderItemsByOrderIDRow{
+		{ProductID: productId, Quantity: 2, RefundedQuantity: 1, UnitPrice: decimal.NewFromInt(100), TotalPrice: decimal.NewFromInt(200)},
+	}
+	balances := []db.ListCommissionBalancesByOrderIDRow{
+		{AffiliateID: affiliateId, Earned: decimal.NewFromInt(21), Outstanding: decimal.NewFromInt(11)},
+	}
+
+	mockDB.EXPECT().GetOrderByIDForUpdate(gomock.Any(), orderId).Return(order, nil).Times(1)
+	mockDB.EXPECT().ListOrderItemsByOrderID(gomock.Any(), orderId).Return(items, nil).Times(1)
+	mockDB.EXPECT().AddOrderItemRefundedQuantity(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+	mockDB.EXPECT().AddUserBalance(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+	mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(4)
+	mockDB.EXPECT().AddProductQuantity(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+	mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, nil).Times(1)
+	mockDB.EXPECT().ListCommissionBalancesByOrderID(gomock.Any(), orderId).Return(balances, nil).Times(1)
+	mockDB.EXPECT().CreateCommissionReversal(gomock.Any(), db.CreateCommissionReversalParams{
+		OrderID:     orderId,
+		AffiliateID: affiliateId,
+		Amount:      decimal.NewFromInt(-11),
+	}).Return(db.Commission{}, nil).Times(1)
+	mockDB.EXPECT().DeductAffiliateBalance(gomock.Any(), db.DeductAffiliateBalanceParams{
+		Balance: decimal.NewFromInt(11),
+		ID:      affiliateId,
+	}).Return(nil).Times(1)
+	mockDB.EXPECT().UpdateOrderRefund(gomock.Any(), db.UpdateOrderRefundParams{
+		RefundedAmount: decimal.NewFromInt(100),
+		Status:         OrderStatusRefunded,
+		ID:             orderId,
+	}).Return(db.Order{ID: orderId, Status: OrderStatusRefunded}, nil).Times(1)
+
+	body, err := json.Marshal(RefundOrderRequest{Items: []RefundItemRequest{{ProductID: productId, Quantity: 1}}})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(withPrincipal(testAdmin))
+	router.POST("/orders/:id/refund", func(c *gin.Context) {
+		NewHandler(store, testKeys).RefundOrderHandler(c)
+	})
+
+	req, err := http.NewRequest(http.MethodPost, "/orders/"+orderId.String()+"/refund", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, 1, store.Commits)
+
+	var response RefundResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Equal(t, OrderStatusRefunded, response.OrderStatus)
+	require.True(t, decimal.NewFromInt(11).Equal(response.CommissionReversed))
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/buranasakS/trading_application/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// requirePrincipal returns the caller that JwtMiddleware authenticated and
+// writes a 401 when there is none.
+func requirePrincipal(c *gin.Context) (auth.Principal, bool) {
+	principal, ok := auth.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return auth.Principal{}, false
+	}
+
+	return principal, true
+}
+
+// authorizeUser checks that the caller may act on the account of userId:
+// their own account, or any account with an elevated role. It writes the
+// error response when not.
+func authorizeUser(c *gin.Context, userId pgtype.UUID) bool {
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return false
+	}
+
+	if !principal.CanActFor(userId) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only access your own account"})
+		return false
+	}
+
+	return true
+}
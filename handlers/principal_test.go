@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buranasakS/trading_application/auth"
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// testAdmin is the caller of handler tests that are not about ownership.
+var testAdmin = auth.Principal{
+	UserID: helpers.PgtypeUUID(nil, "123e4567-e89b-12d3-a456-4266141740ff"),
+	Role:   auth.RoleAdmin,
+}
+
+// testKeys signs the access tokens handlers issue in tests.
+var testKeys = func() *auth.KeySet {
+	key, err := auth.GenerateKey("test")
+	if err != nil {
+		panic(err)
+	}
+	keys, err := auth.NewKeySet(key.ID, key)
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}()
+
+// withPrincipal stands in for JwtMiddleware in handler tests.
+func withPrincipal(p auth.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+	}
+}
+
+func TestAuthorizeUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	own := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	other := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	tests := []struct {
+		name           string
+		principal      *auth.Principal
+		expectedStatus int
+	}{
+		{name: "Own account", principal: &auth.Principal{UserID: own, Role: auth.RoleCustomer}, expectedStatus: http.StatusOK},
+		{name: "Another account", principal: &auth.Principal{UserID: other, Role: auth.RoleCustomer}, expectedStatus: http.StatusForbidden},
+		{name: "Support on another account", principal: &auth.Principal{UserID: other, Role: auth.RoleSupport}, expectedStatus: http.StatusOK},
+		{name: "No principal", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if tt.principal != nil {
+				router.Use(withPrincipal(*tt.principal))
+			}
+			router.GET("/test", func(c *gin.Context) {
+				if authorizeUser(c, own) {
+					c.JSON(http.StatusOK, gin.H{"message": "success"})
+				}
+			})
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test", nil))
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestUserAccountOwnership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gin.SetMode(gin.TestMode)
+
+	caller := auth.Principal{UserID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000"), Role: auth.RoleCustomer}
+	otherId := "123e4567-e89b-12d3-a456-426614174001"
+	other := helpers.PgtypeUUID(t, otherId)
+
+	// Carts, reservations and orders are looked up to find their owner.
+	otherCart := func(mockDB *mockdb.MockQuerier) {
+		mockDB.EXPECT().GetCartByID(gomock.Any(), other).Return(db.Cart{ID: other, UserID: other, Status: CartStatusOpen}, nil).Times(1)
+	}
+	otherReservation := func(mockDB *mockdb.MockQuerier) {
+		mockDB.EXPECT().GetStockReservationByID(gomock.Any(), other).
+			Return(db.StockReservation{ID: other, UserID: other, Status: ReservationStatusActive}, nil).Times(1)
+	}
+	otherOrder := func(mockDB *mockdb.MockQuerier) {
+		mockDB.EXPECT().GetOrderByID(gomock.Any(), other).Return(db.Order{ID: other, UserID: other, Status: OrderStatusCompleted}, nil).Times(1)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		route      string
+		path       string
+		body       string
+		buildStubs func(mockDB *mockdb.MockQuerier)
+		handler    func(h *Handler) gin.HandlerFunc
+	}{
+		{name: "User detail", method: http.MethodGet, route: "/users/:id", path: "/users/" + otherId, handler: func(h *Handler) gin.HandlerFunc { return h.GetUserDetailHandler }},
+		{name: "User orders", method: http.MethodGet, route: "/users/:id/orders", path: "/users/" + otherId + "/orders", handler: func(h *Handler) gin.HandlerFunc { return h.ListUserOrdersHandler }},
+		{name: "User transactions", method: http.MethodGet, route: "/users/:id/transactions", path: "/users/" + otherId + "/transactions", handler: func(h *Handler) gin.HandlerFunc { return h.ListUserTransactionsHandler }},
+		{name: "Deduct balance", method: http.MethodPatch, route: "/users/deduct/balance/:id", path: "/users/deduct/balance/" + otherId, body: `{"amount": "10"}`, handler: func(h *Handler) gin.HandlerFunc { return h.DeductUserBalanceHandler }},
+		{name: "Add balance", method: http.MethodPatch, route: "/users/add/balance/:id", path: "/users/add/balance/" + otherId, body: `{"amount": "10"}`, handler: func(h *Handler) gin.HandlerFunc { return h.AddUserBalanceHandler }},
+		{name: "Order for another user", method: http.MethodPost, route: "/users/order", path: "/users/order", body: `{"user_id": "` + otherId + `", "product_id": "` + otherId + `", "quantity": 1}`, handler: func(h *Handler) gin.HandlerFunc { return h.UserOrderProductHandler }},
+		{name: "Cart for another user", method: http.MethodPost, route: "/carts", path: "/carts", body: `{"user_id": "` + otherId + `"}`, handler: func(h *Handler) gin.HandlerFunc { return h.CreateCartHandler }},
+		{name: "Another user's cart", method: http.MethodGet, route: "/carts/:id", path: "/carts/" + otherId, buildStubs: otherCart, handler: func(h *Handler) gin.HandlerFunc { return h.GetCartHandler }},
+		{name: "Add to another user's cart", method: http.MethodPost, route: "/carts/:id/items", path: "/carts/" + otherId + "/items", body: `{"product_id": "` + otherId + `", "quantity": 1}`, buildStubs: otherCart, handler: func(h *Handler) gin.HandlerFunc { return h.AddCartItemHandler }},
+		{name: "Remove from another user's cart", method: http.MethodDelete, route: "/carts/:id/items/:product_id", path: "/carts/" + otherId + "/items/" + otherId, buildStubs: otherCart, handler: func(h *Handler) gin.HandlerFunc { return h.RemoveCartItemHandler }},
+		{name: "Check out another user's cart", method: http.MethodPost, route: "/carts/:id/checkout", path: "/carts/" + otherId + "/checkout", buildStubs: func(mockDB *mockdb.MockQuerier) {
+			mockDB.EXPECT().GetCartByIDForUpdate(gomock.Any(), other).Return(db.Cart{ID: other, UserID: other, Status: CartStatusOpen}, nil).Times(1)
+		}, handler: func(h *Handler) gin.HandlerFunc { return h.CheckoutCartHandler }},
+		{name: "Reservation for another user", method: http.MethodPost, route: "/reservations", path: "/reservations", body: `{"user_id": "` + otherId + `", "product_id": "` + otherId + `", "quantity": 1}`, handler: func(h *Handler) gin.HandlerFunc { return h.CreateStockReservationHandler }},
+		{name: "Another user's reservation", method: http.MethodGet, route: "/reservations/:id", path: "/reservations/" + otherId, buildStubs: otherReservation, handler: func(h *Handler) gin.HandlerFunc { return h.GetStockReservationHandler }},
+		{name: "Confirm another user's reservation", method: http.MethodPost, route: "/reservations/:id/confirm", path: "/reservations/" + otherId + "/confirm", buildStubs: otherReservation, handler: func(h *Handler) gin.HandlerFunc { return h.ConfirmStockReservationHandler }},
+		{name: "Release another user's reservation", method: http.MethodPost, route: "/reservations/:id/release", path: "/reservations/" + otherId + "/release", buildStubs: otherReservation, handler: func(h *Handler) gin.HandlerFunc { return h.ReleaseStockReservationHandler }},
+		{name: "Another user's order", method: http.MethodGet, route: "/orders/:id", path: "/orders/" + otherId, buildStubs: otherOrder, handler: func(h *Handler) gin.HandlerFunc { return h.GetOrderDetailHandler }},
+		{name: "Cancel another user's order", method: http.MethodPost, route: "/orders/:id/cancel", path: "/orders/" + otherId + "/cancel", buildStubs: func(mockDB *mockdb.MockQuerier) {
+			mockDB.EXPECT().GetOrderByIDForUpdate(gomock.Any(), other).Return(db.Order{ID: other, UserID: other, Status: OrderStatusCompleted}, nil).Times(1)
+		}, handler: func(h *Handler) gin.HandlerFunc { return h.CancelOrderHandler }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only the lookup of the owner is expected: the request is refused
+			// before anything else.
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			if tt.buildStubs != nil {
+				tt.buildStubs(mockDB)
+			}
+
+			router := gin.New()
+			router.Use(withPrincipal(caller))
+			router.Handle(tt.method, tt.route, tt.handler(NewHandler(mockdb.NewFakeStore(mockDB), testKeys)))
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, http.StatusForbidden, recorder.Code)
+			require.JSONEq(t, `{"error":"You can only access your own account"}`, recorder.Body.String())
+		})
+	}
+}
@@ -2,17 +2,69 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/inventory"
+	"github.com/buranasakS/trading_application/pagination"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errProductQuantity   = errors.New("Quantity must be more than 0")
+	errProductPrice      = errors.New("Price must be more than 0")
+	errProductPriceScale = errors.New("Price must not have more than 2 decimal places")
+	errProductName       = errors.New("Name must not be empty")
+	errProductTags       = errors.New("A product can have at most 20 tags")
+	errProductTagLength  = errors.New("A tag must not be longer than 50 characters")
+)
+
+const (
+	maxProductTags      = 20
+	maxProductTagLength = 50
 )
 
 type RequestProduct struct{
-	Name     string  `json:"name" binding:"required"`
-	Quantity int32   `json:"quantity" binding:"required"`
-	Price    float64 `json:"price" binding:"required"`
+	Name     string          `json:"name" binding:"required"`
+	Quantity int32           `json:"quantity" binding:"required"`
+	Price    decimal.Decimal `json:"price"`
+	Category    string          `json:"category"`
+	Description string          `json:"description"`
+	Tags        []string        `json:"tags"`
+}
+
+// RequestUpdateProduct changes only the fields that are set. An empty
+// category removes the product from its category and an empty list of
+// tags removes all its tags.
+type RequestUpdateProduct struct {
+	Name        *string          `json:"name"`
+	Price       *decimal.Decimal `json:"price"`
+	Category    *string          `json:"category"`
+	Description *string          `json:"description"`
+	Tags        []string         `json:"tags"`
+}
+
+// ProductDetailResponse is a product with the unit price an order placed now
+// would pay. price_id is the scheduled price in force, or null when the list
+// price applies.
+type ProductDetailResponse struct {
+	db.Product
+	EffectivePrice decimal.Decimal `json:"effective_price"`
+	PriceID        pgtype.UUID     `json:"price_id"`
+}
+
+type RequestRestockProduct struct {
+	Quantity int32  `json:"quantity" binding:"required"`
+	Reason   string `json:"reason" binding:"max=255"`
 }
 
 // CreateProductHandler godoc
@@ -33,23 +85,50 @@ func (h *Handler) CreateProductHandler(c *gin.Context) {
 		return
 	}
 
-	if req.Quantity <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be more than 0"})
+	if err := validateProductQuantity(req.Quantity); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if req.Price <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Price must be more than 0"})
+	if err := validateProductPrice(req.Price); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	product, err := h.db.CreateProduct(context.Background(), db.CreateProductParams{
-		Name: req.Name,
-		Quantity: req.Quantity,
-		Price: req.Price,
+	tags, err := normalizeProductTags(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product db.Product
+	err = h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		var err error
+		product, err = qtx.CreateProduct(context.Background(), db.CreateProductParams{
+			Name: req.Name,
+			Quantity: req.Quantity,
+			Price: req.Price,
+			Category: pgtype.Text{String: req.Category, Valid: req.Category != ""},
+			Description: req.Description,
+			Tags: tags,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
+			return errResponded
+		}
+
+		if err := recordInventory(c, qtx, inventory.InitialStock(product.ID, product.Quantity)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record inventory movement"})
+			return errResponded
+		}
+
+		return nil
 	})
+	if errors.Is(err, errResponded) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
@@ -57,35 +136,89 @@ func (h *Handler) CreateProductHandler(c *gin.Context) {
 }
 
 // ListProductsHandler godoc
-// @Summary      List all products
-// @Description  List all products
+// @Summary      List products
+// @Description  List the products that are not archived, filtered and sorted, by page or by cursor
 // @Tags         Products
 // @Security BearerAuth
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  []db.Product "List of products"
+// @Param        limit      query   int     false  "Number of products per page (default 10, at most 100)"
+// @Param        page       query   int     false  "Page number (default 1)"
+// @Param        cursor     query   string  false  "next_cursor of the previous page, instead of page"
+// @Param        sort       query   string  false  "name or price, prefixed with - for descending order"
+// @Param        name       query   string  false  "Part of the product name"
+// @Param        min_price  query   number  false  "Lowest price in force"
+// @Param        max_price  query   number  false  "Highest price in force"
+// @Success      200  {object}  pagination.Page[db.ListProductsRow] "List of products"
 // @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
 // @Router       /products/list [get]
 func (h *Handler) ListProductsHandler(c *gin.Context) {
+	params, err := pagination.Parse(c.Request.URL.Query(), pagination.Text("name"), pagination.Number("price"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	minPrice, maxPrice, err := queryPriceRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	products, err := h.db.ListProducts(context.Background())
+	name := queryContains(c, "name")
+
+	// The price filters and the price sort use the price in force, the same
+	// for the page and the count.
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	products, err := h.db.ListProducts(context.Background(), db.ListProductsParams{
+		At:         now,
+		Name:       name,
+		MinPrice:   minPrice,
+		MaxPrice:   maxPrice,
+		AfterID:    params.AfterID(),
+		Sort:       params.SortKey(),
+		AfterValue: params.AfterValue(),
+		PageLimit:  params.FetchLimit(),
+		PageOffset: params.Offset(),
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list products"})
 		return
 	}
 
-	c.JSON(http.StatusOK, products)
+	totalCount, err := h.db.CountProducts(context.Background(), db.CountProductsParams{
+		At:       now,
+		Name:     name,
+		MinPrice: minPrice,
+		MaxPrice: maxPrice,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pagination.NewPage(params, products, totalCount, func(product db.ListProductsRow) pagination.Cursor {
+		cursor := pagination.Cursor{ID: product.ID}
+		switch params.Sort {
+		case "name":
+			cursor.Value = product.Name
+		case "price":
+			cursor.Value = product.EffectivePrice.String()
+		}
+		return cursor
+	}))
 }
 
 // GetProductByIDHandler godoc
 // @Summary      Get product details by ID
-// @Description  Retrieve product details by their unique ID
+// @Description  Retrieve product details by their unique ID, with the price an order placed now would pay
 // @Tags         Products
 // @Security BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        id   path      string  true  "Product ID (UUID)"
-// @Success      200  {object}  db.Product
+// @Success      200  {object}  ProductDetailResponse
 // @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
 // @Router       /products/{id} [get]
 func (h *Handler) GetProductDetailHandler(c *gin.Context) {
@@ -100,5 +233,285 @@ func (h *Handler) GetProductDetailHandler(c *gin.Context) {
 		return
 	}
 
+	effectivePrice, priceId, err := resolveProductPrice(context.Background(), h.db, product, pgtype.Timestamptz{Time: time.Now(), Valid: true})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product price"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProductDetailResponse{
+		Product:        product,
+		EffectivePrice: effectivePrice,
+		PriceID:        priceId,
+	})
+}
+
+// UpdateProductHandler godoc
+// @Summary      Update a product
+// @Description  Rename a product, change its price, category, description or tags. Only the fields sent are changed. A new price is recorded in the price history and is in force from now on. Stock is changed with the restock endpoint
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string                true  "Product ID (UUID)"
+// @Param        request  body  RequestUpdateProduct  true  "Fields to change"
+// @Success      200  {object}  db.Product
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id} [patch]
+func (h *Handler) UpdateProductHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req RequestUpdateProduct
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name == nil && req.Price == nil && req.Category == nil && req.Description == nil && req.Tags == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+
+	arg := db.UpdateProductParams{ID: productId}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errProductName.Error()})
+			return
+		}
+		arg.Name = pgtype.Text{String: *req.Name, Valid: true}
+	}
+
+	if req.Price != nil {
+		if err := validateProductPrice(*req.Price); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		arg.Price = decimal.NullDecimal{Decimal: *req.Price, Valid: true}
+	}
+
+	if req.Category != nil {
+		arg.Category = pgtype.Text{String: *req.Category, Valid: true}
+	}
+
+	if req.Description != nil {
+		arg.Description = pgtype.Text{String: *req.Description, Valid: true}
+	}
+
+	if req.Tags != nil {
+		tags, err := normalizeProductTags(req.Tags)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		arg.Tags = tags
+	}
+
+	var product db.Product
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		current, err := qtx.GetProductByIDForUpdate(context.Background(), productId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return errResponded
+		}
+
+		if current.ArchivedAt.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product is archived"})
+			return errResponded
+		}
+
+		product, err = qtx.UpdateProduct(context.Background(), arg)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+			return errResponded
+		}
+
+		// A new list price is kept in the price history and is in force from
+		// now on, like a scheduled price starting now: it ends a sale that
+		// is running, and prices scheduled to start later still apply.
+		if arg.Price.Valid && !arg.Price.Decimal.Equal(current.Price) {
+			_, err = qtx.CreateProductPrice(context.Background(), db.CreateProductPriceParams{
+				ProductID:     productId,
+				Price:         arg.Price.Decimal,
+				EffectiveFrom: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record price"})
+				return errResponded
+			}
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// ArchiveProductHandler godoc
+// @Summary      Archive a product
+// @Description  Retire a product so it can no longer be bought. The product is kept so past orders still resolve
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Product ID (UUID)"
+// @Success      200  {object}  db.Product
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id} [delete]
+func (h *Handler) ArchiveProductHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	product, err := h.db.GetProductByID(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	if product.ArchivedAt.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product is already archived"})
+		return
+	}
+
+	product, err = h.db.ArchiveProduct(context.Background(), productId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product is already archived"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// RestockProductHandler godoc
+// @Summary      Restock a product
+// @Description  Add stock to a product and record the inventory movement
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string                 true  "Product ID (UUID)"
+// @Param        request  body  RequestRestockProduct  true  "Quantity received"
+// @Success      200  {object}  db.Product
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/restock [post]
+func (h *Handler) RestockProductHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req RequestRestockProduct
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateProductQuantity(req.Quantity); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product db.Product
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		var err error
+		product, err = qtx.GetProductByIDForUpdate(context.Background(), productId)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return errResponded
+		}
+
+		if product.ArchivedAt.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product is archived"})
+			return errResponded
+		}
+
+		if _, err := qtx.AddProductQuantity(context.Background(), db.AddProductQuantityParams{
+			Quantity: req.Quantity,
+			ID:       productId,
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product quantity"})
+			return errResponded
+		}
+		product.Quantity += req.Quantity
+
+		if err := recordInventory(c, qtx, inventory.Restock(productId, req.Quantity, req.Reason)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record inventory movement"})
+			return errResponded
+		}
+
+		if !saveIdempotentResponse(c, qtx, http.StatusOK, product) {
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
 	c.JSON(http.StatusOK, product)
 }
+
+func validateProductQuantity(quantity int32) error {
+	if quantity <= 0 {
+		return errProductQuantity
+	}
+	return nil
+}
+
+// validateProductPrice checks that a price is positive and in whole cents.
+func validateProductPrice(price decimal.Decimal) error {
+	if !price.IsPositive() {
+		return errProductPrice
+	}
+	if !helpers.RoundMoney(price).Equal(price) {
+		return errProductPriceScale
+	}
+	return nil
+}
+
+// normalizeProductTags trims and lowercases the tags and drops empty and
+// repeated ones, so searching by tag does not depend on how it was typed.
+// The result is never nil, so a non-nil input always sets the tags.
+func normalizeProductTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxProductTagLength {
+			return nil, errProductTagLength
+		}
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxProductTags {
+		return nil, errProductTags
+	}
+
+	return normalized, nil
+}
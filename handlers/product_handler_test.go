@@ -2,18 +2,27 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	mockdb "github.com/buranasakS/trading_application/db/mocks"
 	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/inventory"
+	"github.com/buranasakS/trading_application/pagination"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,6 +39,7 @@ func TestCreateProductHandler(t *testing.T) {
 		reqBody        interface{}
 		mockReturnData db.Product
 		mockReturnErr  error
+		expectedTags   []string
 		expectedStatus int
 		expectedError  string
 	}{
@@ -38,24 +48,60 @@ func TestCreateProductHandler(t *testing.T) {
 			reqBody: RequestProduct{
 				Name:     "New Product",
 				Quantity: 10,
-				Price:    100.0,
+				Price:    decimal.NewFromInt(100),
 			},
 			mockReturnData: db.Product{
 				ID:       productId,
 				Name:     "New Product",
 				Quantity: 10,
-				Price:    100.0,
+				Price:    decimal.NewFromInt(100),
 			},
 			mockReturnErr:  nil,
+			expectedTags:   []string{},
 			expectedStatus: http.StatusCreated,
 			expectedError:  "",
 		},
+		{
+			name: "Tags are normalized",
+			reqBody: RequestProduct{
+				Name:        "Tagged Product",
+				Quantity:    10,
+				Price:       decimal.NewFromInt(100),
+				Description: "A product with tags",
+				Tags:        []string{" Sale ", "sale", "", "Gift"},
+			},
+			mockReturnData: db.Product{
+				ID:          productId,
+				Name:        "Tagged Product",
+				Quantity:    10,
+				Price:       decimal.NewFromInt(100),
+				Description: "A product with tags",
+				Tags:        []string{"sale", "gift"},
+			},
+			mockReturnErr:  nil,
+			expectedTags:   []string{"sale", "gift"},
+			expectedStatus: http.StatusCreated,
+			expectedError:  "",
+		},
+		{
+			name: "Tag too long",
+			reqBody: RequestProduct{
+				Name:     "Tagged Product",
+				Quantity: 10,
+				Price:    decimal.NewFromInt(100),
+				Tags:     []string{strings.Repeat("a", 51)},
+			},
+			mockReturnData: db.Product{},
+			mockReturnErr:  nil,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "A tag must not be longer than 50 characters",
+		},
 		{
 			name: "Invalid quantity (<= 0)",
 			reqBody: RequestProduct{
 				Name:     "Invalid Product",
 				Quantity: 0,
-				Price:    100.0,
+				Price:    decimal.NewFromInt(100),
 			},
 			mockReturnData: db.Product{},
 			mockReturnErr:  nil,
@@ -67,7 +113,7 @@ func TestCreateProductHandler(t *testing.T) {
 			reqBody: RequestProduct{
 				Name:     "Invalid Product",
 				Quantity: 10,
-				Price:    -5.0,
+				Price:    decimal.NewFromInt(-5),
 			},
 			mockReturnData: db.Product{},
 			mockReturnErr:  nil,
@@ -78,7 +124,7 @@ func TestCreateProductHandler(t *testing.T) {
 			name: "Missing Name field",
 			reqBody: RequestProduct{
 				Quantity: 10,
-				Price:    100.0,
+				Price:    decimal.NewFromInt(100),
 			},
 			mockReturnData: db.Product{},
 			mockReturnErr:  nil,
@@ -89,7 +135,7 @@ func TestCreateProductHandler(t *testing.T) {
 			name: "Missing Quantity field",
 			reqBody: RequestProduct{
 				Name:  "Product Without Quantity",
-				Price: 100.0,
+				Price: decimal.NewFromInt(100),
 			},
 			mockReturnData: db.Product{},
 			mockReturnErr:  nil,
@@ -105,7 +151,19 @@ func TestCreateProductHandler(t *testing.T) {
 			mockReturnData: db.Product{},
 			mockReturnErr:  nil,
 			expectedStatus: http.StatusBadRequest,
-			expectedError:  "Key: 'RequestProduct.Price' Error:Field validation for 'Price' failed on the 'required' tag",
+			expectedError:  "Price must be more than 0",
+		},
+		{
+			name: "Price with fractions of a cent",
+			reqBody: RequestProduct{
+				Name:     "Sub-cent Product",
+				Quantity: 10,
+				Price:    decimal.RequireFromString("9.999"),
+			},
+			mockReturnData: db.Product{},
+			mockReturnErr:  nil,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Price must not have more than 2 decimal places",
 		},
 	}
 
@@ -125,17 +183,27 @@ func TestCreateProductHandler(t *testing.T) {
 				mockDB.EXPECT().CreateProduct(
 					gomock.Any(),
 					db.CreateProductParams{
-						Name:     req.Name,
-						Quantity: req.Quantity,
-						Price:    req.Price,
+						Name:        req.Name,
+						Quantity:    req.Quantity,
+						Price:       req.Price,
+						Description: req.Description,
+						Tags:        tt.expectedTags,
 					},
 				).Return(tt.mockReturnData, tt.mockReturnErr).Times(1)
+				if tt.mockReturnErr == nil {
+					mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), db.CreateInventoryMovementParams{
+						ProductID: tt.mockReturnData.ID,
+						Kind:      inventory.KindInitialStock,
+						Quantity:  tt.mockReturnData.Quantity,
+						Reason:    pgtype.Text{String: "Initial stock", Valid: true},
+					}).Return(db.InventoryMovement{}, nil).Times(1)
+				}
 			}
 
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
 			router.POST("/products", func(c *gin.Context) {
-				NewHandler(mockDB).CreateProductHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).CreateProductHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(body))
@@ -164,38 +232,174 @@ func TestListProductsHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockDB := mockdb.NewMockQuerier(ctrl)
+	productId1 := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId2 := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	productId3 := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
 
-	products := []db.Product{
-		{ID: pgtype.UUID{}, Name: "Product 1", Quantity: 10, Price: 100},
-		{ID: pgtype.UUID{}, Name: "Product 2", Quantity: 5, Price: 50},
+	// Product 2 is on sale: the cursor holds the price in force.
+	products := []db.ListProductsRow{
+		{ID: productId1, Name: "Product 1", Quantity: 10, Price: decimal.NewFromInt(100), EffectivePrice: decimal.NewFromInt(100)},
+		{ID: productId2, Name: "Product 2", Quantity: 5, Price: decimal.NewFromInt(60), EffectivePrice: decimal.NewFromInt(50)},
+		{ID: productId3, Name: "Product 3", Quantity: 1, Price: decimal.NewFromInt(20), EffectivePrice: decimal.NewFromInt(20)},
 	}
+	priceCursor := pagination.Cursor{Sort: "-price", Value: "50", ID: productId2}.Encode()
 
-	mockDB.EXPECT().ListProducts(gomock.Any()).Return(products, nil).AnyTimes()
-
-	gin.SetMode(gin.ReleaseMode)
-	router := gin.New()
-
-	router.GET("/products/list", func(c *gin.Context) {
-		NewHandler(mockDB).ListProductsHandler(c)
-	})
+	tests := []struct {
+		name               string
+		query              string
+		buildStubs         func(mockDB *mockdb.MockQuerier)
+		expectedStatus     int
+		expectedError      string
+		expectedCount      int
+		expectedPage       int32
+		expectedNextCursor string
+	}{
+		{
+			name:  "Default page",
+			query: "",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().ListProducts(gomock.Any(), pricedNow(db.ListProductsParams{PageLimit: 11})).Return(products, nil).Times(1)
+				mockDB.EXPECT().CountProducts(gomock.Any(), pricedNow(db.CountProductsParams{})).Return(int64(3), nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  3,
+			expectedPage:   1,
+		},
+		{
+			name:  "Filtered and sorted with a next page",
+			query: "?limit=2&sort=-price&name=prod&min_price=10&max_price=200",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().ListProducts(gomock.Any(), pricedNow(db.ListProductsParams{
+					Name:      pgtype.Text{String: "prod", Valid: true},
+					MinPrice:  decimal.NewNullDecimal(decimal.NewFromInt(10)),
+					MaxPrice:  decimal.NewNullDecimal(decimal.NewFromInt(200)),
+					Sort:      "-price",
+					PageLimit: 3,
+				})).Return(products, nil).Times(1)
+				mockDB.EXPECT().CountProducts(gomock.Any(), gomock.Any()).Return(int64(3), nil).Times(1)
+			},
+			expectedStatus:     http.StatusOK,
+			expectedCount:      2,
+			expectedPage:       1,
+			expectedNextCursor: priceCursor,
+		},
+		{
+			name:  "Wildcards in the name match literally",
+			query: "?name=50%25_off",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().ListProducts(gomock.Any(), pricedNow(db.ListProductsParams{
+					Name:      pgtype.Text{String: `50\%\_off`, Valid: true},
+					PageLimit: 11,
+				})).Return(products, nil).Times(1)
+				mockDB.EXPECT().CountProducts(gomock.Any(), pricedNow(db.CountProductsParams{
+					Name: pgtype.Text{String: `50\%\_off`, Valid: true},
+				})).Return(int64(3), nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  3,
+			expectedPage:   1,
+		},
+		{
+			name:  "Next page by cursor",
+			query: "?limit=2&sort=-price&cursor=" + priceCursor,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().ListProducts(gomock.Any(), pricedNow(db.ListProductsParams{
+					AfterID:    productId2,
+					Sort:       "-price",
+					AfterValue: pgtype.Text{String: "50", Valid: true},
+					PageLimit:  3,
+				})).Return(products[2:], nil).Times(1)
+				mockDB.EXPECT().CountProducts(gomock.Any(), gomock.Any()).Return(int64(3), nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "Cursor made for another sort",
+			query:          "?sort=name&cursor=" + priceCursor,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid cursor value.",
+		},
+		{
+			name:           "Cursor with a tampered price",
+			query:          "?sort=price&cursor=" + pagination.Cursor{Sort: "price", Value: "x", ID: productId2}.Encode(),
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid cursor value.",
+		},
+		{
+			name:           "Page and cursor together",
+			query:          "?page=2&cursor=" + priceCursor,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Use either page or cursor, not both.",
+		},
+		{
+			name:           "Unknown sort field",
+			query:          "?sort=quantity",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid sort value.",
+		},
+		{
+			name:           "Invalid price",
+			query:          "?min_price=cheap",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid min_price value",
+		},
+		{
+			name:           "Inverted price range",
+			query:          "?min_price=100&max_price=10",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "min_price must not be more than max_price",
+		},
+		{
+			name:  "Count fails",
+			query: "",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().ListProducts(gomock.Any(), gomock.Any()).Return(products, nil).Times(1)
+				mockDB.EXPECT().CountProducts(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to count products",
+		},
+	}
 
-	req, err := http.NewRequest(http.MethodGet, "/products/list", nil)
-	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
 
-	recorder := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/products/list", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListProductsHandler)
 
-	router.ServeHTTP(recorder, req)
+			req, err := http.NewRequest(http.MethodGet, "/products/list"+tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
 
-	require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, tt.expectedStatus, recorder.Code)
 
-	var response []db.Product
-	err = json.Unmarshal(recorder.Body.Bytes(), &response)
-	require.NoError(t, err)
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
 
-	require.Equal(t, len(response), len(products))
-	require.Equal(t, response[0].ID, products[0].ID)
-	require.Equal(t, response[1].ID, products[1].ID)
+			var response pagination.Page[db.ListProductsRow]
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Len(t, response.Data, tt.expectedCount)
+			require.Equal(t, int32(tt.expectedCount), response.Count)
+			require.Equal(t, int32(3), response.TotalCount)
+			require.Equal(t, tt.expectedPage, response.Page)
+			require.Equal(t, tt.expectedNextCursor, response.NextCursor)
+		})
+	}
 }
 
 func TestProductDetailHandler(t *testing.T) {
@@ -210,7 +414,12 @@ func TestProductDetailHandler(t *testing.T) {
 		ID:       productId,
 		Name:     "Product 1",
 		Quantity: 10,
-		Price:    100.0,
+		Price:    decimal.NewFromInt(100),
+	}
+	salePrice := db.ProductPrice{
+		ID:        helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002"),
+		ProductID: productId,
+		Price:     decimal.NewFromInt(80),
 	}
 
 	tests := []struct {
@@ -218,6 +427,8 @@ func TestProductDetailHandler(t *testing.T) {
 		paramID        string
 		mockReturnData db.Product
 		mockReturnErr  error
+		priceInForce   db.ProductPrice
+		priceErr       error
 		expectedStatus int
 		expectedBody   interface{}
 	}{
@@ -226,8 +437,25 @@ func TestProductDetailHandler(t *testing.T) {
 			paramID:        productId.String(),
 			mockReturnData: product,
 			mockReturnErr:  nil,
+			priceErr:       pgx.ErrNoRows,
+			expectedStatus: http.StatusOK,
+			expectedBody:   ProductDetailResponse{Product: product, EffectivePrice: product.Price},
+		},
+		{
+			name:           "Scheduled price in force",
+			paramID:        productId.String(),
+			mockReturnData: product,
+			priceInForce:   salePrice,
 			expectedStatus: http.StatusOK,
-			expectedBody:   product,
+			expectedBody:   ProductDetailResponse{Product: product, EffectivePrice: salePrice.Price, PriceID: salePrice.ID},
+		},
+		{
+			name:           "Price lookup fails",
+			paramID:        productId.String(),
+			mockReturnData: product,
+			priceErr:       errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   map[string]string{"error": "Failed to get product price"},
 		},
 		{
 			name:           "Product Not Found",
@@ -255,11 +483,14 @@ func TestProductDetailHandler(t *testing.T) {
 				require.NoError(t, err)
 				mockDB.EXPECT().GetProductByID(gomock.Any(), mockProductId).Return(tt.mockReturnData, tt.mockReturnErr).Times(1)
 			}
+			if tt.mockReturnErr == nil && tt.expectedStatus != http.StatusBadRequest {
+				mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(tt.priceInForce, tt.priceErr).Times(1)
+			}
 
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
 			router.GET("/products/:id", func(c *gin.Context) {
-				NewHandler(mockDB).GetProductDetailHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetProductDetailHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodGet, "/products/"+tt.paramID, nil)
@@ -270,10 +501,10 @@ func TestProductDetailHandler(t *testing.T) {
 			require.Equal(t, tt.expectedStatus, recorder.Code)
 
 			if tt.expectedStatus == http.StatusOK {
-				var response db.Product
+				var response ProductDetailResponse
 				err := json.Unmarshal(recorder.Body.Bytes(), &response)
 				require.NoError(t, err)
-				require.Equal(t, tt.mockReturnData, response)
+				require.Equal(t, tt.expectedBody, response)
 			} else {
 				var response map[string]string
 				err := json.Unmarshal(recorder.Body.Bytes(), &response)
@@ -283,3 +514,373 @@ func TestProductDetailHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	product := db.Product{ID: productId, Name: "Product", Quantity: 10, Price: decimal.NewFromInt(100)}
+	archived := product
+	archived.ArchivedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	tests := []struct {
+		name           string
+		reqBody        string
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:    "Success",
+			reqBody: `{"name": "Renamed", "price": "12.50", "category": ""}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().UpdateProduct(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateProductParams) (db.Product, error) {
+						require.Equal(t, pgtype.Text{String: "Renamed", Valid: true}, arg.Name)
+						require.True(t, arg.Price.Valid)
+						require.True(t, decimal.RequireFromString("12.50").Equal(arg.Price.Decimal))
+						require.Equal(t, pgtype.Text{String: "", Valid: true}, arg.Category)
+						return db.Product{ID: productId, Name: "Renamed", Price: arg.Price.Decimal}, nil
+					}).Times(1)
+				mockDB.EXPECT().CreateProductPrice(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.CreateProductPriceParams) (db.ProductPrice, error) {
+						require.Equal(t, productId, arg.ProductID)
+						require.True(t, decimal.RequireFromString("12.50").Equal(arg.Price))
+						require.WithinDuration(t, time.Now(), arg.EffectiveFrom.Time, time.Minute)
+						require.False(t, arg.EffectiveTo.Valid)
+						return db.ProductPrice{}, nil
+					}).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "Unchanged price",
+			reqBody: `{"price": "100"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().UpdateProduct(gomock.Any(), gomock.Any()).Return(product, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "Recording the price fails",
+			reqBody: `{"price": "80"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().UpdateProduct(gomock.Any(), gomock.Any()).Return(product, nil).Times(1)
+				mockDB.EXPECT().CreateProductPrice(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to record price",
+		},
+		{
+			name:    "Only the name",
+			reqBody: `{"name": "Renamed"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().UpdateProduct(gomock.Any(), db.UpdateProductParams{
+					Name: pgtype.Text{String: "Renamed", Valid: true},
+					ID:   productId,
+				}).Return(product, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "Description and cleared tags",
+			reqBody: `{"description": "Now with more", "tags": []}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().UpdateProduct(gomock.Any(), db.UpdateProductParams{
+					Description: pgtype.Text{String: "Now with more", Valid: true},
+					Tags:        []string{},
+					ID:          productId,
+				}).Return(product, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Too many tags",
+			reqBody:        `{"tags": ["1","2","3","4","5","6","7","8","9","10","11","12","13","14","15","16","17","18","19","20","21"]}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "A product can have at most 20 tags",
+		},
+		{
+			name:           "Nothing to update",
+			reqBody:        `{}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Nothing to update",
+		},
+		{
+			name:           "Empty name",
+			reqBody:        `{"name": " "}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Name must not be empty",
+		},
+		{
+			name:           "Negative price",
+			reqBody:        `{"price": "-1"}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Price must be more than 0",
+		},
+		{
+			name:           "Price with fractions of a cent",
+			reqBody:        `{"price": "9.999"}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Price must not have more than 2 decimal places",
+		},
+		{
+			name:    "Product not found",
+			reqBody: `{"name": "Renamed"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(db.Product{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product not found",
+		},
+		{
+			name:    "Archived product",
+			reqBody: `{"name": "Renamed"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(archived, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product is archived",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.PATCH("/products/:id", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).UpdateProductHandler)
+
+			req, err := http.NewRequest(http.MethodPatch, "/products/"+productId.String(), bytes.NewBufferString(tt.reqBody))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestArchiveProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	product := db.Product{ID: productId, Name: "Product", Quantity: 10, Price: decimal.NewFromInt(100)}
+	archived := product
+	archived.ArchivedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	tests := []struct {
+		name           string
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Success",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().ArchiveProduct(gomock.Any(), productId).Return(archived, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Already archived",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(archived, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product is already archived",
+		},
+		{
+			name: "Archived by a concurrent request",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().ArchiveProduct(gomock.Any(), productId).Return(db.Product{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product is already archived",
+		},
+		{
+			name: "Product not found",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.DELETE("/products/:id", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ArchiveProductHandler)
+
+			req, err := http.NewRequest(http.MethodDelete, "/products/"+productId.String(), nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+			} else {
+				var response db.Product
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.True(t, response.ArchivedAt.Valid)
+			}
+		})
+	}
+}
+
+func TestRestockProductHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	product := db.Product{ID: productId, Name: "Product", Quantity: 10, Price: decimal.NewFromInt(100)}
+	archived := product
+	archived.ArchivedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	failure := errors.New("db error")
+
+	tests := []struct {
+		name             string
+		reqBody          string
+		buildStubs       func(mockDB *mockdb.MockQuerier)
+		expectedStatus   int
+		expectedError    string
+		expectedQuantity int32
+		expectedCommits  int
+	}{
+		{
+			name:    "Success",
+			reqBody: `{"quantity": 5, "reason": "Supplier delivery"}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().AddProductQuantity(gomock.Any(), db.AddProductQuantityParams{Quantity: 5, ID: productId}).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), db.CreateInventoryMovementParams{
+					ProductID: productId,
+					Kind:      inventory.KindRestock,
+					Quantity:  5,
+					Reason:    pgtype.Text{String: "Supplier delivery", Valid: true},
+				}).Return(db.InventoryMovement{}, nil).Times(1)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedQuantity: 15,
+			expectedCommits:  1,
+		},
+		{
+			name:           "Invalid quantity",
+			reqBody:        `{"quantity": -5}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Quantity must be more than 0",
+		},
+		{
+			name:    "Archived product",
+			reqBody: `{"quantity": 5}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(archived, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product is archived",
+		},
+		{
+			name:    "Movement fails",
+			reqBody: `{"quantity": 5}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().AddProductQuantity(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, failure).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to record inventory movement",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+			store := mockdb.NewFakeStore(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/products/:id/restock", NewHandler(store, testKeys).RestockProductHandler)
+
+			req, err := http.NewRequest(http.MethodPost, "/products/"+productId.String()+"/restock", bytes.NewBufferString(tt.reqBody))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Equal(t, tt.expectedCommits, store.Commits)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+			} else {
+				var response db.Product
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedQuantity, response.Quantity)
+			}
+		})
+	}
+}
+
+// pricedNow matches the arguments of a query that resolves prices in force:
+// At has to be about now and the other fields have to equal want's.
+func pricedNow(want interface{}) gomock.Matcher {
+	return pricedNowMatcher{want: want}
+}
+
+type pricedNowMatcher struct {
+	want interface{}
+}
+
+func (m pricedNowMatcher) Matches(x interface{}) bool {
+	if reflect.TypeOf(x) != reflect.TypeOf(m.want) {
+		return false
+	}
+
+	arg := reflect.New(reflect.TypeOf(x)).Elem()
+	arg.Set(reflect.ValueOf(x))
+	at, ok := arg.FieldByName("At").Interface().(pgtype.Timestamptz)
+	if !ok || !at.Valid || time.Since(at.Time).Abs() > time.Minute {
+		return false
+	}
+	arg.FieldByName("At").Set(reflect.ValueOf(pgtype.Timestamptz{}))
+
+	return reflect.DeepEqual(arg.Interface(), m.want)
+}
+
+func (m pricedNowMatcher) String() string {
+	return fmt.Sprintf("is equal to %v priced now", m.want)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// RequestProductPrice schedules a price for a product. Without effective_to
+// the price stays in force until a later scheduled price starts.
+type RequestProductPrice struct {
+	Price         decimal.Decimal `json:"price"`
+	EffectiveFrom time.Time       `json:"effective_from" binding:"required"`
+	EffectiveTo   *time.Time      `json:"effective_to"`
+}
+
+// ProductPricesResponse is the list price of a product with its scheduled
+// prices and the price that applies now.
+type ProductPricesResponse struct {
+	ProductID      pgtype.UUID       `json:"product_id"`
+	ListPrice      decimal.Decimal   `json:"list_price"`
+	EffectivePrice decimal.Decimal   `json:"effective_price"`
+	Prices         []db.ProductPrice `json:"prices"`
+}
+
+// resolveProductPrice returns the unit price of a product at the given time
+// and the scheduled price it comes from. Without a scheduled price in force
+// the list price applies and the ID is NULL.
+func resolveProductPrice(ctx context.Context, q db.Querier, product db.Product, at pgtype.Timestamptz) (decimal.Decimal, pgtype.UUID, error) {
+	price, err := q.GetProductPriceInForce(ctx, db.GetProductPriceInForceParams{
+		ProductID: product.ID,
+		At:        at,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return product.Price, pgtype.UUID{}, nil
+	}
+	if err != nil {
+		return decimal.Decimal{}, pgtype.UUID{}, err
+	}
+
+	return price.Price, price.ID, nil
+}
+
+// ScheduleProductPriceHandler godoc
+// @Summary      Schedule a product price
+// @Description  Schedule a price that replaces the list price of a product during its window, for a sale or a planned price change. When windows overlap, the price with the latest effective_from applies
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string               true  "Product ID (UUID)"
+// @Param        request  body  RequestProductPrice  true  "Price and window"
+// @Success      201  {object}  db.ProductPrice
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/prices [post]
+func (h *Handler) ScheduleProductPriceHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req RequestProductPrice
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateProductPrice(req.Price); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.EffectiveFrom.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from must not be in the past"})
+		return
+	}
+
+	effectiveTo := pgtype.Timestamptz{}
+	if req.EffectiveTo != nil {
+		if !req.EffectiveTo.After(req.EffectiveFrom) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_to must be after effective_from"})
+			return
+		}
+		effectiveTo = pgtype.Timestamptz{Time: *req.EffectiveTo, Valid: true}
+	}
+
+	product, err := h.db.GetProductByID(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	if product.ArchivedAt.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Product is archived"})
+		return
+	}
+
+	price, err := h.db.CreateProductPrice(context.Background(), db.CreateProductPriceParams{
+		ProductID:     productId,
+		Price:         req.Price,
+		EffectiveFrom: pgtype.Timestamptz{Time: req.EffectiveFrom, Valid: true},
+		EffectiveTo:   effectiveTo,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule price"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, price)
+}
+
+// ListProductPricesHandler godoc
+// @Summary      List the prices of a product
+// @Description  List the scheduled prices and past price changes of a product, latest effective first, with its list price and the price that applies now
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Product ID (UUID)"
+// @Success      200  {object}  ProductPricesResponse
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/prices [get]
+func (h *Handler) ListProductPricesHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	product, err := h.db.GetProductByID(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	prices, err := h.db.ListProductPrices(context.Background(), productId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list prices"})
+		return
+	}
+
+	effectivePrice, _, err := resolveProductPrice(context.Background(), h.db, product, pgtype.Timestamptz{Time: time.Now(), Valid: true})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product price"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProductPricesResponse{
+		ProductID:      product.ID,
+		ListPrice:      product.Price,
+		EffectivePrice: effectivePrice,
+		Prices:         prices,
+	})
+}
+
+// CancelProductPriceHandler godoc
+// @Summary      Cancel a scheduled price
+// @Description  Delete a scheduled price that has not come into force yet
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id        path  string  true  "Product ID (UUID)"
+// @Param        price_id  path  string  true  "Price ID (UUID)"
+// @Success      200  {object}  map[string]string  "Price cancelled"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/{id}/prices/{price_id} [delete]
+func (h *Handler) CancelProductPriceHandler(c *gin.Context) {
+	var productId pgtype.UUID
+	if err := productId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var priceId pgtype.UUID
+	if err := priceId.Scan(c.Param("price_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price ID"})
+		return
+	}
+
+	price, err := h.db.GetProductPriceByID(context.Background(), priceId)
+	if err != nil || price.ProductID != productId {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Price not found"})
+		return
+	}
+
+	result, err := h.db.DeleteProductPrice(context.Background(), db.DeleteProductPriceParams{
+		ID:        priceId,
+		ProductID: productId,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel price"})
+		return
+	}
+
+	if result == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Price is already in force"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price cancelled"})
+}
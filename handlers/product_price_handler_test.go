@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleProductPriceHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	priceId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	product := db.Product{ID: productId, Name: "Product", Quantity: 10, Price: decimal.NewFromInt(100)}
+	archived := product
+	archived.ArchivedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	effectiveFrom := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	effectiveTo := effectiveFrom.Add(7 * 24 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		reqBody        interface{}
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Sale scheduled",
+			reqBody: RequestProductPrice{
+				Price:         decimal.RequireFromString("79.90"),
+				EffectiveFrom: effectiveFrom,
+				EffectiveTo:   &effectiveTo,
+			},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().CreateProductPrice(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.CreateProductPriceParams) (db.ProductPrice, error) {
+						require.Equal(t, productId, arg.ProductID)
+						require.True(t, decimal.RequireFromString("79.90").Equal(arg.Price))
+						require.True(t, effectiveFrom.Equal(arg.EffectiveFrom.Time))
+						require.True(t, effectiveTo.Equal(arg.EffectiveTo.Time))
+						return db.ProductPrice{ID: priceId, ProductID: productId, Price: arg.Price, EffectiveFrom: arg.EffectiveFrom, EffectiveTo: arg.EffectiveTo}, nil
+					}).Times(1)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Starts in the past",
+			reqBody:        RequestProductPrice{Price: decimal.NewFromInt(80), EffectiveFrom: past},
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "effective_from must not be in the past",
+		},
+		{
+			name:           "Window ends before it starts",
+			reqBody:        RequestProductPrice{Price: decimal.NewFromInt(80), EffectiveFrom: effectiveTo, EffectiveTo: &effectiveFrom},
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "effective_to must be after effective_from",
+		},
+		{
+			name:           "Price with fractions of a cent",
+			reqBody:        RequestProductPrice{Price: decimal.RequireFromString("0.999"), EffectiveFrom: effectiveFrom},
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Price must not have more than 2 decimal places",
+		},
+		{
+			name:    "Archived product",
+			reqBody: RequestProductPrice{Price: decimal.NewFromInt(80), EffectiveFrom: effectiveFrom},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(archived, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product is archived",
+		},
+		{
+			name:    "Database error",
+			reqBody: RequestProductPrice{Price: decimal.NewFromInt(80), EffectiveFrom: effectiveFrom},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().CreateProductPrice(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to schedule price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/products/:id/prices", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ScheduleProductPriceHandler)
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/products/"+productId.String()+"/prices", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response db.ProductPrice
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, priceId, response.ID)
+		})
+	}
+}
+
+func TestListProductPricesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	product := db.Product{ID: productId, Name: "Product", Quantity: 10, Price: decimal.NewFromInt(100)}
+	prices := []db.ProductPrice{
+		{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001"), ProductID: productId, Price: decimal.NewFromInt(90)},
+		{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002"), ProductID: productId, Price: decimal.NewFromInt(80)},
+	}
+
+	tests := []struct {
+		name              string
+		buildStubs        func(mockDB *mockdb.MockQuerier)
+		expectedStatus    int
+		expectedError     string
+		expectedEffective decimal.Decimal
+	}{
+		{
+			name: "Sale in force",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().ListProductPrices(gomock.Any(), productId).Return(prices, nil).Times(1)
+				mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(prices[1], nil).Times(1)
+			},
+			expectedStatus:    http.StatusOK,
+			expectedEffective: decimal.NewFromInt(80),
+		},
+		{
+			name: "List price applies",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().ListProductPrices(gomock.Any(), productId).Return(prices, nil).Times(1)
+				mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus:    http.StatusOK,
+			expectedEffective: decimal.NewFromInt(100),
+		},
+		{
+			name: "Product not found",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(db.Product{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Product not found",
+		},
+		{
+			name: "Price lookup fails",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().ListProductPrices(gomock.Any(), productId).Return(prices, nil).Times(1)
+				mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to get product price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/products/:id/prices", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListProductPricesHandler)
+
+			req, err := http.NewRequest(http.MethodGet, "/products/"+productId.String()+"/prices", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response ProductPricesResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.True(t, product.Price.Equal(response.ListPrice))
+			require.True(t, tt.expectedEffective.Equal(response.EffectivePrice))
+			require.Len(t, response.Prices, len(prices))
+		})
+	}
+}
+
+func TestCancelProductPriceHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	otherProductId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	priceId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	tests := []struct {
+		name           string
+		paramID        string
+		mockPrice      db.ProductPrice
+		mockPriceErr   error
+		mockDeleteRows int64
+		expectDelete   bool
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "Success", paramID: priceId.String(), mockPrice: db.ProductPrice{ID: priceId, ProductID: productId}, mockDeleteRows: 1, expectDelete: true, expectedStatus: http.StatusOK},
+		{name: "Already in force", paramID: priceId.String(), mockPrice: db.ProductPrice{ID: priceId, ProductID: productId}, expectDelete: true, expectedStatus: http.StatusConflict, expectedError: "Price is already in force"},
+		{name: "Price of another product", paramID: priceId.String(), mockPrice: db.ProductPrice{ID: priceId, ProductID: otherProductId}, expectedStatus: http.StatusNotFound, expectedError: "Price not found"},
+		{name: "Not Found", paramID: priceId.String(), mockPriceErr: pgx.ErrNoRows, expectedStatus: http.StatusNotFound, expectedError: "Price not found"},
+		{name: "Invalid ID", paramID: "invalid-uuid", expectedStatus: http.StatusBadRequest, expectedError: "Invalid price ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.paramID == priceId.String() {
+				mockDB.EXPECT().GetProductPriceByID(gomock.Any(), priceId).Return(tt.mockPrice, tt.mockPriceErr).Times(1)
+			}
+			if tt.expectDelete {
+				mockDB.EXPECT().DeleteProductPrice(gomock.Any(), db.DeleteProductPriceParams{ID: priceId, ProductID: productId}).Return(tt.mockDeleteRows, nil).Times(1)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.DELETE("/products/:id/prices/:price_id", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).CancelProductPriceHandler)
+
+			req, err := http.NewRequest(http.MethodDelete, "/products/"+productId.String()+"/prices/"+tt.paramID, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			var response map[string]string
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			if tt.expectedError != "" {
+				require.Equal(t, tt.expectedError, response["error"])
+			} else {
+				require.Equal(t, "Price cancelled", response["message"])
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CategoryFacet is the number of search matches in a category. Category is
+// empty for the products without one.
+type CategoryFacet struct {
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+}
+
+// ProductSearchResponse is a page of search matches with the match count of
+// every category.
+type ProductSearchResponse struct {
+	pagination.Page[db.SearchProductsRow]
+	Facets []CategoryFacet `json:"facets"`
+}
+
+// SearchProductsHandler godoc
+// @Summary      Search products
+// @Description  Full-text search over the name and description of the products that are not archived, best matches first, with the number of matches per category
+// @Tags         Products
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        q          query   string   false  "Search words. Supports quoted phrases, or and -word"
+// @Param        category   query   string   false  "Category of the products"
+// @Param        tag        query   string   false  "Tag of the products"
+// @Param        min_price  query   number   false  "Lowest price in force"
+// @Param        max_price  query   number   false  "Highest price in force"
+// @Param        in_stock   query   boolean  false  "Only products with stock that is not reserved"
+// @Param        limit      query   int      false  "Number of products per page (default 10, at most 100)"
+// @Param        page       query   int      false  "Page number (default 1)"
+// @Success      200  {object}  ProductSearchResponse
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /products/search [get]
+func (h *Handler) SearchProductsHandler(c *gin.Context) {
+	params, err := pagination.ParseOffset(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	minPrice, maxPrice, err := queryPriceRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inStock, err := queryBool(c, "in_stock", errInvalidInStock)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := pgtype.Text{String: strings.TrimSpace(c.Query("q"))}
+	query.Valid = query.String != ""
+	category := queryText(c, "category")
+	tag := pgtype.Text{String: strings.ToLower(strings.TrimSpace(c.Query("tag")))}
+	tag.Valid = tag.String != ""
+
+	// The price filters use the price in force, the same for every query.
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	products, err := h.db.SearchProducts(context.Background(), db.SearchProductsParams{
+		Query:      query,
+		At:         now,
+		Category:   category,
+		Tag:        tag,
+		MinPrice:   minPrice,
+		MaxPrice:   maxPrice,
+		InStock:    inStock,
+		PageLimit:  params.Limit,
+		PageOffset: params.Offset(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search products"})
+		return
+	}
+
+	totalCount, err := h.db.CountSearchProducts(context.Background(), db.CountSearchProductsParams{
+		At:       now,
+		Query:    query,
+		Category: category,
+		Tag:      tag,
+		MinPrice: minPrice,
+		MaxPrice: maxPrice,
+		InStock:  inStock,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count products"})
+		return
+	}
+
+	facetRows, err := h.db.ListProductCategoryFacets(context.Background(), db.ListProductCategoryFacetsParams{
+		At:       now,
+		Query:    query,
+		Tag:      tag,
+		MinPrice: minPrice,
+		MaxPrice: maxPrice,
+		InStock:  inStock,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count categories"})
+		return
+	}
+
+	facets := make([]CategoryFacet, 0, len(facetRows))
+	for _, row := range facetRows {
+		facets = append(facets, CategoryFacet{Category: row.Category.String, Count: row.Count})
+	}
+
+	if products == nil {
+		products = []db.SearchProductsRow{}
+	}
+
+	c.JSON(http.StatusOK, ProductSearchResponse{
+		Page: pagination.Page[db.SearchProductsRow]{
+			Page:       params.Page,
+			TotalPage:  pagination.TotalPages(totalCount, params.Limit),
+			Count:      int32(len(products)),
+			TotalCount: int32(totalCount),
+			Data:       products,
+		},
+		Facets: facets,
+	})
+}
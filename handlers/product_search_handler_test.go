@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchProductsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	products := []db.SearchProductsRow{
+		{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000"), Name: "Red shoe", Quantity: 3, Price: decimal.NewFromInt(40), Category: pgtype.Text{String: "shoes", Valid: true}, Rank: 0.6},
+		{ID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001"), Name: "Shoe polish", Quantity: 8, Price: decimal.NewFromInt(5), Rank: 0.3},
+	}
+	facets := []db.ListProductCategoryFacetsRow{
+		{Category: pgtype.Text{String: "shoes", Valid: true}, Count: 4},
+		{Count: 1},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+		expectedFacets []CategoryFacet
+	}{
+		{
+			name:  "Search with filters",
+			query: "?q=+shoe+&category=shoes&tag=Sale&min_price=1&max_price=50&in_stock=true&limit=2",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				query := pgtype.Text{String: "shoe", Valid: true}
+				tag := pgtype.Text{String: "sale", Valid: true}
+				minPrice := decimal.NewNullDecimal(decimal.NewFromInt(1))
+				maxPrice := decimal.NewNullDecimal(decimal.NewFromInt(50))
+				mockDB.EXPECT().SearchProducts(gomock.Any(), pricedNow(db.SearchProductsParams{
+					Query:     query,
+					Category:  pgtype.Text{String: "shoes", Valid: true},
+					Tag:       tag,
+					MinPrice:  minPrice,
+					MaxPrice:  maxPrice,
+					InStock:   true,
+					PageLimit: 2,
+				})).Return(products, nil).Times(1)
+				mockDB.EXPECT().CountSearchProducts(gomock.Any(), pricedNow(db.CountSearchProductsParams{
+					Query:    query,
+					Category: pgtype.Text{String: "shoes", Valid: true},
+					Tag:      tag,
+					MinPrice: minPrice,
+					MaxPrice: maxPrice,
+					InStock:  true,
+				})).Return(int64(4), nil).Times(1)
+				mockDB.EXPECT().ListProductCategoryFacets(gomock.Any(), pricedNow(db.ListProductCategoryFacetsParams{
+					Query:    query,
+					Tag:      tag,
+					MinPrice: minPrice,
+					MaxPrice: maxPrice,
+					InStock:  true,
+				})).Return(facets, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedFacets: []CategoryFacet{{Category: "shoes", Count: 4}, {Category: "", Count: 1}},
+		},
+		{
+			name:           "Invalid in_stock",
+			query:          "?in_stock=maybe",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid in_stock value",
+		},
+		{
+			name:           "Inverted price range",
+			query:          "?min_price=10&max_price=1",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "min_price must not be more than max_price",
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=0",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid limit value. Must be a positive integer.",
+		},
+		{
+			name:  "Search fails",
+			query: "?q=shoe",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().SearchProducts(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to search products",
+		},
+		{
+			name:  "Facets fail",
+			query: "?q=shoe",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().SearchProducts(gomock.Any(), gomock.Any()).Return(products, nil).Times(1)
+				mockDB.EXPECT().CountSearchProducts(gomock.Any(), gomock.Any()).Return(int64(2), nil).Times(1)
+				mockDB.EXPECT().ListProductCategoryFacets(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to count categories",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/products/search", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).SearchProductsHandler)
+
+			req, err := http.NewRequest(http.MethodGet, "/products/search"+tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response ProductSearchResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Len(t, response.Data, len(products))
+			require.Equal(t, products[0].Name, response.Data[0].Name)
+			require.Equal(t, int32(4), response.TotalCount)
+			require.Equal(t, int32(2), response.TotalPage)
+			require.Equal(t, tt.expectedFacets, response.Facets)
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusConfirmed = "confirmed"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+)
+
+const (
+	defaultReservationTTL = 15 * time.Minute
+	maxReservationTTL     = time.Hour
+)
+
+// RequestStockReservation holds stock for a user, the caller when UserID is
+// left out. TTLSeconds defaults to 15 minutes and may be at most an hour.
+type RequestStockReservation struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	ProductID  pgtype.UUID `json:"product_id"`
+	Quantity   int32       `json:"quantity"`
+	TTLSeconds int32       `json:"ttl_seconds"`
+}
+
+// CreateStockReservationHandler godoc
+// @Summary      Reserve stock
+// @Description  Hold stock of a product for a user without selling it. The reservation expires after its TTL
+// @Tags         Reservations
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body    RequestStockReservation true "Reservation detail"
+// @Success      201  {object}   db.StockReservation
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /reservations [post]
+func (h *Handler) CreateStockReservationHandler(c *gin.Context) {
+	var req RequestStockReservation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Quantity <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be more than 0"})
+		return
+	}
+
+	ttl := defaultReservationTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl <= 0 || ttl > maxReservationTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "TTL must be between 1 and 3600 seconds"})
+		return
+	}
+
+	principal, ok := requirePrincipal(c)
+	if !ok {
+		return
+	}
+
+	if !req.UserID.Valid {
+		req.UserID = principal.UserID
+	}
+
+	if !authorizeUser(c, req.UserID) {
+		return
+	}
+
+	if _, err := h.db.GetUserDetailByID(context.Background(), req.UserID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+		return
+	}
+
+	var reservation db.StockReservation
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		// The product lock serializes reservations and orders of the product,
+		// so the available stock cannot be handed out twice.
+		product, err := qtx.GetProductByIDForUpdate(context.Background(), req.ProductID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product not found"})
+			return errResponded
+		}
+
+		if product.ArchivedAt.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product is no longer available"})
+			return errResponded
+		}
+
+		reserved, err := qtx.GetReservedProductQuantity(context.Background(), db.GetReservedProductQuantityParams{
+			ProductID: req.ProductID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reserved quantity"})
+			return errResponded
+		}
+
+		if product.Quantity-reserved < req.Quantity {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough product in stock"})
+			return errResponded
+		}
+
+		reservation, err = qtx.CreateStockReservation(context.Background(), db.CreateStockReservationParams{
+			ProductID: req.ProductID,
+			UserID:    req.UserID,
+			Quantity:  req.Quantity,
+			ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reservation"})
+			return errResponded
+		}
+
+		if !saveIdempotentResponse(c, qtx, http.StatusCreated, reservation) {
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reservation)
+}
+
+// GetStockReservationHandler godoc
+// @Summary      Get a reservation
+// @Description  Get a stock reservation by ID
+// @Tags         Reservations
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Reservation ID (UUID)"
+// @Success      200  {object}  db.StockReservation
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /reservations/{id} [get]
+func (h *Handler) GetStockReservationHandler(c *gin.Context) {
+	var reservationId pgtype.UUID
+	if err := reservationId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation ID"})
+		return
+	}
+
+	reservation, err := h.db.GetStockReservationByID(context.Background(), reservationId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	if !authorizeUser(c, reservation.UserID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+// ConfirmStockReservationHandler godoc
+// @Summary      Confirm a reservation
+// @Description  Buy the reserved stock for the reservation's user. Works like ordering with a reservation_id
+// @Tags         Reservations
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Reservation ID (UUID)"
+// @Success      201  {object}  OrderResponse  "Order completed"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /reservations/{id}/confirm [post]
+func (h *Handler) ConfirmStockReservationHandler(c *gin.Context) {
+	var reservationId pgtype.UUID
+	if err := reservationId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation ID"})
+		return
+	}
+
+	reservation, err := h.db.GetStockReservationByID(context.Background(), reservationId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	if !authorizeUser(c, reservation.UserID) {
+		return
+	}
+
+	h.placeOrder(c, OrderRequest{
+		UserID:        reservation.UserID,
+		ReservationID: reservation.ID,
+	})
+}
+
+// ReleaseStockReservationHandler godoc
+// @Summary      Release a reservation
+// @Description  Give the reserved stock back before the reservation expires
+// @Tags         Reservations
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Reservation ID (UUID)"
+// @Success      200  {object}  db.StockReservation
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /reservations/{id}/release [post]
+func (h *Handler) ReleaseStockReservationHandler(c *gin.Context) {
+	var reservationId pgtype.UUID
+	if err := reservationId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reservation ID"})
+		return
+	}
+
+	reservation, err := h.db.GetStockReservationByID(context.Background(), reservationId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	if !authorizeUser(c, reservation.UserID) {
+		return
+	}
+
+	if reservation.Status != ReservationStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reservation is no longer active"})
+		return
+	}
+
+	reservation, err = h.db.ReleaseStockReservation(context.Background(), reservationId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reservation is no longer active"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release reservation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
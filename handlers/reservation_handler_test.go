@@ -0,0 +1,480 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateStockReservationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	reservationId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	product := db.Product{ID: productId, Quantity: 10, Price: decimal.NewFromInt(100)}
+
+	expectReservation := func(mockDB *mockdb.MockQuerier, ttl time.Duration) {
+		mockDB.EXPECT().CreateStockReservation(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, arg db.CreateStockReservationParams) (db.StockReservation, error) {
+				require.Equal(t, productId, arg.ProductID)
+				require.Equal(t, userId, arg.UserID)
+				require.Equal(t, int32(4), arg.Quantity)
+				require.WithinDuration(t, time.Now().Add(ttl), arg.ExpiresAt.Time, time.Minute)
+				return db.StockReservation{
+					ID:        reservationId,
+					ProductID: arg.ProductID,
+					UserID:    arg.UserID,
+					Quantity:  arg.Quantity,
+					Status:    ReservationStatusActive,
+					ExpiresAt: arg.ExpiresAt,
+				}, nil
+			}).Times(1)
+	}
+
+	tests := []struct {
+		name            string
+		reqBody         string
+		buildStubs      func(mockDB *mockdb.MockQuerier)
+		expectedStatus  int
+		expectedError   string
+		expectedCommits int
+	}{
+		{
+			name:    "Success with the default TTL",
+			reqBody: `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId}, nil).Times(1)
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), db.GetReservedProductQuantityParams{ProductID: productId}).Return(int32(6), nil).Times(1)
+				expectReservation(mockDB, defaultReservationTTL)
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedCommits: 1,
+		},
+		{
+			name:    "Success with a custom TTL",
+			reqBody: `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4, "ttl_seconds": 120}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId}, nil).Times(1)
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), gomock.Any()).Return(int32(0), nil).Times(1)
+				expectReservation(mockDB, 2*time.Minute)
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedCommits: 1,
+		},
+		{
+			name:           "Zero quantity",
+			reqBody:        `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 0}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Quantity must be more than 0",
+		},
+		{
+			name:           "TTL too long",
+			reqBody:        `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4, "ttl_seconds": 7200}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "TTL must be between 1 and 3600 seconds",
+		},
+		{
+			name:           "Negative TTL",
+			reqBody:        `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4, "ttl_seconds": -1}`,
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "TTL must be between 1 and 3600 seconds",
+		},
+		{
+			name:    "User not found",
+			reqBody: `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "User not found",
+		},
+		{
+			name:    "Product not found",
+			reqBody: `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId}, nil).Times(1)
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(db.Product{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product not found",
+		},
+		{
+			name:    "Archived product",
+			reqBody: `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				archived := product
+				archived.ArchivedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId}, nil).Times(1)
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(archived, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Product is no longer available",
+		},
+		{
+			name:    "Stock held by other reservations",
+			reqBody: `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId}, nil).Times(1)
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), gomock.Any()).Return(int32(7), nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Not enough product in stock",
+		},
+		{
+			name:    "Create reservation fails",
+			reqBody: `{"user_id": "123e4567-e89b-12d3-a456-426614174000", "product_id": "123e4567-e89b-12d3-a456-426614174001", "quantity": 4}`,
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId}, nil).Times(1)
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), gomock.Any()).Return(int32(0), nil).Times(1)
+				mockDB.EXPECT().CreateStockReservation(gomock.Any(), gomock.Any()).Return(db.StockReservation{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to create reservation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+			store := mockdb.NewFakeStore(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/reservations", NewHandler(store, testKeys).CreateStockReservationHandler)
+
+			req, err := http.NewRequest(http.MethodPost, "/reservations", bytes.NewBufferString(tt.reqBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Equal(t, tt.expectedCommits, store.Commits)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response db.StockReservation
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, reservationId, response.ID)
+			require.Equal(t, ReservationStatusActive, response.Status)
+		})
+	}
+}
+
+func TestGetStockReservationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reservationId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+
+	tests := []struct {
+		name           string
+		id             string
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Success",
+			id:   reservationId.String(),
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(db.StockReservation{ID: reservationId, Status: ReservationStatusActive}, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Not found",
+			id:   reservationId.String(),
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(db.StockReservation{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Reservation not found",
+		},
+		{
+			name:           "Invalid ID",
+			id:             "invalid-uuid",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid reservation ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.GET("/reservations/:id", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetStockReservationHandler)
+
+			req, err := http.NewRequest(http.MethodGet, "/reservations/"+tt.id, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectedError != "" {
+				require.Contains(t, recorder.Body.String(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestConfirmStockReservationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	reservationId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+
+	user := db.GetUserDetailByIDForUpdateRow{ID: userId, Balance: decimal.NewFromInt(1000)}
+	product := db.Product{ID: productId, Quantity: 3, Price: decimal.NewFromInt(100)}
+	reservation := db.StockReservation{
+		ID:        reservationId,
+		ProductID: productId,
+		UserID:    userId,
+		Quantity:  3,
+		Status:    ReservationStatusActive,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+
+	expectLocks := func(mockDB *mockdb.MockQuerier, reservation db.StockReservation) {
+		mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(reservation, nil).Times(1)
+		mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(user, nil).Times(1)
+		mockDB.EXPECT().GetStockReservationByIDForUpdate(gomock.Any(), reservationId).Return(reservation, nil).Times(1)
+	}
+
+	expectOrder := func(mockDB *mockdb.MockQuerier) {
+		mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+		// The reservation's own stock is not counted against it, so all three
+		// units can be bought even though they are reserved.
+		mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), db.GetReservedProductQuantityParams{
+			ProductID: productId,
+			ExcludeID: reservationId,
+		}).Return(int32(0), nil).Times(1)
+		mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, pgx.ErrNoRows).Times(1)
+		mockDB.EXPECT().DeductUserBalance(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+		mockDB.EXPECT().DeductProductQuantity(gomock.Any(), db.DeductProductQuantityParams{Quantity: 3, ID: productId}).Return(int64(1), nil).Times(1)
+		mockDB.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(db.Order{ID: orderId, UserID: userId}, nil).Times(1)
+	}
+
+	tests := []struct {
+		name            string
+		buildStubs      func(mockDB *mockdb.MockQuerier)
+		expectedStatus  int
+		expectedError   string
+		expectedCommits int
+	}{
+		{
+			name: "Success",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				expectLocks(mockDB, reservation)
+				expectOrder(mockDB)
+				mockDB.EXPECT().ConfirmStockReservation(gomock.Any(), db.ConfirmStockReservationParams{OrderID: orderId, ID: reservationId}).Return(int64(1), nil).Times(1)
+				mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+				mockDB.EXPECT().CreateOrderItem(gomock.Any(), gomock.Any()).Return(db.OrderItem{}, nil).Times(1)
+				mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, nil).Times(1)
+			},
+			expectedStatus:  http.StatusCreated,
+			expectedCommits: 1,
+		},
+		{
+			name: "Reservation not found",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(db.StockReservation{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Reservation not found",
+		},
+		{
+			name: "Reservation expired",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				expired := reservation
+				expired.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Second), Valid: true}
+				expectLocks(mockDB, expired)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Reservation has expired",
+		},
+		{
+			name: "Reservation already confirmed",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				confirmed := reservation
+				confirmed.Status = ReservationStatusConfirmed
+				expectLocks(mockDB, confirmed)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Reservation is no longer active",
+		},
+		{
+			name: "Confirm reservation fails",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				expectLocks(mockDB, reservation)
+				expectOrder(mockDB)
+				mockDB.EXPECT().ConfirmStockReservation(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to confirm reservation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+			store := mockdb.NewFakeStore(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/reservations/:id/confirm", NewHandler(store, testKeys).ConfirmStockReservationHandler)
+
+			req, err := http.NewRequest(http.MethodPost, "/reservations/"+reservationId.String()+"/confirm", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Equal(t, tt.expectedCommits, store.Commits)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response OrderResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.True(t, decimal.NewFromInt(300).Equal(response.TotalCost))
+		})
+	}
+}
+
+func TestReleaseStockReservationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reservationId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	active := db.StockReservation{ID: reservationId, Quantity: 3, Status: ReservationStatusActive}
+
+	tests := []struct {
+		name           string
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Success",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				released := active
+				released.Status = ReservationStatusReleased
+				mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(active, nil).Times(1)
+				mockDB.EXPECT().ReleaseStockReservation(gomock.Any(), reservationId).Return(released, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Reservation not found",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(db.StockReservation{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Reservation not found",
+		},
+		{
+			name: "Reservation already expired",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				expired := active
+				expired.Status = ReservationStatusExpired
+				mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(expired, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Reservation is no longer active",
+		},
+		{
+			name: "Reservation confirmed concurrently",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(active, nil).Times(1)
+				mockDB.EXPECT().ReleaseStockReservation(gomock.Any(), reservationId).Return(db.StockReservation{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Reservation is no longer active",
+		},
+		{
+			name: "Release fails",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetStockReservationByID(gomock.Any(), reservationId).Return(active, nil).Times(1)
+				mockDB.EXPECT().ReleaseStockReservation(gomock.Any(), reservationId).Return(db.StockReservation{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to release reservation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/reservations/:id/release", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ReleaseStockReservationHandler)
+
+			req, err := http.NewRequest(http.MethodPost, "/reservations/"+reservationId.String()+"/release", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response db.StockReservation
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, ReservationStatusReleased, response.Status)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/ledger"
+	"github.com/buranasakS/trading_application/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+const transactionDateLayout = "2006-01-02"
+
+var transactionTypes = map[string]bool{
+	ledger.KindOpeningBalance:     true,
+	ledger.KindTopUp:              true,
+	ledger.KindDeduction:          true,
+	ledger.KindPurchase:           true,
+	ledger.KindRefund:             true,
+	ledger.KindCommission:         true,
+	ledger.KindCommissionReversal: true,
+}
+
+type BalanceTransaction struct {
+	ID             pgtype.UUID        `json:"id"`
+	TransactionID  pgtype.UUID        `json:"transaction_id"`
+	Type           string             `json:"type"`
+	Amount         decimal.Decimal    `json:"amount"`
+	RunningBalance decimal.Decimal    `json:"running_balance"`
+	Reason         string             `json:"reason"`
+	OrderID        pgtype.UUID        `json:"order_id"`
+	CommissionID   pgtype.UUID        `json:"commission_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type ResponseBalanceTransactions struct {
+	Page       int32                `json:"page"`
+	TotalPage  int32                `json:"total_page"`
+	Count      int32                `json:"count"`
+	TotalCount int32                `json:"total_count"`
+	Data       []BalanceTransaction `json:"data"`
+}
+
+// ListUserTransactionsHandler godoc
+// @Summary      List balance transactions of a user
+// @Description  Fetch a paginated list of balance changes of a user, newest first, with the balance after each change
+// @Tags         Users
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path    string  true   "User ID (UUID)"
+// @Param        type   query   string  false  "Transaction type (top_up, deduction, purchase, refund, opening_balance)"
+// @Param        from   query   string  false  "Earliest date, YYYY-MM-DD or RFC 3339"
+// @Param        to     query   string  false  "Latest date, YYYY-MM-DD (inclusive) or RFC 3339 (exclusive)"
+// @Param        limit  query   int     false  "Number of transactions per page (default 10)"
+// @Param        page   query   int     false  "Page number (default 1)"
+// @Success      200  {object}  ResponseBalanceTransactions
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
+// @Router       /users/{id}/transactions [get]
+func (h *Handler) ListUserTransactionsHandler(c *gin.Context) {
+	var userId pgtype.UUID
+	if err := userId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+		return
+	}
+
+	if !authorizeUser(c, userId) {
+		return
+	}
+
+	if _, err := h.db.GetUserDetailByID(context.Background(), userId); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	h.listAccountTransactions(c, ledger.AccountUser, userId)
+}
+
+// ListAffiliateTransactionsHandler godoc
+// @Summary      List balance transactions of an affiliate
+// @Description  Fetch a paginated list of balance changes of an affiliate, newest first, with the balance after each change
+// @Tags         Affiliates
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id     path    string  true   "Affiliate ID (UUID)"
+// @Param        type   query   string  false  "Transaction type (commission, commission_reversal, opening_balance)"
+// @Param        from   query   string  false  "Earliest date, YYYY-MM-DD or RFC 3339"
+// @Param        to     query   string  false  "Latest date, YYYY-MM-DD (inclusive) or RFC 3339 (exclusive)"
+// @Param        limit  query   int     false  "Number of transactions per page (default 10)"
+// @Param        page   query   int     false  "Page number (default 1)"
+// @Success      200  {object}  ResponseBalanceTransactions
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Router       /affiliates/{id}/transactions [get]
+func (h *Handler) ListAffiliateTransactionsHandler(c *gin.Context) {
+	var affiliateId pgtype.UUID
+	if err := affiliateId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid affiliate ID"})
+		return
+	}
+
+	if _, err := h.db.GetAffiliateByID(context.Background(), affiliateId); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Affiliate not found"})
+		return
+	}
+
+	h.listAccountTransactions(c, ledger.AccountAffiliate, affiliateId)
+}
+
+// listAccountTransactions writes the filtered page of ledger postings of one
+// account holder.
+func (h *Handler) listAccountTransactions(c *gin.Context, account string, accountId pgtype.UUID) {
+	params, err := pagination.ParseOffset(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var kind pgtype.Text
+	if t := c.Query("type"); t != "" {
+		if !transactionTypes[t] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction type"})
+			return
+		}
+		kind = pgtype.Text{String: t, Valid: true}
+	}
+
+	from, err := parseTransactionTime(c.Query("from"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+		return
+	}
+
+	to, err := parseTransactionTime(c.Query("to"), true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+		return
+	}
+
+	if from.Valid && to.Valid && !from.Time.Before(to.Time) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	rows, err := h.db.ListAccountTransactions(context.Background(), db.ListAccountTransactionsParams{
+		Account:    account,
+		AccountID:  accountId,
+		Kind:       kind,
+		FromTime:   from,
+		ToTime:     to,
+		PageLimit:  params.Limit,
+		PageOffset: params.Offset(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+		return
+	}
+
+	totalCount, err := h.db.CountAccountTransactions(context.Background(), db.CountAccountTransactionsParams{
+		Account:   account,
+		AccountID: accountId,
+		Kind:      kind,
+		FromTime:  from,
+		ToTime:    to,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count transactions"})
+		return
+	}
+
+	transactions := make([]BalanceTransaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, BalanceTransaction{
+			ID:             row.ID,
+			TransactionID:  row.TransactionID,
+			Type:           row.Kind,
+			Amount:         row.Amount,
+			RunningBalance: row.RunningBalance,
+			Reason:         row.Reason.String,
+			OrderID:        row.OrderID,
+			CommissionID:   row.CommissionID,
+			CreatedAt:      row.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, ResponseBalanceTransactions{
+		Page:       params.Page,
+		TotalPage:  pagination.TotalPages(totalCount, params.Limit),
+		Count:      int32(len(transactions)),
+		TotalCount: int32(totalCount),
+		Data:       transactions,
+	})
+}
+
+// parseTransactionTime accepts a date or an RFC 3339 timestamp. A date used as
+// the upper bound covers the whole day.
+func parseTransactionTime(value string, upper bool) (pgtype.Timestamptz, error) {
+	if value == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return pgtype.Timestamptz{Time: t, Valid: true}, nil
+	}
+
+	t, err := time.Parse(transactionDateLayout, value)
+	if err != nil {
+		return pgtype.Timestamptz{}, err
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
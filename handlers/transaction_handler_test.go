@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/ledger"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListUserTransactionsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	rows := []db.ListAccountTransactionsRow{
+		{
+			Kind:           ledger.KindPurchase,
+			Amount:         decimal.RequireFromString("-30.00"),
+			RunningBalance: decimal.RequireFromString("70.00"),
+			Reason:         pgtype.Text{String: "Order purchase", Valid: true},
+			OrderID:        orderId,
+		},
+		{
+			Kind:           ledger.KindTopUp,
+			Amount:         decimal.RequireFromString("100.00"),
+			RunningBalance: decimal.RequireFromString("100.00"),
+			Reason:         pgtype.Text{String: "Balance top-up", Valid: true},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		userErr        error
+		expectList     bool
+		expectedParams db.ListAccountTransactionsParams
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:       "Success",
+			query:      "?limit=2&page=2",
+			expectList: true,
+			expectedParams: db.ListAccountTransactionsParams{
+				Account:    ledger.AccountUser,
+				AccountID:  userId,
+				PageLimit:  2,
+				PageOffset: 2,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "Filtered by type and date range",
+			query:      "?type=purchase&from=2026-01-01&to=2026-01-31",
+			expectList: true,
+			expectedParams: db.ListAccountTransactionsParams{
+				Account:    ledger.AccountUser,
+				AccountID:  userId,
+				Kind:       pgtype.Text{String: ledger.KindPurchase, Valid: true},
+				FromTime:   pgtype.Timestamptz{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+				ToTime:     pgtype.Timestamptz{Time: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+				PageLimit:  10,
+				PageOffset: 0,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "User not found",
+			userErr:        errors.New("no rows in result set"),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "User not found",
+		},
+		{
+			name:           "Invalid type",
+			query:          "?type=gift",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid transaction type",
+		},
+		{
+			name:           "Invalid date",
+			query:          "?from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid from date",
+		},
+		{
+			name:           "Empty date range",
+			query:          "?from=2026-02-01&to=2026-01-01",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "from must be before to",
+		},
+		{
+			name:           "Invalid page",
+			query:          "?page=0",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid page value. Must be a positive integer.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId}, tt.userErr).Times(1)
+
+			if tt.expectList {
+				mockDB.EXPECT().ListAccountTransactions(gomock.Any(), tt.expectedParams).Return(rows, nil).Times(1)
+				mockDB.EXPECT().CountAccountTransactions(gomock.Any(), db.CountAccountTransactionsParams{
+					Account:   tt.expectedParams.Account,
+					AccountID: tt.expectedParams.AccountID,
+					Kind:      tt.expectedParams.Kind,
+					FromTime:  tt.expectedParams.FromTime,
+					ToTime:    tt.expectedParams.ToTime,
+				}).Return(int64(4), nil).Times(1)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.GET("/users/:id/transactions", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListUserTransactionsHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/users/"+userId.String()+"/transactions"+tt.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response ResponseBalanceTransactions
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, int32(4), response.TotalCount)
+				require.Equal(t, int32(2), response.Count)
+				require.Len(t, response.Data, 2)
+				require.Equal(t, ledger.KindPurchase, response.Data[0].Type)
+				require.Equal(t, "Order purchase", response.Data[0].Reason)
+				require.Equal(t, orderId, response.Data[0].OrderID)
+				require.True(t, decimal.RequireFromString("70").Equal(response.Data[0].RunningBalance))
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestListAffiliateTransactionsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+
+	affiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	commissionId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+
+	tests := []struct {
+		name           string
+		affiliateErr   error
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "Success",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Affiliate not found",
+			affiliateErr:   errors.New("no rows in result set"),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Affiliate not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB.EXPECT().GetAffiliateByID(gomock.Any(), affiliateId).Return(db.Affiliate{ID: affiliateId}, tt.affiliateErr).Times(1)
+
+			if tt.affiliateErr == nil {
+				mockDB.EXPECT().ListAccountTransactions(gomock.Any(), db.ListAccountTransactionsParams{
+					Account:   ledger.AccountAffiliate,
+					AccountID: affiliateId,
+					PageLimit: 10,
+				}).Return([]db.ListAccountTransactionsRow{
+					{
+						Kind:           ledger.KindCommission,
+						Amount:         decimal.RequireFromString("5.00"),
+						RunningBalance: decimal.RequireFromString("5.00"),
+						Reason:         pgtype.Text{String: "Commission earned", Valid: true},
+						CommissionID:   commissionId,
+					},
+				}, nil).Times(1)
+				mockDB.EXPECT().CountAccountTransactions(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/affiliates/:id/transactions", func(c *gin.Context) {
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListAffiliateTransactionsHandler(c)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/affiliates/"+affiliateId.String()+"/transactions", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response ResponseBalanceTransactions
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response.Data, 1)
+				require.Equal(t, commissionId, response.Data[0].CommissionID)
+				require.Equal(t, int32(1), response.TotalPage)
+			} else {
+				var response map[string]string
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
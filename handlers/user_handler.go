@@ -2,18 +2,21 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"math"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
-	"github.com/buranasakS/trading_application/config"
+	"github.com/buranasakS/trading_application/auth"
 	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/ledger"
+	"github.com/buranasakS/trading_application/pagination"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -27,32 +30,38 @@ type RequestUserRegister struct {
 	Password    string      `json:"password" binding:"required"`
 	AffiliateID pgtype.UUID `json:"affiliate_id" binding:"required"`
 }
-type ResponseUser struct {
-	Page       int32   `json:"page"`
-	TotalPage  int32   `json:"total_page"`
-	Count      int32   `json:"count"`
-	TotalCount int32   `json:"total_count"`
-	Data       []Users `json:"data"`
-}
+type ResponseUser = pagination.Page[Users]
+
 type Users struct {
-	ID          pgtype.UUID `json:"id"`
-	Username    string      `json:"username"`
-	Balance     float64     `json:"balance"`
-	AffiliateID pgtype.UUID `json:"affiliate_id"`
+	ID          pgtype.UUID     `json:"id"`
+	Username    string          `json:"username"`
+	Balance     decimal.Decimal `json:"balance"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
+}
+
+type RequestUserRole struct {
+	Role string `json:"role" binding:"required"`
 }
 
 type RequestAmount struct {
-	Amount float64 `json:"amount" binding:"required"`
+	Amount decimal.Decimal `json:"amount"`
+	Reason string          `json:"reason" binding:"max=255"`
 }
 
-// RegisterUserHandler godoc
-// @Summary      register a new user
-// @Description  register a new user
+// unknownUserHash is compared against when the username does not exist, so
+// that a login for it takes as long as one with a wrong password.
+const unknownUserHash = "$2a$10$VwCSHWjr8p3uBJF8hI.AI.740f7JIPmVNuKTv3vIDBbx4pyWPEYES"
+
+// LoginUserHandler godoc
+// @Summary      log in
+// @Description  log in with username and password and start a session. The access token expires after 15 minutes and is renewed with the refresh token. Repeated failures for a username or from a client delay further attempts and then lock them out for a while
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        request body      RequestUserLogin true "User details"
-// @Success      201  {object}  db.User "User created successfully"
+// @Success      200  {object}  TokenResponse
+// @Failure 400 {object} handlers.ErrorResponse "Invalid username or password"
+// @Failure 429 {object} handlers.ErrorResponse "Too many failed login attempts"
 // @Router       /login [post]
 func (h *Handler) LoginUserHandler(c *gin.Context) {
 	var req RequestUserLogin
@@ -61,40 +70,108 @@ func (h *Handler) LoginUserHandler(c *gin.Context) {
 		return
 	}
 
-	user, err := h.db.GetUserByUsernameForLogin(context.Background(), req.Username)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username or password"})
+	now := time.Now()
+	// Forwarding headers are only believed from TRUSTED_PROXIES, so the
+	// client cannot pick the IP its failures are counted against.
+	clientIP := c.ClientIP()
+
+	var tokens TokenResponse
+	var retryAfter time.Duration
+	invalid := false
+
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		// Attempts on the username or from the client IP wait here for each
+		// other, so parallel guesses are checked and counted one at a time
+		// instead of all passing the check before any is counted.
+		failures, err := qtx.LockLoginFailures(context.Background(), db.LockLoginFailuresParams{
+			Username: req.Username,
+			ClientIP: clientIP,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+			return errResponded
+		}
+
+		var retryAt time.Time
+		for _, failure := range failures {
+			at := auth.LoginLockoutFor(failure.Scope).RetryAt(int(failure.Failures), failure.LastFailureAt.Time)
+			if at.After(retryAt) {
+				retryAt = at
+			}
+		}
+		if retryAt.After(now) {
+			retryAfter = retryAt.Sub(now)
+			return nil
+		}
+
+		// Unknown usernames and wrong passwords get the same answer, in the
+		// same time, so the answer does not tell which usernames exist.
+		user, err := qtx.GetUserByUsernameForLogin(context.Background(), req.Username)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+			return errResponded
+		}
+		found := err == nil
+		hash := unknownUserHash
+		if found {
+			hash = user.Password
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)); err != nil || !found {
+			if err := qtx.RecordLoginFailure(context.Background(), db.RecordLoginFailureParams{
+				WindowStart: pgtype.Timestamptz{Time: now.Add(-auth.LoginFailureWindow), Valid: true},
+				FailedAt:    pgtype.Timestamptz{Time: now, Valid: true},
+				Username:    req.Username,
+				ClientIP:    clientIP,
+			}); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record login attempt"})
+				return errResponded
+			}
+			invalid = true
+			return nil
+		}
+
+		// The failures of the client IP are kept: one account the client
+		// knows the password of must not reset its guessing at others.
+		if _, err := qtx.DeleteUsernameLoginFailures(context.Background(), req.Username); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset login attempts"})
+			return errResponded
+		}
+
+		session, err := qtx.CreateAuthSession(context.Background(), user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+			return errResponded
+		}
+
+		tokens, err = issueTokens(context.Background(), qtx, h.keys, session.ID, user.ID, user.Username, user.Role, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
 		return
 	}
-
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
-	secretKey := os.Getenv("SECRET_KEY")
-	if secretKey == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Missing token key"})
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
 		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub":      user.ID,
-		"exp":      time.Now().Add(time.Hour * 72).Unix(),
-		"IssuedAt": time.Now().Unix(),
-		"username": user.Username,
-	})
-
-	tokenString, err := token.SignedString([]byte(secretKey))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "Failed to generate token"})
+	if invalid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid username or password"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
-	})
+	c.JSON(http.StatusOK, tokens)
 }
 
 // RegisterUserHandler godoc
@@ -148,52 +225,60 @@ func (h *Handler) RegisterUserHandler(c *gin.Context) {
 }
 
 // ListUsersHandler godoc
-// @Summary      List all users with pagination
-// @Description  Fetch a paginated list of users from the database
+// @Summary      List users with pagination
+// @Description  Fetch a filtered and sorted list of users, by page or by cursor
 // @Tags         Users
 // @Security BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        limit  query   int  false  "Number of users per page (default 10)"
-// @Param        page   query   int  false  "Page number (default 1)"
+// @Param        limit         query   int     false  "Number of users per page (default 10, at most 100)"
+// @Param        page          query   int     false  "Page number (default 1)"
+// @Param        cursor        query   string  false  "next_cursor of the previous page, instead of page"
+// @Param        sort          query   string  false  "username or balance, prefixed with - for descending order"
+// @Param        username      query   string  false  "Part of the username"
+// @Param        affiliate_id  query   string  false  "Affiliate ID (UUID)"
 // @Success      200  {object}  ResponseUser
 // @Failure 401 {object} handlers.ErrorResponse
 // @Router       /users/all [get]
 func (h *Handler) ListUsersHandler(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "10")
-	pageStr := c.DefaultQuery("page", "1")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit value. Must be a positive integer."})
+	params, err := pagination.Parse(c.Request.URL.Query(), pagination.Text("username"), pagination.Number("balance"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page value. Must be a positive integer."})
+	affiliateId, err := queryUUID(c, "affiliate_id", errInvalidAffiliateID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if limit < 1 {
-		limit = 10
-	}
-	if page < 1 {
-		page = 1
-	}
-
-	offset := (page - 1) * limit
+	username := queryContains(c, "username")
 
 	userRows, err := h.db.ListUsers(context.Background(), db.ListUsersParams{
-		Limit:  int32(limit),
-		Offset: int32(offset),
+		Username:    username,
+		AffiliateID: affiliateId,
+		AfterID:     params.AfterID(),
+		Sort:        params.SortKey(),
+		AfterValue:  params.AfterValue(),
+		PageLimit:   params.FetchLimit(),
+		PageOffset:  params.Offset(),
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
 
-	var users []Users
+	totalCount, err := h.db.CountUsers(context.Background(), db.CountUsersParams{
+		Username:    username,
+		AffiliateID: affiliateId,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
+		return
+	}
+
+	users := make([]Users, 0, len(userRows))
 	for _, userRow := range userRows {
 		users = append(users, Users{
 			ID:          userRow.ID,
@@ -203,16 +288,16 @@ func (h *Handler) ListUsersHandler(c *gin.Context) {
 		})
 	}
 
-	totalCount := len(users)
-	totalPage := (int32(totalCount) + int32(limit) - 1) / int32(limit)
-
-	c.JSON(http.StatusOK, ResponseUser{
-		Page:       int32(page),
-		TotalPage:  totalPage,
-		Count:      int32(len(users)),
-		TotalCount: int32(totalCount),
-		Data:       users,
-	})
+	c.JSON(http.StatusOK, pagination.NewPage(params, users, totalCount, func(user Users) pagination.Cursor {
+		cursor := pagination.Cursor{ID: user.ID}
+		switch params.Sort {
+		case "username":
+			cursor.Value = user.Username
+		case "balance":
+			cursor.Value = user.Balance.String()
+		}
+		return cursor
+	}))
 }
 
 // GetUserDetailByIDHandler godoc
@@ -225,6 +310,7 @@ func (h *Handler) ListUsersHandler(c *gin.Context) {
 // @Param        id   path      string  true  "User ID (UUID)"
 // @Success      200  {object}  db.User
 // @Failure 401 {object} handlers.ErrorResponse
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
 // @Router       /users/{id} [get]
 func (h *Handler) GetUserDetailHandler(c *gin.Context) {
 	var userId pgtype.UUID
@@ -233,6 +319,10 @@ func (h *Handler) GetUserDetailHandler(c *gin.Context) {
 		return
 	}
 
+	if !authorizeUser(c, userId) {
+		return
+	}
+
 	user, err := h.db.GetUserDetailByID(context.Background(), userId)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
@@ -253,6 +343,7 @@ func (h *Handler) GetUserDetailHandler(c *gin.Context) {
 // @Param request body RequestAmount true "Amount to deduct"
 // @Success      200  {object}  map[string]string "Balance deducted successfully"
 // @Failure 401 {object} handlers.ErrorResponse
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
 // @Router /users/deduct/balance/{id} [patch]
 func (h *Handler) DeductUserBalanceHandler(c *gin.Context) {
 	var userId pgtype.UUID
@@ -261,6 +352,10 @@ func (h *Handler) DeductUserBalanceHandler(c *gin.Context) {
 		return
 	}
 
+	if !authorizeUser(c, userId) {
+		return
+	}
+
 	_, err := h.db.GetUserDetailByID(context.Background(), userId)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
@@ -273,45 +368,58 @@ func (h *Handler) DeductUserBalanceHandler(c *gin.Context) {
 		return
 	}
 
-	if req.Amount <= 0 {
+	if !req.Amount.IsPositive() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be more than 0"})
 		return
 	}
 
-	tx, err := config.ConnectDatabase().DB.BeginTx(context.Background(), pgx.TxOptions{})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+	if !helpers.RoundMoney(req.Amount).Equal(req.Amount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must not have more than 2 decimal places"})
 		return
 	}
 
-	defer tx.Rollback(context.Background())
-
-	var qtx db.Querier = h.db
-	if queriesDB, ok := h.db.(*db.Queries); ok {
-		qtx = queriesDB.WithTx(tx)
-	}
-
-	result, err := qtx.DeductUserBalance(context.Background(), db.DeductUserBalanceParams{
-		Balance: req.Amount,
-		ID:      userId,
+	response := gin.H{"message": "Deduct balance completed"}
+	err = h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		result, err := qtx.DeductUserBalance(context.Background(), db.DeductUserBalanceParams{
+			Balance: req.Amount,
+			ID:      userId,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"Error": "Failed to deduct balance"})
+			return errResponded
+		}
+
+		if result == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
+			return errResponded
+		}
+
+		entry := ledger.Deduction(userId, req.Amount)
+		if req.Reason != "" {
+			entry.Reason = req.Reason
+		}
+
+		err = ledger.Record(context.Background(), qtx, entry)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record ledger entry"})
+			return errResponded
+		}
+
+		if !saveIdempotentResponse(c, qtx, http.StatusOK, response) {
+			return errResponded
+		}
+
+		return nil
 	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "Failed to deduct balance"})
-		return
-	}
-
-	if result == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
+	if errors.Is(err, errResponded) {
 		return
 	}
-
-	err = tx.Commit(context.Background())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Deduct balance completed"})
+	c.JSON(http.StatusOK, response)
 }
 
 // AddUserBalanceHandler godoc
@@ -325,6 +433,7 @@ func (h *Handler) DeductUserBalanceHandler(c *gin.Context) {
 // @Param request body RequestAmount true "Amount to add"
 // @Success      200  {object}  map[string]string "Balance added successfully"
 // @Failure 401 {object} handlers.ErrorResponse
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
 // @Router /users/add/balance/{id} [patch]
 func (h *Handler) AddUserBalanceHandler(c *gin.Context) {
 	var userId pgtype.UUID
@@ -333,6 +442,10 @@ func (h *Handler) AddUserBalanceHandler(c *gin.Context) {
 		return
 	}
 
+	if !authorizeUser(c, userId) {
+		return
+	}
+
 	_, err := h.db.GetUserDetailByID(context.Background(), userId)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
@@ -345,43 +458,160 @@ func (h *Handler) AddUserBalanceHandler(c *gin.Context) {
 		return
 	}
 
-	if req.Amount <= 0 {
+	if !req.Amount.IsPositive() {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be more than 0"})
 		return
 	}
 
-	tx, err := config.ConnectDatabase().DB.BeginTx(context.Background(), pgx.TxOptions{})
+	if !helpers.RoundMoney(req.Amount).Equal(req.Amount) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must not have more than 2 decimal places"})
+		return
+	}
+
+	response := gin.H{"message": "Add balance completed"}
+	err = h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		result, err := qtx.AddUserBalance(context.Background(), db.AddUserBalanceParams{
+			Balance: req.Amount,
+			ID:      userId,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
+			return errResponded
+		}
+
+		if result == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
+			return errResponded
+		}
+
+		entry := ledger.TopUp(userId, req.Amount)
+		if req.Reason != "" {
+			entry.Reason = req.Reason
+		}
+
+		err = ledger.Record(context.Background(), qtx, entry)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record ledger entry"})
+			return errResponded
+		}
+
+		if !saveIdempotentResponse(c, qtx, http.StatusOK, response) {
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
-	defer tx.Rollback(context.Background())
+	c.JSON(http.StatusOK, response)
+}
 
-	var qtx db.Querier = h.db
-	if queriesDB, ok := h.db.(*db.Queries); ok {
-		qtx = queriesDB.WithTx(tx)
+// UpdateUserRoleHandler godoc
+// @Summary      Change the role of a user
+// @Description  Change the role of a user to customer, affiliate, support or admin. The sessions of the user are revoked so the new role applies from the next login
+// @Tags         Users
+// @Security BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string           true  "User ID (UUID)"
+// @Param        request  body  RequestUserRole  true  "New role"
+// @Success      200  {object}  db.UpdateUserRoleRow
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Forbidden"
+// @Router       /users/{id}/role [patch]
+func (h *Handler) UpdateUserRoleHandler(c *gin.Context) {
+	var userId pgtype.UUID
+	if err := userId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+		return
 	}
 
-	result, err := qtx.AddUserBalance(context.Background(), db.AddUserBalanceParams{
-		Balance: req.Amount,
-		ID:      userId,
+	var req RequestUserRole
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !auth.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	var user db.UpdateUserRoleRow
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		var err error
+		user, err = qtx.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{
+			Role: req.Role,
+			ID:   userId,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return errResponded
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+			return errResponded
+		}
+
+		// Access tokens carry the role, so the old one would outlive the
+		// change until they expire.
+		if _, err := qtx.RevokeUserAuthSessions(context.Background(), userId); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+			return errResponded
+		}
+
+		return nil
 	})
+	if errors.Is(err, errResponded) {
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update balance"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
-	if result == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
+	c.JSON(http.StatusOK, user)
+}
+
+// UnlockUserHandler godoc
+// @Summary      Unlock a user locked out of logging in
+// @Description  Forget the failed logins of the user, lifting a lockout or login delay. Failures counted against client IPs are kept
+// @Tags         Users
+// @Security BearerAuth
+// @Produce      json
+// @Param        id  path  string  true  "User ID (UUID)"
+// @Success      200  {object}  map[string]string  "User unlocked"
+// @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Forbidden"
+// @Failure 404 {object} handlers.ErrorResponse "User not found"
+// @Router       /users/{id}/unlock [post]
+func (h *Handler) UnlockUserHandler(c *gin.Context) {
+	var userId pgtype.UUID
+	if err := userId.Scan(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
 		return
 	}
 
-	err = tx.Commit(context.Background())
+	user, err := h.db.GetUserDetailByID(context.Background(), userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return
+	}
+
+	if _, err := h.db.DeleteUsernameLoginFailures(context.Background(), user.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Add balance completed"})
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked"})
 }
@@ -9,26 +9,29 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
+	"time"
 
+	"github.com/buranasakS/trading_application/auth"
 	mockdb "github.com/buranasakS/trading_application/db/mocks"
 	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/buranasakS/trading_application/helpers"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type responseUserDetail struct {
-	ID          pgtype.UUID `json:"id"`
-	Username    string      `json:"username"`
-	Balance     float64     `json:"balance"`
-	AffiliateID pgtype.UUID `json:"affiliate_id"`
+	ID          pgtype.UUID     `json:"id"`
+	Username    string          `json:"username"`
+	Balance     decimal.Decimal `json:"balance"`
+	AffiliateID pgtype.UUID     `json:"affiliate_id"`
 }
 
 func TestLoginUserHandler(t *testing.T) {
@@ -38,14 +41,26 @@ func TestLoginUserHandler(t *testing.T) {
 	hashPassword, errHash := helpers.HashedPassword("password123")
 	require.NoError(t, errHash)
 
+	clientIP := "192.0.2.1"
+	now := time.Now()
+	usernameFailures := []db.LoginFailure{
+		{Scope: auth.LockoutScopeUsername, Key: "testuser", Failures: 3, LastFailureAt: pgtype.Timestamptz{Time: now.Add(-time.Minute), Valid: true}},
+	}
+
 	tests := []struct {
 		name           string
 		reqBody        interface{}
 		mockUser       *db.GetUserByUsernameForLoginRow
 		mockError      error
 		expectedStatus int
-		secretKey      string
+		sessionError   error
+		expectSession  bool
 		expectedBody   string
+		failures       []db.LoginFailure
+		failuresError  error
+		expectFailure  bool
+		recordError    error
+		retryAfter     string
 	}{
 		{
 			name: "Success",
@@ -60,8 +75,8 @@ func TestLoginUserHandler(t *testing.T) {
 			},
 			mockError:      nil,
 			expectedStatus: http.StatusOK,
-			secretKey:      "SECRET_KEY",
-			expectedBody:   `"token":`,
+			expectSession:  true,
+			expectedBody:   `"access_token":`,
 		},
 		{
 			name:           "Invalid JSON",
@@ -78,8 +93,9 @@ func TestLoginUserHandler(t *testing.T) {
 				Password: "password123",
 			},
 			mockUser:       nil,
-			mockError:      sql.ErrNoRows,
+			mockError:      pgx.ErrNoRows,
 			expectedStatus: http.StatusBadRequest,
+			expectFailure:  true,
 			expectedBody:   `"Invalid username or password"`,
 		},
 		{
@@ -93,25 +109,103 @@ func TestLoginUserHandler(t *testing.T) {
 				Username: "testuser",
 				Password: hashPassword,
 			},
-			mockError:      errors.New("Invalid username or password"),
+			mockError:      nil,
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Invalid username or password",
+			expectFailure:  true,
+			expectedBody:   `"Invalid username or password"`,
 		},
 		{
-			name: "Failed to Generate Token",
+			name: "Failed to Get User",
 			reqBody: RequestUserLogin{
 				Username: "testuser",
 				Password: "password123",
 			},
+			mockError:      errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"Failed to get user"`,
+		},
+		{
+			name: "Failed to Record Failure",
+			reqBody: RequestUserLogin{
+				Username: "testuser",
+				Password: "wrongpassword",
+			},
 			mockUser: &db.GetUserByUsernameForLoginRow{
 				ID:       userId,
 				Username: "testuser",
 				Password: hashPassword,
 			},
-			mockError:      nil,
-			secretKey:      "",
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `"Missing token key"`,
+			expectFailure:  true,
+			recordError:    errors.New("db error"),
+			expectedBody:   `"Failed to record login attempt"`,
+		},
+		{
+			name: "Failed to Check Login Attempts",
+			reqBody: RequestUserLogin{
+				Username: "testuser",
+				Password: "password123",
+			},
+			failuresError:  errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"Failed to check login attempts"`,
+		},
+		{
+			name: "Username Locked Out",
+			reqBody: RequestUserLogin{
+				Username: "testuser",
+				Password: "password123",
+			},
+			failures: []db.LoginFailure{
+				{Scope: auth.LockoutScopeUsername, Key: "testuser", Failures: 10, LastFailureAt: pgtype.Timestamptz{Time: now.Add(-5 * time.Minute), Valid: true}},
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   `"Too many failed login attempts, try again later"`,
+			retryAfter:     "600",
+		},
+		{
+			name: "Client IP Delayed",
+			reqBody: RequestUserLogin{
+				Username: "otheruser",
+				Password: "password123",
+			},
+			failures: []db.LoginFailure{
+				{Scope: auth.LockoutScopeIP, Key: clientIP, Failures: 12, LastFailureAt: pgtype.Timestamptz{Time: now, Valid: true}},
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   `"Too many failed login attempts, try again later"`,
+			retryAfter:     "4",
+		},
+		{
+			name: "Success After Delay Resets Failures",
+			reqBody: RequestUserLogin{
+				Username: "testuser",
+				Password: "password123",
+			},
+			mockUser: &db.GetUserByUsernameForLoginRow{
+				ID:       userId,
+				Username: "testuser",
+				Password: hashPassword,
+			},
+			failures:       usernameFailures,
+			expectedStatus: http.StatusOK,
+			expectSession:  true,
+			expectedBody:   `"access_token":`,
+		},
+		{
+			name: "Failed to Create Session",
+			reqBody: RequestUserLogin{
+				Username: "testuser",
+				Password: "password123",
+			},
+			mockUser: &db.GetUserByUsernameForLoginRow{
+				ID:       userId,
+				Username: "testuser",
+				Password: hashPassword,
+			},
+			sessionError:   errors.New("db error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `"Failed to create session"`,
 		},
 	}
 
@@ -122,6 +216,27 @@ func TestLoginUserHandler(t *testing.T) {
 
 			mockDB := mockdb.NewMockQuerier(ctrl)
 
+			if login, ok := tt.reqBody.(RequestUserLogin); ok {
+				mockDB.EXPECT().LockLoginFailures(gomock.Any(), db.LockLoginFailuresParams{Username: login.Username, ClientIP: clientIP}).
+					Return(tt.failures, tt.failuresError).
+					Times(1)
+			}
+
+			if tt.expectFailure {
+				mockDB.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.RecordLoginFailureParams) error {
+						require.Equal(t, tt.reqBody.(RequestUserLogin).Username, arg.Username)
+						require.Equal(t, clientIP, arg.ClientIP)
+						require.Equal(t, auth.LoginFailureWindow, arg.FailedAt.Time.Sub(arg.WindowStart.Time))
+						return tt.recordError
+					}).
+					Times(1)
+			}
+
+			if tt.expectSession || tt.sessionError != nil {
+				mockDB.EXPECT().DeleteUsernameLoginFailures(gomock.Any(), "testuser").Return(int64(1), nil).Times(1)
+			}
+
 			if tt.mockUser != nil || tt.mockError != nil {
 				mockDB.EXPECT().GetUserByUsernameForLogin(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(ctx context.Context, username string) (db.GetUserByUsernameForLoginRow, error) {
@@ -133,12 +248,28 @@ func TestLoginUserHandler(t *testing.T) {
 					Times(1)
 			}
 
-			os.Setenv("SECRET_KEY", tt.secretKey)
+			if tt.sessionError != nil {
+				mockDB.EXPECT().CreateAuthSession(gomock.Any(), userId).Return(db.AuthSession{}, tt.sessionError).Times(1)
+			}
+
+			if tt.expectSession {
+				sessionId := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+				mockDB.EXPECT().CreateAuthSession(gomock.Any(), tt.mockUser.ID).
+					Return(db.AuthSession{ID: sessionId, UserID: tt.mockUser.ID}, nil).
+					Times(1)
+				mockDB.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, arg db.CreateRefreshTokenParams) (db.RefreshToken, error) {
+						require.Equal(t, sessionId, arg.SessionID)
+						return db.RefreshToken{SessionID: sessionId, TokenHash: arg.TokenHash}, nil
+					}).
+					Times(1)
+			}
 
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
+			store := mockdb.NewFakeStore(mockDB)
 			router.POST("/login", func(c *gin.Context) {
-				NewHandler(mockDB).LoginUserHandler(c)
+				NewHandler(store, testKeys).LoginUserHandler(c)
 			})
 
 			body, err := json.Marshal(tt.reqBody)
@@ -151,6 +282,11 @@ func TestLoginUserHandler(t *testing.T) {
 			router.ServeHTTP(recorder, req)
 
 			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Equal(t, tt.retryAfter, recorder.Header().Get("Retry-After"))
+			if tt.expectFailure && tt.recordError == nil {
+				// The failure is counted even though the login is refused.
+				require.Equal(t, 1, store.Commits)
+			}
 
 			responseBody, err := io.ReadAll(recorder.Body)
 			require.NoError(t, err)
@@ -187,7 +323,7 @@ func TestRegisterUserHandler(t *testing.T) {
 			mockReturnData: db.User{
 				ID:          userId,
 				Username:    "testuser",
-				Balance:     0,
+				Balance:     decimal.NewFromInt(0),
 				AffiliateID: affiliateId,
 			},
 			mockReturnErr:  nil,
@@ -251,7 +387,7 @@ func TestRegisterUserHandler(t *testing.T) {
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
 			router.POST("/register", func(c *gin.Context) {
-				NewHandler(mockDB).RegisterUserHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).RegisterUserHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(body))
@@ -299,13 +435,13 @@ func TestListUsersHandler(t *testing.T) {
 				{
 					ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
 					Username:    "user1",
-					Balance:     100,
+					Balance:     decimal.NewFromInt(100),
 					AffiliateID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
 				},
 				{
 					ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
 					Username:    "user2",
-					Balance:     200,
+					Balance:     decimal.NewFromInt(200),
 					AffiliateID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
 				},
 			},
@@ -315,13 +451,13 @@ func TestListUsersHandler(t *testing.T) {
 				{
 					ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
 					Username:    "user1",
-					Balance:     100,
+					Balance:     decimal.NewFromInt(100),
 					AffiliateID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
 				},
 				{
 					ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
 					Username:    "user3",
-					Balance:     200,
+					Balance:     decimal.NewFromInt(200),
 					AffiliateID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
 				},
 			},
@@ -354,15 +490,21 @@ func TestListUsersHandler(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			if tc.expectedStatus == http.StatusOK || tc.expectedStatus == http.StatusInternalServerError {
 				mockDB.EXPECT().
-					ListUsers(gomock.Any(), gomock.Any()).
+					ListUsers(gomock.Any(), db.ListUsersParams{PageLimit: 3}).
 					Return(tc.mockReturn, tc.mockError).
 					Times(1)
 			}
+			if tc.expectedStatus == http.StatusOK {
+				mockDB.EXPECT().
+					CountUsers(gomock.Any(), db.CountUsersParams{}).
+					Return(int64(5), nil).
+					Times(1)
+			}
 
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
 			router.GET("/users/all", func(c *gin.Context) {
-				NewHandler(mockDB).ListUsersHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).ListUsersHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodGet, "/users/all?"+tc.queryParams, nil)
@@ -378,6 +520,8 @@ func TestListUsersHandler(t *testing.T) {
 				err := json.Unmarshal(recorder.Body.Bytes(), &response)
 				assert.NoError(t, err)
 				assert.Equal(t, tc.expectedCount, len(response.Data))
+				assert.Equal(t, int32(5), response.TotalCount)
+				assert.Equal(t, int32(3), response.TotalPage)
 			}
 		})
 	}
@@ -395,7 +539,7 @@ func TestGetUserDetail(t *testing.T) {
 	user := responseUserDetail{
 		ID:          userId,
 		Username:    "testuser",
-		Balance:     100,
+		Balance:     decimal.NewFromInt(100),
 		AffiliateID: affiliateId,
 	}
 
@@ -449,8 +593,9 @@ func TestGetUserDetail(t *testing.T) {
 
 			gin.SetMode(gin.ReleaseMode)
 			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
 			router.GET("/users/:id", func(c *gin.Context) {
-				NewHandler(mockDB).GetUserDetailHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).GetUserDetailHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodGet, "/users/"+tc.userID, nil)
@@ -497,7 +642,7 @@ func TestDeductUserBalanceHandler(t *testing.T) {
 			name:   "Success",
 			userId: userId.String(),
 			reqBody: RequestAmount{
-				Amount: 100.0,
+				Amount: decimal.NewFromInt(100),
 			},
 			mockUserErr:    nil,
 			mockDeductErr:  nil,
@@ -508,7 +653,7 @@ func TestDeductUserBalanceHandler(t *testing.T) {
 		{
 			name:           "Invalid User ID",
 			userId:         "invalid-uuid",
-			reqBody:        RequestAmount{Amount: 100.0},
+			reqBody:        RequestAmount{Amount: decimal.NewFromInt(100)},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   gin.H{"error": "Invalid User ID"},
 		},
@@ -516,7 +661,7 @@ func TestDeductUserBalanceHandler(t *testing.T) {
 			name:   "User Not Found",
 			userId: userId.String(),
 			reqBody: RequestAmount{
-				Amount: 100.0,
+				Amount: decimal.NewFromInt(100),
 			},
 			mockUserErr:    sql.ErrNoRows,
 			expectedStatus: http.StatusBadRequest,
@@ -526,12 +671,12 @@ func TestDeductUserBalanceHandler(t *testing.T) {
 			name:   "Insufficient Balance",
 			userId: userId.String(),
 			reqBody: RequestAmount{
-				Amount: 1000.0,
+				Amount: decimal.NewFromInt(1000),
 			},
 			mockUserDetail: db.GetUserDetailByIDRow{
 				ID:       userId,
 				Username: "testuser",
-				Balance:  500.0,
+				Balance:  decimal.NewFromInt(500),
 			},
 			mockUserErr:    nil,
 			mockDeductErr:  nil,
@@ -553,7 +698,7 @@ func TestDeductUserBalanceHandler(t *testing.T) {
 				"amount": 0,
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   gin.H{"error": "Invalid request body, 'amount' should be a positive number"},
+			expectedBody:   gin.H{"error": "Amount must be more than 0"},
 		},
 		{
 			name:   "Negative Amount",
@@ -577,7 +722,7 @@ func TestDeductUserBalanceHandler(t *testing.T) {
 					Times(1)
 
 				if tt.mockUserErr == nil && tt.reqBody != "invalid json" {
-					if amount, ok := tt.reqBody.(RequestAmount); ok && amount.Amount > 0 {
+					if amount, ok := tt.reqBody.(RequestAmount); ok && amount.Amount.IsPositive() {
 						mockDB.EXPECT().
 							DeductUserBalance(gomock.Any(), db.DeductUserBalanceParams{
 								Balance: amount.Amount,
@@ -585,13 +730,21 @@ func TestDeductUserBalanceHandler(t *testing.T) {
 							}).
 							Return(tt.mockDeductRows, tt.mockDeductErr).
 							Times(1)
+
+						if tt.mockDeductErr == nil && tt.mockDeductRows > 0 {
+							mockDB.EXPECT().
+								CreateLedgerEntry(gomock.Any(), gomock.Any()).
+								Return(db.LedgerEntry{}, nil).
+								Times(2)
+						}
 					}
 				}
 			}
 
 			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
 			router.PATCH("/users/deduct/balance/:id", func(c *gin.Context) {
-				NewHandler(mockDB).DeductUserBalanceHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).DeductUserBalanceHandler(c)
 			})
 
 			body, err := json.Marshal(tt.reqBody)
@@ -642,7 +795,7 @@ func TestAddUserBalanceHandler(t *testing.T) {
 			name:   "Success",
 			userId: userId.String(),
 			reqBody: RequestAmount{
-				Amount: 100.0,
+				Amount: decimal.NewFromInt(100),
 			},
 			mockUserErr:   nil,
 			mockAddErr:    nil,
@@ -653,7 +806,7 @@ func TestAddUserBalanceHandler(t *testing.T) {
 		{
 			name:           "Invalid User ID",
 			userId:         "invalid-uuid",
-			reqBody:        RequestAmount{Amount: 100.0},
+			reqBody:        RequestAmount{Amount: decimal.NewFromInt(100)},
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   gin.H{"error": "Invalid User ID"},
 		},
@@ -661,7 +814,7 @@ func TestAddUserBalanceHandler(t *testing.T) {
 			name:   "User Not Found",
 			userId: userId.String(),
 			reqBody: RequestAmount{
-				Amount: 100.0,
+				Amount: decimal.NewFromInt(100),
 			},
 			mockUserErr:   sql.ErrNoRows,
 			expectedStatus: http.StatusBadRequest,
@@ -671,12 +824,12 @@ func TestAddUserBalanceHandler(t *testing.T) {
 			name:   "Insufficient Balance",
 			userId: userId.String(),
 			reqBody: RequestAmount{
-				Amount: 1000.0,
+				Amount: decimal.NewFromInt(1000),
 			},
 			mockUserDetail: db.GetUserDetailByIDRow{
 				ID:       userId,
 				Username: "testuser",
-				Balance:  500.0,
+				Balance:  decimal.NewFromInt(500),
 			},
 			mockUserErr:    nil,
 			mockAddErr:     nil,
@@ -698,7 +851,7 @@ func TestAddUserBalanceHandler(t *testing.T) {
 				"amount": 0,
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   gin.H{"error": "Invalid request body, 'amount' should be a positive number"},
+			expectedBody:   gin.H{"error": "Amount must be more than 0"},
 		},
 		{
 			name:   "Negative Amount",
@@ -722,7 +875,7 @@ func TestAddUserBalanceHandler(t *testing.T) {
 					Times(1)
 
 				if tt.mockUserErr == nil && tt.reqBody != "invalid json" {
-					if amount, ok := tt.reqBody.(RequestAmount); ok && amount.Amount > 0 {
+					if amount, ok := tt.reqBody.(RequestAmount); ok && amount.Amount.IsPositive() {
 						mockDB.EXPECT().
 							AddUserBalance(gomock.Any(), db.AddUserBalanceParams{
 								Balance: amount.Amount,
@@ -730,13 +883,21 @@ func TestAddUserBalanceHandler(t *testing.T) {
 							}).
 							Return(tt.mockAddRows, tt.mockAddErr).
 							Times(1)
+
+						if tt.mockAddErr == nil && tt.mockAddRows > 0 {
+							mockDB.EXPECT().
+								CreateLedgerEntry(gomock.Any(), gomock.Any()).
+								Return(db.LedgerEntry{}, nil).
+								Times(2)
+						}
 					}
 				}
 			}
 
 			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
 			router.PATCH("/users/add/balance/:id", func(c *gin.Context) {
-				NewHandler(mockDB).AddUserBalanceHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).AddUserBalanceHandler(c)
 			})
 
 			body, err := json.Marshal(tt.reqBody)
@@ -765,3 +926,317 @@ func TestAddUserBalanceHandler(t *testing.T) {
 	}
 }
 
+
+func TestUserBalanceHandlersRollback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	failure := errors.New("db error")
+
+	type balanceUpdate func(mockDB *mockdb.MockQuerier) *gomock.Call
+
+	deduct := func(mockDB *mockdb.MockQuerier) *gomock.Call {
+		return mockDB.EXPECT().DeductUserBalance(gomock.Any(), gomock.Any())
+	}
+	add := func(mockDB *mockdb.MockQuerier) *gomock.Call {
+		return mockDB.EXPECT().AddUserBalance(gomock.Any(), gomock.Any())
+	}
+
+	tests := []struct {
+		name            string
+		path            string
+		handler         func(h *Handler) gin.HandlerFunc
+		update          balanceUpdate
+		updateRows      int64
+		updateErr       error
+		ledgerErr       error
+		commitErr       error
+		expectedStatus  int
+		expectedError   string
+		expectedCommits int
+	}{
+		{
+			name:            "Deduct commits",
+			path:            "/users/deduct/balance/",
+			handler:         func(h *Handler) gin.HandlerFunc { return h.DeductUserBalanceHandler },
+			update:          deduct,
+			updateRows:      1,
+			expectedStatus:  http.StatusOK,
+			expectedCommits: 1,
+		},
+		{
+			name:           "Deduct rolls back on insufficient balance",
+			path:           "/users/deduct/balance/",
+			handler:        func(h *Handler) gin.HandlerFunc { return h.DeductUserBalanceHandler },
+			update:         deduct,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Insufficient balance",
+		},
+		{
+			name:           "Deduct rolls back on ledger failure",
+			path:           "/users/deduct/balance/",
+			handler:        func(h *Handler) gin.HandlerFunc { return h.DeductUserBalanceHandler },
+			update:         deduct,
+			updateRows:     1,
+			ledgerErr:      failure,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to record ledger entry",
+		},
+		{
+			name:           "Deduct reports commit failure",
+			path:           "/users/deduct/balance/",
+			handler:        func(h *Handler) gin.HandlerFunc { return h.DeductUserBalanceHandler },
+			update:         deduct,
+			updateRows:     1,
+			commitErr:      failure,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to commit transaction",
+		},
+		{
+			name:            "Add commits",
+			path:            "/users/add/balance/",
+			handler:         func(h *Handler) gin.HandlerFunc { return h.AddUserBalanceHandler },
+			update:          add,
+			updateRows:      1,
+			expectedStatus:  http.StatusOK,
+			expectedCommits: 1,
+		},
+		{
+			name:           "Add rolls back on update failure",
+			path:           "/users/add/balance/",
+			handler:        func(h *Handler) gin.HandlerFunc { return h.AddUserBalanceHandler },
+			update:         add,
+			updateErr:      failure,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to update balance",
+		},
+		{
+			name:           "Add rolls back on ledger failure",
+			path:           "/users/add/balance/",
+			handler:        func(h *Handler) gin.HandlerFunc { return h.AddUserBalanceHandler },
+			update:         add,
+			updateRows:     1,
+			ledgerErr:      failure,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to record ledger entry",
+		},
+		{
+			name:           "Add reports commit failure",
+			path:           "/users/add/balance/",
+			handler:        func(h *Handler) gin.HandlerFunc { return h.AddUserBalanceHandler },
+			update:         add,
+			updateRows:     1,
+			commitErr:      failure,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to commit transaction",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			store := mockdb.NewFakeStore(mockDB)
+			store.CommitErr = tt.commitErr
+
+			mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{ID: userId}, nil).Times(1)
+			tt.update(mockDB).Return(tt.updateRows, tt.updateErr).Times(1)
+			if tt.updateErr == nil && tt.updateRows > 0 {
+				if tt.ledgerErr != nil {
+					mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, tt.ledgerErr).Times(1)
+				} else {
+					mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+				}
+			}
+
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.PATCH(tt.path+":id", tt.handler(NewHandler(store, testKeys)))
+
+			body, err := json.Marshal(RequestAmount{Amount: decimal.NewFromInt(100)})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPatch, tt.path+userId.String(), bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Equal(t, tt.expectedCommits, store.Commits)
+			require.Equal(t, 1-tt.expectedCommits, store.Rollbacks)
+
+			if tt.expectedError != "" {
+				var response gin.H
+				err = json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedError, response["error"])
+			}
+		})
+	}
+}
+
+func TestUpdateUserRoleHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name           string
+		reqBody        interface{}
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+		expectCommit   bool
+	}{
+		{
+			name:    "Promoted to support",
+			reqBody: RequestUserRole{Role: "support"},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().UpdateUserRole(gomock.Any(), db.UpdateUserRoleParams{Role: "support", ID: userId}).
+					Return(db.UpdateUserRoleRow{ID: userId, Username: "testuser", Role: "support"}, nil).Times(1)
+				mockDB.EXPECT().RevokeUserAuthSessions(gomock.Any(), userId).Return(int64(2), nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectCommit:   true,
+		},
+		{
+			name:           "Unknown role",
+			reqBody:        RequestUserRole{Role: "superuser"},
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid role",
+		},
+		{
+			name:    "User not found",
+			reqBody: RequestUserRole{Role: "admin"},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).Return(db.UpdateUserRoleRow{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "User not found",
+		},
+		{
+			name:    "Sessions cannot be revoked",
+			reqBody: RequestUserRole{Role: "admin"},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).
+					Return(db.UpdateUserRoleRow{ID: userId, Username: "testuser", Role: "admin"}, nil).Times(1)
+				mockDB.EXPECT().RevokeUserAuthSessions(gomock.Any(), userId).Return(int64(0), errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "Failed to revoke sessions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+			store := mockdb.NewFakeStore(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.PATCH("/users/:id/role", NewHandler(store, testKeys).UpdateUserRoleHandler)
+
+			body, err := json.Marshal(tt.reqBody)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPatch, "/users/"+userId.String()+"/role", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectCommit {
+				require.Equal(t, 1, store.Commits)
+			} else {
+				require.Zero(t, store.Commits)
+			}
+
+			if tt.expectedError != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, tt.expectedError, response["error"])
+				return
+			}
+
+			var response db.UpdateUserRoleRow
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			require.Equal(t, "support", response.Role)
+		})
+	}
+}
+
+func TestUnlockUserHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name           string
+		path           string
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Unlocked",
+			path: "/users/" + userId.String() + "/unlock",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).
+					Return(db.GetUserDetailByIDRow{ID: userId, Username: "testuser"}, nil).Times(1)
+				mockDB.EXPECT().DeleteUsernameLoginFailures(gomock.Any(), "testuser").Return(int64(1), nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"User unlocked"}`,
+		},
+		{
+			name:           "Invalid user ID",
+			path:           "/users/invalid/unlock",
+			buildStubs:     func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Invalid User ID"}`,
+		},
+		{
+			name: "User not found",
+			path: "/users/" + userId.String() + "/unlock",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(db.GetUserDetailByIDRow{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"User not found"}`,
+		},
+		{
+			name: "Unlock fails",
+			path: "/users/" + userId.String() + "/unlock",
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).
+					Return(db.GetUserDetailByIDRow{ID: userId, Username: "testuser"}, nil).Times(1)
+				mockDB.EXPECT().DeleteUsernameLoginFailures(gomock.Any(), "testuser").Return(int64(0), errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"Failed to unlock user"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/users/:id/unlock", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).UnlockUserHandler)
+
+			req, err := http.NewRequest(http.MethodPost, tt.path, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.JSONEq(t, tt.expectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -2,28 +2,35 @@ package handlers
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
+	"time"
 
-	"github.com/buranasakS/trading_application/config"
 	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/inventory"
+	"github.com/buranasakS/trading_application/ledger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
+// OrderRequest places an order for one product. user_id defaults to the
+// caller; only support and admin may order for another user. With a
+// reservation_id the order consumes that reservation; product_id and
+// quantity may then be left out and default to the reserved ones.
 type OrderRequest struct {
-	UserID    pgtype.UUID `json:"user_id"`
-	ProductID pgtype.UUID `json:"product_id"`
-	Quantity  int         `json:"quantity"`
+	UserID        pgtype.UUID `json:"user_id"`
+	ProductID     pgtype.UUID `json:"product_id"`
+	Quantity      int         `json:"quantity"`
+	ReservationID pgtype.UUID `json:"reservation_id"`
 }
 
 type OrderResponse struct {
-	Status    string  `json:"status"`
-	Message   string  `json:"message"`
-	OrderID   string  `json:"order_id"`
-	TotalCost float64 `json:"total_cost"`
+	Status    string          `json:"status"`
+	Message   string          `json:"message"`
+	OrderID   string          `json:"order_id"`
+	TotalCost decimal.Decimal `json:"total_cost"`
 }
 
 // UserOrderProductHandler godoc
@@ -36,6 +43,7 @@ type OrderResponse struct {
 // @Param        request body    OrderRequest true "Order product detail"
 // @Success      201  {object}   OrderResponse  "Order completed"
 // @Failure 401 {object} handlers.ErrorResponse "Unauthorized"
+// @Failure 403 {object} handlers.ErrorResponse "Another user's account"
 // @Router       /users/order [post]
 func (h *Handler) UserOrderProductHandler(c *gin.Context) {
 	var req OrderRequest
@@ -44,144 +52,218 @@ func (h *Handler) UserOrderProductHandler(c *gin.Context) {
 		return
 	}
 
-	if req.Quantity <= 0 {
+	if req.Quantity < 0 || (req.Quantity == 0 && !req.ReservationID.Valid) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be more than 0"})
 		return
 	}
 
-	tx, err := config.ConnectDatabase().DB.BeginTx(context.Background(), pgx.TxOptions{})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+	principal, ok := requirePrincipal(c)
+	if !ok {
 		return
 	}
-	defer tx.Rollback(context.Background())
 
-	var qtx db.Querier = h.db
-	if queriesDB, ok := h.db.(*db.Queries); ok {
-		qtx = queriesDB.WithTx(tx)
+	if !req.UserID.Valid {
+		req.UserID = principal.UserID
 	}
 
-	user, err := h.db.GetUserDetailByID(context.Background(), req.UserID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+	if !authorizeUser(c, req.UserID) {
 		return
 	}
 
-	product, err := h.db.GetProductByID(context.Background(), req.ProductID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Product not found"})
-		return
-	}
+	h.placeOrder(c, req)
+}
 
-	if product.Quantity < int32(req.Quantity) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough product in stock"})
-		return
-	}
+// placeOrder buys req.Quantity of the product for the user and writes the
+// response. When req.ReservationID is set the reservation is confirmed with
+// the order, so the stock it held is sold instead of being counted twice.
+func (h *Handler) placeOrder(c *gin.Context, req OrderRequest) {
+	var response gin.H
+	err := h.db.ExecTx(context.Background(), func(qtx db.Querier) error {
+		// Lock the user, then the reservation, then the product. Every write
+		// path takes the locks in this order so concurrent orders and refunds
+		// cannot deadlock.
+		user, err := qtx.GetUserDetailByIDForUpdate(context.Background(), req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+			return errResponded
+		}
 
-	totalPrice := product.Price * float64(req.Quantity)
-	if user.Balance < totalPrice {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough balance"})
-		return
-	}
+		if req.ReservationID.Valid {
+			reservation, err := qtx.GetStockReservationByIDForUpdate(context.Background(), req.ReservationID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Reservation not found"})
+				return errResponded
+			}
 
-	_, err = qtx.DeductUserBalance(context.Background(), db.DeductUserBalanceParams{
-		Balance: totalPrice,
-		ID:      req.UserID,
-	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct balance"})
-		return
-	}
+			if msg := checkOrderReservation(reservation, req); msg != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+				return errResponded
+			}
 
-	_, err = qtx.DeductProductQuantity(context.Background(), db.DeductProductQuantityParams{
-		Quantity: int32(req.Quantity),
-		ID:       req.ProductID,
-	})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct product quantity"})
-		return
-	}
+			req.ProductID = reservation.ProductID
+			req.Quantity = int(reservation.Quantity)
+		}
+
+		product, err := qtx.GetProductByIDForUpdate(context.Background(), req.ProductID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product not found"})
+			return errResponded
+		}
 
-	orderID := uuid.New()
+		if product.ArchivedAt.Valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Product is no longer available"})
+			return errResponded
+		}
 
-	if user.AffiliateID.Valid {
-		affiliates := []db.Affiliate{}
-		currentAffiliateID := user.AffiliateID
+		// The order's own reservation is left out, its stock is the stock
+		// being bought.
+		reserved, err := qtx.GetReservedProductQuantity(context.Background(), db.GetReservedProductQuantityParams{
+			ProductID: req.ProductID,
+			ExcludeID: req.ReservationID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reserved quantity"})
+			return errResponded
+		}
 
-		for currentAffiliateID.Valid {
-			affiliate, err := qtx.GetAffiliateByID(context.Background(), currentAffiliateID)
-			if err != nil || !affiliate.ID.Valid {
-				break
-			}
-			affiliates = append(affiliates, affiliate)
-			currentAffiliateID = affiliate.MasterAffiliate
+		if product.Quantity-reserved < int32(req.Quantity) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough product in stock"})
+			return errResponded
 		}
 
-		if len(affiliates) == 0 {
-			return
+		unitPrice, priceId, err := resolveProductPrice(context.Background(), qtx, product, pgtype.Timestamptz{Time: time.Now(), Valid: true})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get product price"})
+			return errResponded
 		}
 
-		commissionRates := []float64{0.20, 0.15, 0.10, 0.05}
-		numLevels := len(commissionRates)
-		var previousCommissionRate float64 = commissionRates[numLevels-1]
+		totalPrice := unitPrice.Mul(decimal.NewFromInt(int64(req.Quantity)))
+		if user.Balance.LessThan(totalPrice) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough balance"})
+			return errResponded
+		}
 
-		fmt.Println(numLevels)
-		fmt.Println(previousCommissionRate)
+		result, err := qtx.DeductUserBalance(context.Background(), db.DeductUserBalanceParams{
+			Balance: totalPrice,
+			ID:      req.UserID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct balance"})
+			return errResponded
+		}
 
-		for i := 0; i < len(affiliates); i++ {
-			var commissionAmount float64
-			level := len(affiliates) - 1 - i
+		if result == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough balance"})
+			return errResponded
+		}
 
-			if level == 0 {
-				commissionAmount = commissionRates[0] * totalPrice
-				previousCommissionRate = commissionRates[0]
-			} else if level < numLevels {
-				commissionAmount = (commissionRates[level-1] - commissionRates[level]) * totalPrice
-				previousCommissionRate = commissionRates[level-1]
-			} else {
-				previousCommissionRate = 0
-				commissionAmount = previousCommissionRate * totalPrice
-			}
+		result, err = qtx.DeductProductQuantity(context.Background(), db.DeductProductQuantityParams{
+			Quantity: int32(req.Quantity),
+			ID:       req.ProductID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct product quantity"})
+			return errResponded
+		}
+
+		if result == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Not enough product in stock"})
+			return errResponded
+		}
 
-			commissionAmount = commissionAmount * 100 / 100
+		order, err := qtx.CreateOrder(context.Background(), db.CreateOrderParams{
+			UserID:    req.UserID,
+			TotalCost: totalPrice,
+			Status:    OrderStatusCompleted,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+			return errResponded
+		}
 
-			if commissionAmount <= 0 {
-				continue
+		if req.ReservationID.Valid {
+			result, err = qtx.ConfirmStockReservation(context.Background(), db.ConfirmStockReservationParams{
+				OrderID: order.ID,
+				ID:      req.ReservationID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm reservation"})
+				return errResponded
 			}
 
-			if commissionAmount > 0 {
-				_, err := qtx.CreateCommission(context.Background(), db.CreateCommissionParams{
-					OrderID:     pgtype.UUID{Bytes: orderID, Valid: true},
-					AffiliateID: affiliates[i].ID,
-					Amount:      commissionAmount,
-				})
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create commission"})
-					return
-				}
-
-				err = qtx.AddAffiliateBalance(context.Background(), db.AddAffiliateBalanceParams{
-					ID:      affiliates[i].ID,
-					Balance: commissionAmount,
-				})
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add affiliate balance"})
-					return
-				}
+			if result == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Reservation is no longer active"})
+				return errResponded
 			}
 		}
-	}
 
-	err = tx.Commit(context.Background())
+		err = ledger.Record(context.Background(), qtx, ledger.Purchase(req.UserID, order.ID, totalPrice))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record ledger entry"})
+			return errResponded
+		}
+
+		_, err = qtx.CreateOrderItem(context.Background(), db.CreateOrderItemParams{
+			OrderID:    order.ID,
+			ProductID:  req.ProductID,
+			Quantity:   int32(req.Quantity),
+			UnitPrice:  unitPrice,
+			TotalPrice: totalPrice,
+			ListPrice:  product.Price,
+			PriceID:    priceId,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order item"})
+			return errResponded
+		}
+
+		if err := recordInventory(c, qtx, inventory.Sale(req.ProductID, order.ID, int32(req.Quantity))); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record inventory movement"})
+			return errResponded
+		}
+
+		lines := []orderLine{{product: product, quantity: int32(req.Quantity), unitPrice: unitPrice, priceID: priceId, total: totalPrice}}
+		if err := payOrderCommissions(c, qtx, order, user.AffiliateID, lines); err != nil {
+			return err
+		}
+
+		response = gin.H{
+			"status":     "success",
+			"message":    "Purchase completed",
+			"order_id":   uuid.UUID(order.ID.Bytes).String(),
+			"total_cost": totalPrice,
+		}
+		if !saveIdempotentResponse(c, qtx, http.StatusCreated, response) {
+			return errResponded
+		}
+
+		return nil
+	})
+	if errors.Is(err, errResponded) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"Error": "Failed to commit transaction"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"status":     "success",
-		"message":    "Purchase completed",
-		"order_id":   orderID.String(),
-		"total_cost": totalPrice,
-	})
+	c.JSON(http.StatusCreated, response)
+}
+
+// checkOrderReservation returns why reservation cannot be used for req, or
+// an empty string when it can.
+func checkOrderReservation(reservation db.StockReservation, req OrderRequest) string {
+	switch {
+	case reservation.UserID != req.UserID:
+		return "Reservation belongs to another user"
+	case reservation.Status != ReservationStatusActive:
+		return "Reservation is no longer active"
+	case !reservation.ExpiresAt.Time.After(time.Now()):
+		return "Reservation has expired"
+	case req.ProductID.Valid && req.ProductID != reservation.ProductID:
+		return "Reservation is for a different product"
+	case req.Quantity != 0 && int32(req.Quantity) != reservation.Quantity:
+		return "Quantity does not match the reservation"
+	}
+	return ""
 }
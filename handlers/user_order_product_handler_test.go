@@ -10,13 +10,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/buranasakS/trading_application/auth"
 	mockdb "github.com/buranasakS/trading_application/db/mocks"
 	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/buranasakS/trading_application/helpers"
+	"github.com/buranasakS/trading_application/inventory"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -32,14 +37,17 @@ func TestUserOrderProductHandler(t *testing.T) {
 	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
 	affiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
 	masterAffiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174004")
+	planId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174005")
 
 	tests := []struct {
 		name                    string
 		reqBody                 interface{}
-		mockUser                db.GetUserDetailByIDRow
+		mockUser                db.GetUserDetailByIDForUpdateRow
 		mockUserErr             error
 		mockProduct             db.Product
 		mockProductErr          error
+		mockReserved            int32
 		mockDeductUserRows      int64
 		mockDeductUserErr       error
 		mockDeductProductRows   int64
@@ -96,10 +104,10 @@ func TestUserOrderProductHandler(t *testing.T) {
 				ProductID: productId,
 				Quantity:  1,
 			},
-			mockUser: db.GetUserDetailByIDRow{
+			mockUser: db.GetUserDetailByIDForUpdateRow{
 				ID:       userId,
 				Username: "testuser",
-				Balance:  1000.0,
+				Balance:  decimal.NewFromInt(1000),
 			},
 			mockUserErr:    nil,
 			mockProductErr: sql.ErrNoRows,
@@ -113,10 +121,10 @@ func TestUserOrderProductHandler(t *testing.T) {
 				ProductID: productId,
 				Quantity:  1,
 			},
-			mockUser: db.GetUserDetailByIDRow{
+			mockUser: db.GetUserDetailByIDForUpdateRow{
 				ID:          userId,
 				Username:    "testuser",
-				Balance:     1000.0,
+				Balance:     decimal.NewFromInt(1000),
 				AffiliateID: pgtype.UUID{Valid: false},
 			},
 			mockUserErr: nil,
@@ -124,7 +132,7 @@ func TestUserOrderProductHandler(t *testing.T) {
 				ID:       productId,
 				Name:     "testproduct",
 				Quantity: 100,
-				Price:    100.0,
+				Price:    decimal.NewFromInt(100),
 			},
 			mockProductErr:        nil,
 			mockDeductUserRows:    1,
@@ -141,10 +149,10 @@ func TestUserOrderProductHandler(t *testing.T) {
 				ProductID: productId,
 				Quantity:  1,
 			},
-			mockUser: db.GetUserDetailByIDRow{
+			mockUser: db.GetUserDetailByIDForUpdateRow{
 				ID:          userId,
 				Username:    "testuser",
-				Balance:     1000.0,
+				Balance:     decimal.NewFromInt(1000),
 				AffiliateID: affiliateId,
 			},
 			mockUserErr: nil,
@@ -152,7 +160,7 @@ func TestUserOrderProductHandler(t *testing.T) {
 				ID:       productId,
 				Name:     "testproduct",
 				Quantity: 100,
-				Price:    100.0,
+				Price:    decimal.NewFromInt(100),
 			},
 			mockProductErr:        nil,
 			mockDeductUserRows:    1,
@@ -184,17 +192,17 @@ func TestUserOrderProductHandler(t *testing.T) {
 				ProductID: productId,
 				Quantity:  1,
 			},
-			mockUser: db.GetUserDetailByIDRow{
+			mockUser: db.GetUserDetailByIDForUpdateRow{
 				ID:       userId,
 				Username: "testuser",
-				Balance:  50.0,
+				Balance:  decimal.NewFromInt(50),
 			},
 			mockUserErr: nil,
 			mockProduct: db.Product{
 				ID:       productId,
 				Name:     "testproduct",
 				Quantity: 100,
-				Price:    100.0,
+				Price:    decimal.NewFromInt(100),
 			},
 			mockProductErr: nil,
 			expectedStatus: http.StatusBadRequest,
@@ -207,22 +215,44 @@ func TestUserOrderProductHandler(t *testing.T) {
 				ProductID: productId,
 				Quantity:  15,
 			},
-			mockUser: db.GetUserDetailByIDRow{
+			mockUser: db.GetUserDetailByIDForUpdateRow{
 				ID:       userId,
 				Username: "testuser",
-				Balance:  1000.0,
+				Balance:  decimal.NewFromInt(1000),
 			},
 			mockUserErr: nil,
 			mockProduct: db.Product{
 				ID:       productId,
 				Name:     "testproduct",
 				Quantity: 10,
-				Price:    100.0,
+				Price:    decimal.NewFromInt(100),
 			},
 			mockProductErr: nil,
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "Not enough product in stock",
 		},
+		{
+			name: "Stock held by reservations",
+			reqBody: OrderRequest{
+				UserID:    userId,
+				ProductID: productId,
+				Quantity:  5,
+			},
+			mockUser: db.GetUserDetailByIDForUpdateRow{
+				ID:       userId,
+				Username: "testuser",
+				Balance:  decimal.NewFromInt(1000),
+			},
+			mockProduct: db.Product{
+				ID:       productId,
+				Name:     "testproduct",
+				Quantity: 10,
+				Price:    decimal.NewFromInt(100),
+			},
+			mockReserved:   8,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Not enough product in stock",
+		},
 		{
 			name: "Failed to deduct user balance",
 			reqBody: OrderRequest{
@@ -230,13 +260,13 @@ func TestUserOrderProductHandler(t *testing.T) {
 				ProductID: productId,
 				Quantity:  1,
 			},
-			mockUser: db.GetUserDetailByIDRow{
+			mockUser: db.GetUserDetailByIDForUpdateRow{
 				ID:       userId,
 				Username: "testuser",
-				Balance:  1000,
+				Balance:  decimal.NewFromInt(1000),
 			},
 			mockUserErr:       nil,
-			mockProduct:       db.Product{ID: productId, Name: "testproduct", Quantity: 100, Price: 100},
+			mockProduct:       db.Product{ID: productId, Name: "testproduct", Quantity: 100, Price: decimal.NewFromInt(100)},
 			mockProductErr:    nil,
 			mockDeductUserErr: errors.New("Failed to deduct user balance"),
 			expectedStatus:    http.StatusInternalServerError,
@@ -263,14 +293,26 @@ func TestUserOrderProductHandler(t *testing.T) {
 
 			if tt.name != "Invalid JSON" {
 				if tt.name == "Insufficient Balance" || tt.name == "Not Enough Product in Stock" {
-					mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(tt.mockUser, tt.mockUserErr).Times(1)
+					mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(tt.mockUser, tt.mockUserErr).Times(1)
 					if tt.mockUserErr == nil {
-						mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(tt.mockProduct, tt.mockProductErr).Times(1)
+						mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(tt.mockProduct, tt.mockProductErr).Times(1)
+					}
+					if tt.mockUserErr == nil && tt.mockProductErr == nil {
+						mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), db.GetReservedProductQuantityParams{ProductID: productId}).Return(tt.mockReserved, nil).Times(1)
+						if tt.mockProduct.Quantity-tt.mockReserved >= int32(quantity) {
+							mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, pgx.ErrNoRows).Times(1)
+						}
 					}
 				} else if quantity > 0 {
-					mockDB.EXPECT().GetUserDetailByID(gomock.Any(), userId).Return(tt.mockUser, tt.mockUserErr).Times(1)
+					mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(tt.mockUser, tt.mockUserErr).Times(1)
 					if tt.mockUserErr == nil {
-						mockDB.EXPECT().GetProductByID(gomock.Any(), productId).Return(tt.mockProduct, tt.mockProductErr).Times(1)
+						mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(tt.mockProduct, tt.mockProductErr).Times(1)
+					}
+					if tt.mockUserErr == nil && tt.mockProductErr == nil {
+						mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), db.GetReservedProductQuantityParams{ProductID: productId}).Return(tt.mockReserved, nil).Times(1)
+						if tt.mockProduct.Quantity-tt.mockReserved >= int32(quantity) {
+							mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, pgx.ErrNoRows).Times(1)
+						}
 					}
 				}
 			}
@@ -281,6 +323,20 @@ func TestUserOrderProductHandler(t *testing.T) {
 				}
 				if tt.mockDeductUserErr == nil && tt.mockDeductProductErr == nil {
 					mockDB.EXPECT().DeductProductQuantity(gomock.Any(), gomock.Any()).Return(tt.mockDeductProductRows, tt.mockDeductProductErr).Times(1)
+					mockDB.EXPECT().CreateOrder(gomock.Any(), db.CreateOrderParams{
+						UserID:    userId,
+						TotalCost: tt.mockProduct.Price.Mul(decimal.NewFromInt(int64(quantity))),
+						Status:    OrderStatusCompleted,
+					}).Return(db.Order{ID: orderId, UserID: userId, Status: OrderStatusCompleted}, nil).Times(1)
+					mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+					mockDB.EXPECT().CreateOrderItem(gomock.Any(), gomock.Any()).Return(db.OrderItem{OrderID: orderId}, nil).Times(1)
+					mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(ctx context.Context, arg db.CreateInventoryMovementParams) (db.InventoryMovement, error) {
+							require.Equal(t, inventory.KindSale, arg.Kind)
+							require.Equal(t, -int32(quantity), arg.Quantity)
+							require.Equal(t, orderId, arg.OrderID)
+							return db.InventoryMovement{}, nil
+						}).Times(1)
 				}
 			} else if tt.name == "Failed to deduct user balance" && tt.mockUserErr == nil && tt.mockProductErr == nil {
 				mockDB.EXPECT().DeductUserBalance(gomock.Any(), gomock.Any()).Return(tt.mockDeductUserRows, tt.mockDeductUserErr).Times(1)
@@ -290,11 +346,17 @@ func TestUserOrderProductHandler(t *testing.T) {
 				for _, affiliate := range tt.mockAffiliateList {
 					mockDB.EXPECT().GetAffiliateByID(gomock.Any(), affiliate.ID).Return(affiliate, tt.mockAffiliateErr).Times(1)
 				}
+				mockDB.EXPECT().GetCommissionOverrideByProductID(gomock.Any(), productId).Return(db.CommissionOverride{}, pgx.ErrNoRows).Times(1)
+				mockDB.EXPECT().GetCommissionPlanInForce(gomock.Any(), gomock.Any()).Return(db.CommissionPlan{
+					ID:    planId,
+					Name:  "Default",
+					Rates: []float64{0.20, 0.15, 0.10, 0.05},
+				}, nil).Times(1)
 				if tt.mockCreateCommissionErr == nil {
 					mockDB.EXPECT().CreateCommission(gomock.Any(), gomock.Any()).
 						DoAndReturn(func(ctx context.Context, params db.CreateCommissionParams) (db.Commission, error) {
 							for _, aff := range tt.mockAffiliateList {
-								if aff.ID == params.AffiliateID {
+								if aff.ID == params.AffiliateID && params.PlanID == planId && params.Rule.String == CommissionRulePlan {
 									return db.Commission{}, tt.mockCreateCommissionErr
 								}
 							}
@@ -310,12 +372,15 @@ func TestUserOrderProductHandler(t *testing.T) {
 							}
 							return fmt.Errorf("unexpected affiliate ID")
 						}).Times(len(tt.mockAffiliateList))
+
+					mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2 * len(tt.mockAffiliateList))
 				}
 			}
 
 			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
 			router.POST("/users/order", func(c *gin.Context) {
-				NewHandler(mockDB).UserOrderProductHandler(c)
+				NewHandler(mockdb.NewFakeStore(mockDB), testKeys).UserOrderProductHandler(c)
 			})
 
 			req, err := http.NewRequest(http.MethodPost, "/users/order", bytes.NewBuffer(body))
@@ -336,3 +401,359 @@ func TestUserOrderProductHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestUserOrderProductHandlerRollback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	affiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174004")
+
+	user := db.GetUserDetailByIDForUpdateRow{ID: userId, Balance: decimal.NewFromInt(1000), AffiliateID: affiliateId}
+	product := db.Product{ID: productId, Quantity: 100, Price: decimal.NewFromInt(100)}
+	failure := errors.New("db error")
+
+	tests := []struct {
+		name           string
+		failAt         string
+		commitErr      error
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "Reserved quantity lookup fails", failAt: "GetReservedProductQuantity", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to get reserved quantity"},
+		{name: "Price lookup fails", failAt: "GetProductPriceInForce", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to get product price"},
+		{name: "Deduct user balance fails", failAt: "DeductUserBalance", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to deduct balance"},
+		{name: "Balance spent by a concurrent order", failAt: "BalanceSpent", expectedStatus: http.StatusBadRequest, expectedError: "Not enough balance"},
+		{name: "Stock sold by a concurrent order", failAt: "StockSold", expectedStatus: http.StatusBadRequest, expectedError: "Not enough product in stock"},
+		{name: "Deduct product quantity fails", failAt: "DeductProductQuantity", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to deduct product quantity"},
+		{name: "Create order fails", failAt: "CreateOrder", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to create order"},
+		{name: "Purchase ledger entry fails", failAt: "RecordPurchase", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to record ledger entry"},
+		{name: "Create order item fails", failAt: "CreateOrderItem", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to create order item"},
+		{name: "Sale inventory movement fails", failAt: "RecordSale", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to record inventory movement"},
+		{name: "Commission rule lookup fails", failAt: "ResolveCommissionRule", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to resolve commission rule"},
+		{name: "Create commission fails", failAt: "CreateCommission", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to create commission"},
+		{name: "Add affiliate balance fails", failAt: "AddAffiliateBalance", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to add affiliate balance"},
+		{name: "Commission ledger entry fails", failAt: "RecordCommission", expectedStatus: http.StatusInternalServerError, expectedError: "Failed to record ledger entry"},
+		{name: "Commit fails", commitErr: failure, expectedStatus: http.StatusInternalServerError, expectedError: "Failed to commit transaction"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			store := mockdb.NewFakeStore(mockDB)
+			store.CommitErr = tt.commitErr
+
+			errAt := func(step string) error {
+				if step == tt.failAt {
+					return failure
+				}
+				return nil
+			}
+
+			func() {
+				mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(user, nil).Times(1)
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(product, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), gomock.Any()).Return(int32(0), errAt("GetReservedProductQuantity")).Times(1)
+				if tt.failAt == "GetReservedProductQuantity" {
+					return
+				}
+
+				if tt.failAt == "GetProductPriceInForce" {
+					mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, failure).Times(1)
+					return
+				}
+				mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).Return(db.ProductPrice{}, pgx.ErrNoRows).Times(1)
+
+				rowsAt := func(step string) int64 {
+					if step == tt.failAt {
+						return 0
+					}
+					return 1
+				}
+
+				mockDB.EXPECT().DeductUserBalance(gomock.Any(), gomock.Any()).Return(rowsAt("BalanceSpent"), errAt("DeductUserBalance")).Times(1)
+				if tt.failAt == "DeductUserBalance" || tt.failAt == "BalanceSpent" {
+					return
+				}
+
+				mockDB.EXPECT().DeductProductQuantity(gomock.Any(), gomock.Any()).Return(rowsAt("StockSold"), errAt("DeductProductQuantity")).Times(1)
+				if tt.failAt == "DeductProductQuantity" || tt.failAt == "StockSold" {
+					return
+				}
+
+				mockDB.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(db.Order{ID: orderId, UserID: userId}, errAt("CreateOrder")).Times(1)
+				if tt.failAt == "CreateOrder" {
+					return
+				}
+
+				if tt.failAt == "RecordPurchase" {
+					mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, failure).Times(1)
+					return
+				}
+				mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+
+				mockDB.EXPECT().CreateOrderItem(gomock.Any(), gomock.Any()).Return(db.OrderItem{}, errAt("CreateOrderItem")).Times(1)
+				if tt.failAt == "CreateOrderItem" {
+					return
+				}
+
+				mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, errAt("RecordSale")).Times(1)
+				if tt.failAt == "RecordSale" {
+					return
+				}
+
+				mockDB.EXPECT().GetAffiliateByID(gomock.Any(), affiliateId).Return(db.Affiliate{ID: affiliateId}, nil).Times(1)
+				mockDB.EXPECT().GetCommissionOverrideByProductID(gomock.Any(), productId).Return(db.CommissionOverride{}, pgx.ErrNoRows).Times(1)
+				mockDB.EXPECT().GetCommissionPlanInForce(gomock.Any(), gomock.Any()).Return(db.CommissionPlan{Rates: []float64{0.10}}, errAt("ResolveCommissionRule")).Times(1)
+				if tt.failAt == "ResolveCommissionRule" {
+					return
+				}
+
+				mockDB.EXPECT().CreateCommission(gomock.Any(), gomock.Any()).Return(db.Commission{}, errAt("CreateCommission")).Times(1)
+				if tt.failAt == "CreateCommission" {
+					return
+				}
+
+				mockDB.EXPECT().AddAffiliateBalance(gomock.Any(), gomock.Any()).Return(errAt("AddAffiliateBalance")).Times(1)
+				if tt.failAt == "AddAffiliateBalance" {
+					return
+				}
+
+				if tt.failAt == "RecordCommission" {
+					mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, failure).Times(1)
+					return
+				}
+				mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+			}()
+
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/users/order", func(c *gin.Context) {
+				NewHandler(store, testKeys).UserOrderProductHandler(c)
+			})
+
+			body, err := json.Marshal(OrderRequest{UserID: userId, ProductID: productId, Quantity: 1})
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/users/order", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Contains(t, recorder.Body.String(), tt.expectedError)
+			require.Equal(t, 0, store.Commits)
+			require.Equal(t, 1, store.Rollbacks)
+		})
+	}
+}
+
+func TestUserOrderProductHandlerWithReservation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	otherId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	reservationId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+
+	user := db.GetUserDetailByIDForUpdateRow{ID: userId, Balance: decimal.NewFromInt(1000)}
+	reservation := db.StockReservation{
+		ID:        reservationId,
+		ProductID: productId,
+		UserID:    userId,
+		Quantity:  2,
+		Status:    ReservationStatusActive,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+
+	tests := []struct {
+		name           string
+		req            OrderRequest
+		buildStubs     func(mockDB *mockdb.MockQuerier)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Reservation not found",
+			req:  OrderRequest{UserID: userId, ReservationID: reservationId},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(user, nil).Times(1)
+				mockDB.EXPECT().GetStockReservationByIDForUpdate(gomock.Any(), reservationId).Return(db.StockReservation{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Reservation not found",
+		},
+		{
+			name: "Reservation of another user",
+			req:  OrderRequest{UserID: userId, ReservationID: reservationId},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				other := reservation
+				other.UserID = otherId
+				mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(user, nil).Times(1)
+				mockDB.EXPECT().GetStockReservationByIDForUpdate(gomock.Any(), reservationId).Return(other, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Reservation belongs to another user",
+		},
+		{
+			name: "Different product",
+			req:  OrderRequest{UserID: userId, ProductID: otherId, ReservationID: reservationId},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(user, nil).Times(1)
+				mockDB.EXPECT().GetStockReservationByIDForUpdate(gomock.Any(), reservationId).Return(reservation, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Reservation is for a different product",
+		},
+		{
+			name: "Quantity does not match",
+			req:  OrderRequest{UserID: userId, Quantity: 5, ReservationID: reservationId},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(user, nil).Times(1)
+				mockDB.EXPECT().GetStockReservationByIDForUpdate(gomock.Any(), reservationId).Return(reservation, nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Quantity does not match the reservation",
+		},
+		{
+			name: "Reserved stock was adjusted away",
+			req:  OrderRequest{UserID: userId, ProductID: productId, Quantity: 2, ReservationID: reservationId},
+			buildStubs: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(user, nil).Times(1)
+				mockDB.EXPECT().GetStockReservationByIDForUpdate(gomock.Any(), reservationId).Return(reservation, nil).Times(1)
+				mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(db.Product{ID: productId, Quantity: 1, Price: decimal.NewFromInt(100)}, nil).Times(1)
+				mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), db.GetReservedProductQuantityParams{
+					ProductID: productId,
+					ExcludeID: reservationId,
+				}).Return(int32(0), nil).Times(1)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Not enough product in stock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.buildStubs(mockDB)
+			store := mockdb.NewFakeStore(mockDB)
+
+			router := gin.New()
+			router.Use(withPrincipal(testAdmin))
+			router.POST("/users/order", NewHandler(store, testKeys).UserOrderProductHandler)
+
+			body, err := json.Marshal(tt.req)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/users/order", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Contains(t, recorder.Body.String(), tt.expectedError)
+			require.Equal(t, 0, store.Commits)
+		})
+	}
+}
+
+func TestUserOrderProductHandlerWithScheduledPrice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	priceId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+
+	listPrice := decimal.NewFromInt(100)
+	salePrice := decimal.NewFromInt(80)
+	total := decimal.NewFromInt(160)
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+	mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), userId).Return(db.GetUserDetailByIDForUpdateRow{ID: userId, Balance: decimal.NewFromInt(1000)}, nil).Times(1)
+	mockDB.EXPECT().GetProductByIDForUpdate(gomock.Any(), productId).Return(db.Product{ID: productId, Quantity: 10, Price: listPrice}, nil).Times(1)
+	mockDB.EXPECT().GetReservedProductQuantity(gomock.Any(), gomock.Any()).Return(int32(0), nil).Times(1)
+	mockDB.EXPECT().GetProductPriceInForce(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, arg db.GetProductPriceInForceParams) (db.ProductPrice, error) {
+			require.Equal(t, productId, arg.ProductID)
+			require.WithinDuration(t, time.Now(), arg.At.Time, time.Minute)
+			return db.ProductPrice{ID: priceId, ProductID: productId, Price: salePrice}, nil
+		}).Times(1)
+	mockDB.EXPECT().DeductUserBalance(gomock.Any(), db.DeductUserBalanceParams{Balance: total, ID: userId}).Return(int64(1), nil).Times(1)
+	mockDB.EXPECT().DeductProductQuantity(gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+	mockDB.EXPECT().CreateOrder(gomock.Any(), db.CreateOrderParams{
+		UserID:    userId,
+		TotalCost: total,
+		Status:    OrderStatusCompleted,
+	}).Return(db.Order{ID: orderId, UserID: userId}, nil).Times(1)
+	mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, nil).Times(2)
+	mockDB.EXPECT().CreateOrderItem(gomock.Any(), db.CreateOrderItemParams{
+		OrderID:    orderId,
+		ProductID:  productId,
+		Quantity:   2,
+		UnitPrice:  salePrice,
+		TotalPrice: total,
+		ListPrice:  listPrice,
+		PriceID:    priceId,
+	}).Return(db.OrderItem{}, nil).Times(1)
+	mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, nil).Times(1)
+
+	router := gin.New()
+	router.Use(withPrincipal(testAdmin))
+	router.POST("/users/order", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).UserOrderProductHandler)
+
+	body, err := json.Marshal(OrderRequest{UserID: userId, ProductID: productId, Quantity: 2})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/users/order", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	var response map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Equal(t, "160", response["total_cost"])
+}
+
+func TestUserOrderProductHandlerDefaultsToCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	caller := auth.Principal{UserID: helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000"), Role: auth.RoleCustomer}
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+	mockDB.EXPECT().GetUserDetailByIDForUpdate(gomock.Any(), caller.UserID).Return(db.GetUserDetailByIDForUpdateRow{}, pgx.ErrNoRows).Times(1)
+
+	router := gin.New()
+	router.Use(withPrincipal(caller))
+	router.POST("/users/order", NewHandler(mockdb.NewFakeStore(mockDB), testKeys).UserOrderProductHandler)
+
+	body, err := json.Marshal(gin.H{"product_id": productId, "quantity": 1})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/users/order", bytes.NewBuffer(body))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.JSONEq(t, `{"error":"User not found"}`, recorder.Body.String())
+}
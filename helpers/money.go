@@ -0,0 +1,14 @@
+package helpers
+
+import "github.com/shopspring/decimal"
+
+// MoneyPlaces is the number of decimal places money is stored with.
+const MoneyPlaces = 2
+
+// RoundMoney rounds an amount to the cent, half away from zero, so 0.125
+// becomes 0.13 and -0.125 becomes -0.13. Every computed amount that is not
+// already a sum or multiple of stored amounts, such as a commission share or
+// a pro-rata clawback, goes through this before it is stored or returned.
+func RoundMoney(amount decimal.Decimal) decimal.Decimal {
+	return amount.Round(MoneyPlaces)
+}
@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundMoney(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   string
+		expected string
+	}{
+		{
+			name:     "Already rounded",
+			amount:   "12.34",
+			expected: "12.34",
+		},
+		{
+			name:     "Half rounds up",
+			amount:   "0.125",
+			expected: "0.13",
+		},
+		{
+			name:     "Below half rounds down",
+			amount:   "0.1249",
+			expected: "0.12",
+		},
+		{
+			name:     "Negative half rounds away from zero",
+			amount:   "-0.125",
+			expected: "-0.13",
+		},
+		{
+			name:     "Float artefact",
+			amount:   "50.00000000000001",
+			expected: "50",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rounded := RoundMoney(decimal.RequireFromString(tc.amount))
+			require.True(t, decimal.RequireFromString(tc.expected).Equal(rounded), "got %s", rounded)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package inventory records every change to the stock of a product as a
+// movement, so the quantity of a product can be explained, and checked, by
+// summing its movements.
+package inventory
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Movement kinds.
+const (
+	KindOpeningBalance = "opening_balance"
+	KindInitialStock   = "initial_stock"
+	KindSale           = "sale"
+	KindRestock        = "restock"
+	KindReturn         = "return"
+	KindAdjustment     = "adjustment"
+)
+
+var (
+	ErrZeroQuantity = errors.New("inventory movement quantity must not be zero")
+	ErrWrongSign    = errors.New("inventory movement quantity has the wrong sign for its kind")
+)
+
+// Movement changes the stock of a product by Quantity, positive for stock
+// coming in. Actor is who made the change, empty for the system, and Reason
+// the human readable explanation shown in the product's history.
+type Movement struct {
+	ProductID pgtype.UUID
+	Kind      string
+	Quantity  int32
+	Reason    string
+	Actor     string
+	OrderID   pgtype.UUID
+}
+
+// InitialStock records the stock a product is created with.
+func InitialStock(productID pgtype.UUID, quantity int32) Movement {
+	return Movement{ProductID: productID, Kind: KindInitialStock, Quantity: quantity, Reason: "Initial stock"}
+}
+
+// Sale records stock leaving with an order.
+func Sale(productID pgtype.UUID, orderID pgtype.UUID, quantity int32) Movement {
+	return Movement{ProductID: productID, Kind: KindSale, Quantity: -quantity, Reason: "Order sale", OrderID: orderID}
+}
+
+// Restock records stock received. An empty reason gets a default.
+func Restock(productID pgtype.UUID, quantity int32, reason string) Movement {
+	if reason == "" {
+		reason = "Restock"
+	}
+	return Movement{ProductID: productID, Kind: KindRestock, Quantity: quantity, Reason: reason}
+}
+
+// Return records stock coming back when an order is refunded or cancelled.
+// An empty reason gets a default.
+func Return(productID pgtype.UUID, orderID pgtype.UUID, quantity int32, reason string) Movement {
+	if reason == "" {
+		reason = "Returned on refund"
+	}
+	return Movement{ProductID: productID, Kind: KindReturn, Quantity: quantity, Reason: reason, OrderID: orderID}
+}
+
+// Adjustment records a manual correction of the stock in either direction.
+func Adjustment(productID pgtype.UUID, quantity int32, reason string) Movement {
+	return Movement{ProductID: productID, Kind: KindAdjustment, Quantity: quantity, Reason: reason}
+}
+
+// Validate checks that the movement changes the stock, in the direction its
+// kind allows.
+func (m Movement) Validate() error {
+	if m.Quantity == 0 {
+		return ErrZeroQuantity
+	}
+
+	switch m.Kind {
+	case KindSale:
+		if m.Quantity > 0 {
+			return ErrWrongSign
+		}
+	case KindInitialStock, KindRestock, KindReturn:
+		if m.Quantity < 0 {
+			return ErrWrongSign
+		}
+	}
+
+	return nil
+}
+
+// Record writes m. It should run on the same database transaction as the
+// stock update it explains.
+func Record(ctx context.Context, q db.Querier, m Movement) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	_, err := q.CreateInventoryMovement(ctx, db.CreateInventoryMovementParams{
+		ProductID: m.ProductID,
+		Kind:      m.Kind,
+		Quantity:  m.Quantity,
+		Reason:    pgtype.Text{String: m.Reason, Valid: m.Reason != ""},
+		Actor:     pgtype.Text{String: m.Actor, Valid: m.Actor != ""},
+		OrderID:   m.OrderID,
+	})
+	return err
+}
@@ -0,0 +1,113 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMovementBuilders(t *testing.T) {
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	tests := []struct {
+		name             string
+		movement         Movement
+		expectedKind     string
+		expectedQuantity int32
+		expectedReason   string
+		expectedOrder    bool
+	}{
+		{name: "Initial stock", movement: InitialStock(productId, 10), expectedKind: KindInitialStock, expectedQuantity: 10, expectedReason: "Initial stock"},
+		{name: "Sale", movement: Sale(productId, orderId, 3), expectedKind: KindSale, expectedQuantity: -3, expectedReason: "Order sale", expectedOrder: true},
+		{name: "Restock", movement: Restock(productId, 5, ""), expectedKind: KindRestock, expectedQuantity: 5, expectedReason: "Restock"},
+		{name: "Restock with reason", movement: Restock(productId, 5, "Supplier delivery"), expectedKind: KindRestock, expectedQuantity: 5, expectedReason: "Supplier delivery"},
+		{name: "Return", movement: Return(productId, orderId, 2, ""), expectedKind: KindReturn, expectedQuantity: 2, expectedReason: "Returned on refund", expectedOrder: true},
+		{name: "Return on cancellation", movement: Return(productId, orderId, 2, "Order cancelled"), expectedKind: KindReturn, expectedQuantity: 2, expectedReason: "Order cancelled", expectedOrder: true},
+		{name: "Adjustment", movement: Adjustment(productId, -4, "Damaged"), expectedKind: KindAdjustment, expectedQuantity: -4, expectedReason: "Damaged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.movement.Validate())
+			require.Equal(t, productId, tt.movement.ProductID)
+			require.Equal(t, tt.expectedKind, tt.movement.Kind)
+			require.Equal(t, tt.expectedQuantity, tt.movement.Quantity)
+			require.Equal(t, tt.expectedReason, tt.movement.Reason)
+			require.Equal(t, tt.expectedOrder, tt.movement.OrderID.Valid)
+		})
+	}
+}
+
+func TestMovementValidate(t *testing.T) {
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name     string
+		movement Movement
+		err      error
+	}{
+		{name: "Zero quantity", movement: Adjustment(productId, 0, "Count"), err: ErrZeroQuantity},
+		{name: "Sale adding stock", movement: Movement{ProductID: productId, Kind: KindSale, Quantity: 1}, err: ErrWrongSign},
+		{name: "Restock removing stock", movement: Restock(productId, -1, ""), err: ErrWrongSign},
+		{name: "Return removing stock", movement: Return(productId, pgtype.UUID{}, -1, ""), err: ErrWrongSign},
+		{name: "Negative adjustment", movement: Adjustment(productId, -1, "Count")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.movement.Validate()
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+
+	t.Run("Writes the movement with its actor", func(t *testing.T) {
+		mockDB := mockdb.NewMockQuerier(ctrl)
+		mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), db.CreateInventoryMovementParams{
+			ProductID: productId,
+			Kind:      KindSale,
+			Quantity:  -2,
+			Reason:    pgtype.Text{String: "Order sale", Valid: true},
+			Actor:     pgtype.Text{String: "user-1", Valid: true},
+			OrderID:   orderId,
+		}).Return(db.InventoryMovement{}, nil).Times(1)
+
+		movement := Sale(productId, orderId, 2)
+		movement.Actor = "user-1"
+		require.NoError(t, Record(context.Background(), mockDB, movement))
+	})
+
+	t.Run("Rejects invalid movements without writing", func(t *testing.T) {
+		mockDB := mockdb.NewMockQuerier(ctrl)
+
+		err := Record(context.Background(), mockDB, Restock(productId, 0, ""))
+		require.ErrorIs(t, err, ErrZeroQuantity)
+	})
+
+	t.Run("Returns database errors", func(t *testing.T) {
+		mockDB := mockdb.NewMockQuerier(ctrl)
+		mockDB.EXPECT().CreateInventoryMovement(gomock.Any(), gomock.Any()).Return(db.InventoryMovement{}, errors.New("db error")).Times(1)
+
+		err := Record(context.Background(), mockDB, Restock(productId, 1, ""))
+		require.EqualError(t, err, "db error")
+	})
+}
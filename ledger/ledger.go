@@ -0,0 +1,152 @@
+// Package ledger records balance movements as double-entry transactions. Each
+// transaction is a set of postings that sum to zero, so every amount credited
+// to a user or affiliate is matched by a platform account and any balance can
+// be explained by summing its postings.
+package ledger
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// Accounts. User and affiliate postings carry the holder's ID, the others
+// belong to the platform.
+const (
+	AccountUser              = "user"
+	AccountAffiliate         = "affiliate"
+	AccountExternal          = "external"
+	AccountSales             = "sales"
+	AccountCommissionExpense = "commission_expense"
+)
+
+// Transaction kinds.
+const (
+	KindOpeningBalance     = "opening_balance"
+	KindTopUp              = "top_up"
+	KindDeduction          = "deduction"
+	KindPurchase           = "purchase"
+	KindRefund             = "refund"
+	KindCommission         = "commission"
+	KindCommissionReversal = "commission_reversal"
+)
+
+var (
+	ErrTooFewPostings = errors.New("ledger transaction needs at least two postings")
+	ErrUnbalanced     = errors.New("ledger transaction postings do not sum to zero")
+)
+
+// Posting moves Amount into an account. A positive amount increases the
+// account's balance and a negative amount decreases it.
+type Posting struct {
+	Account   string
+	AccountID pgtype.UUID
+	Amount    decimal.Decimal
+}
+
+// Transaction is one balance movement and the order or commission behind it.
+// Reason is the human readable explanation shown in the account history.
+type Transaction struct {
+	Kind         string
+	Reason       string
+	OrderID      pgtype.UUID
+	CommissionID pgtype.UUID
+	Postings     []Posting
+}
+
+// transfer builds a transaction moving amount from one account to another.
+func transfer(kind string, reason string, from Posting, to Posting, amount decimal.Decimal) Transaction {
+	from.Amount = amount.Neg()
+	to.Amount = amount
+	return Transaction{Kind: kind, Reason: reason, Postings: []Posting{from, to}}
+}
+
+// TopUp credits a user with money paid in from outside the platform.
+func TopUp(userID pgtype.UUID, amount decimal.Decimal) Transaction {
+	return transfer(KindTopUp, "Balance top-up", Posting{Account: AccountExternal}, Posting{Account: AccountUser, AccountID: userID}, amount)
+}
+
+// Deduction debits a user for money paid out of the platform.
+func Deduction(userID pgtype.UUID, amount decimal.Decimal) Transaction {
+	return transfer(KindDeduction, "Balance deduction", Posting{Account: AccountUser, AccountID: userID}, Posting{Account: AccountExternal}, amount)
+}
+
+// Purchase debits a user for an order.
+func Purchase(userID pgtype.UUID, orderID pgtype.UUID, amount decimal.Decimal) Transaction {
+	t := transfer(KindPurchase, "Order purchase", Posting{Account: AccountUser, AccountID: userID}, Posting{Account: AccountSales}, amount)
+	t.OrderID = orderID
+	return t
+}
+
+// Refund credits a user with money returned on an order.
+func Refund(userID pgtype.UUID, orderID pgtype.UUID, amount decimal.Decimal) Transaction {
+	t := transfer(KindRefund, "Order refund", Posting{Account: AccountSales}, Posting{Account: AccountUser, AccountID: userID}, amount)
+	t.OrderID = orderID
+	return t
+}
+
+// Commission credits an affiliate with a commission paid on an order.
+func Commission(affiliateID pgtype.UUID, orderID pgtype.UUID, commissionID pgtype.UUID, amount decimal.Decimal) Transaction {
+	t := transfer(KindCommission, "Commission earned", Posting{Account: AccountCommissionExpense}, Posting{Account: AccountAffiliate, AccountID: affiliateID}, amount)
+	t.OrderID = orderID
+	t.CommissionID = commissionID
+	return t
+}
+
+// CommissionReversal debits an affiliate for a commission clawed back on a
+// refund. commissionID is the reversal row.
+func CommissionReversal(affiliateID pgtype.UUID, orderID pgtype.UUID, commissionID pgtype.UUID, amount decimal.Decimal) Transaction {
+	t := transfer(KindCommissionReversal, "Commission reversed on refund", Posting{Account: AccountAffiliate, AccountID: affiliateID}, Posting{Account: AccountCommissionExpense}, amount)
+	t.OrderID = orderID
+	t.CommissionID = commissionID
+	return t
+}
+
+// Validate checks that the transaction has postings on both sides and that
+// they sum to zero.
+func (t Transaction) Validate() error {
+	if len(t.Postings) < 2 {
+		return ErrTooFewPostings
+	}
+
+	sum := decimal.Zero
+	for _, posting := range t.Postings {
+		sum = sum.Add(posting.Amount)
+	}
+	if !sum.IsZero() {
+		return ErrUnbalanced
+	}
+
+	return nil
+}
+
+// Record writes the postings of t under a new transaction ID. It should run
+// on the same database transaction as the balance update it explains.
+func Record(ctx context.Context, q db.Querier, t Transaction) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	transactionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	for _, posting := range t.Postings {
+		_, err := q.CreateLedgerEntry(ctx, db.CreateLedgerEntryParams{
+			TransactionID: transactionID,
+			Kind:          t.Kind,
+			Account:       posting.Account,
+			AccountID:     posting.AccountID,
+			Amount:        posting.Amount,
+			OrderID:       t.OrderID,
+			CommissionID:  t.CommissionID,
+			Reason:        pgtype.Text{String: t.Reason, Valid: t.Reason != ""},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
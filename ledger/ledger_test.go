@@ -0,0 +1,148 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/helpers"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionBuilders(t *testing.T) {
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	affiliateId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174001")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+	commissionId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174003")
+	amount := decimal.RequireFromString("12.34")
+
+	tests := []struct {
+		name            string
+		transaction     Transaction
+		expectedKind    string
+		expectedAccount string
+		expectedSign    int
+	}{
+		{name: "Top up", transaction: TopUp(userId, amount), expectedKind: KindTopUp, expectedAccount: AccountUser, expectedSign: 1},
+		{name: "Deduction", transaction: Deduction(userId, amount), expectedKind: KindDeduction, expectedAccount: AccountUser, expectedSign: -1},
+		{name: "Purchase", transaction: Purchase(userId, orderId, amount), expectedKind: KindPurchase, expectedAccount: AccountUser, expectedSign: -1},
+		{name: "Refund", transaction: Refund(userId, orderId, amount), expectedKind: KindRefund, expectedAccount: AccountUser, expectedSign: 1},
+		{name: "Commission", transaction: Commission(affiliateId, orderId, commissionId, amount), expectedKind: KindCommission, expectedAccount: AccountAffiliate, expectedSign: 1},
+		{name: "Commission reversal", transaction: CommissionReversal(affiliateId, orderId, commissionId, amount), expectedKind: KindCommissionReversal, expectedAccount: AccountAffiliate, expectedSign: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.transaction.Validate())
+			require.Equal(t, tt.expectedKind, tt.transaction.Kind)
+			require.NotEmpty(t, tt.transaction.Reason)
+
+			var holder *Posting
+			for i := range tt.transaction.Postings {
+				if tt.transaction.Postings[i].Account == tt.expectedAccount {
+					holder = &tt.transaction.Postings[i]
+				}
+			}
+			require.NotNil(t, holder)
+			require.True(t, holder.AccountID.Valid)
+			require.Equal(t, tt.expectedSign, holder.Amount.Sign())
+			require.True(t, amount.Equal(holder.Amount.Abs()))
+		})
+	}
+}
+
+func TestTransactionValidate(t *testing.T) {
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+
+	tests := []struct {
+		name          string
+		transaction   Transaction
+		expectedError error
+	}{
+		{
+			name:        "Balanced",
+			transaction: TopUp(userId, decimal.NewFromInt(10)),
+		},
+		{
+			name: "Single posting",
+			transaction: Transaction{Kind: KindTopUp, Postings: []Posting{
+				{Account: AccountUser, AccountID: userId, Amount: decimal.NewFromInt(10)},
+			}},
+			expectedError: ErrTooFewPostings,
+		},
+		{
+			name: "Unbalanced",
+			transaction: Transaction{Kind: KindTopUp, Postings: []Posting{
+				{Account: AccountUser, AccountID: userId, Amount: decimal.NewFromInt(10)},
+				{Account: AccountExternal, Amount: decimal.NewFromInt(-9)},
+			}},
+			expectedError: ErrUnbalanced,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.transaction.Validate()
+			if tt.expectedError == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174000")
+	orderId := helpers.PgtypeUUID(t, "123e4567-e89b-12d3-a456-426614174002")
+
+	t.Run("Writes every posting under one transaction", func(t *testing.T) {
+		mockDB := mockdb.NewMockQuerier(ctrl)
+
+		var params []db.CreateLedgerEntryParams
+		mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, arg db.CreateLedgerEntryParams) (db.LedgerEntry, error) {
+				params = append(params, arg)
+				return db.LedgerEntry{}, nil
+			}).Times(2)
+
+		err := Record(context.Background(), mockDB, Purchase(userId, orderId, decimal.NewFromInt(50)))
+		require.NoError(t, err)
+
+		require.Len(t, params, 2)
+		require.True(t, params[0].TransactionID.Valid)
+		require.Equal(t, params[0].TransactionID, params[1].TransactionID)
+		require.True(t, params[0].Amount.Add(params[1].Amount).IsZero())
+		for _, arg := range params {
+			require.Equal(t, KindPurchase, arg.Kind)
+			require.Equal(t, orderId, arg.OrderID)
+			require.Equal(t, pgtype.Text{String: "Order purchase", Valid: true}, arg.Reason)
+		}
+	})
+
+	t.Run("Rejects unbalanced transactions without writing", func(t *testing.T) {
+		mockDB := mockdb.NewMockQuerier(ctrl)
+
+		err := Record(context.Background(), mockDB, Transaction{Kind: KindTopUp, Postings: []Posting{
+			{Account: AccountUser, AccountID: userId, Amount: decimal.NewFromInt(10)},
+			{Account: AccountExternal, Amount: decimal.NewFromInt(-1)},
+		}})
+		require.ErrorIs(t, err, ErrUnbalanced)
+	})
+
+	t.Run("Returns database errors", func(t *testing.T) {
+		mockDB := mockdb.NewMockQuerier(ctrl)
+		mockDB.EXPECT().CreateLedgerEntry(gomock.Any(), gomock.Any()).Return(db.LedgerEntry{}, errors.New("db error")).Times(1)
+
+		err := Record(context.Background(), mockDB, TopUp(userId, decimal.NewFromInt(10)))
+		require.EqualError(t, err, "db error")
+	})
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
 	"github.com/buranasakS/trading_application/config"
 	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/buranasakS/trading_application/handlers"
+	"github.com/buranasakS/trading_application/reservation"
 	"github.com/buranasakS/trading_application/routes"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -23,24 +25,40 @@ import (
 // @name Authorization
 // @BasePath /
 func main() {
+	err := godotenv.Load(".env")
+	if err != nil {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
 
 	database := config.ConnectDatabase()
 	defer config.CloseDatabase(database)
 
-	queries := db.New(database.DB)	
-	h := handlers.NewHandler(queries)
+	keys, err := config.LoadKeySet()
+	if err != nil {
+		log.Fatalf("Invalid token keys: %v", err)
+	}
+
+	store := db.NewStore(database.DB)
+	h := handlers.NewHandler(store, keys)
+
+	sweepInterval, err := config.LoadReservationSweepInterval()
+	if err != nil {
+		log.Fatalf("Invalid reservation settings: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reservation.NewSweeper(store, sweepInterval).Run(ctx)
 
 	gin.SetMode(gin.DebugMode)
 	router := gin.Default()
 	router.Use(gin.Recovery())
-
-	routes.SetupRoutes(router, h)
-	
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
+	if err := router.SetTrustedProxies(config.LoadTrustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
 	}
 
+	routes.SetupRoutes(router, h, store, keys)
+
 	port := os.Getenv("PORT")
 	err = router.Run(":" + port)
 	if err != nil {
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/buranasakS/trading_application/auth"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+
+	idempotencyKeyContextKey  = "idempotency_key"
+	idempotencyHashContextKey = "idempotency_request_hash"
+)
+
+// ErrIdempotencyKeyUsed is returned by SaveIdempotentResponse when another
+// request stored the same key first. The caller must roll back its write.
+var ErrIdempotencyKeyUsed = errors.New("idempotency key already used")
+
+// IdempotencyMiddleware replays the stored response of a request carrying an
+// Idempotency-Key that already succeeded and rejects a key reused with a
+// different request with 422. Keys are per caller, so it runs after
+// JwtMiddleware. Requests without the header pass through. The handler
+// stores its response with SaveIdempotentResponse inside the transaction of
+// its write.
+func IdempotencyMiddleware(q db.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must not be longer than 255 characters"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(c.Request.Method, c.Request.URL.Path, body)
+		key = callerIdempotencyKey(c, key)
+
+		stored, err := q.GetIdempotencyKey(context.Background(), key)
+		if err == nil {
+			if stored.RequestHash != hash {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+				c.Abort()
+				return
+			}
+
+			c.Header(IdempotencyReplayedHeader, "true")
+			c.Data(int(stored.StatusCode), "application/json; charset=utf-8", stored.ResponseBody)
+			c.Abort()
+			return
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up Idempotency-Key"})
+			c.Abort()
+			return
+		}
+
+		c.Set(idempotencyKeyContextKey, key)
+		c.Set(idempotencyHashContextKey, hash)
+
+		c.Next()
+	}
+}
+
+// SaveIdempotentResponse stores the response of a request made with an
+// Idempotency-Key. Call it with the transaction of the write, before commit,
+// so the key and the write are kept or rolled back together. It does nothing
+// for requests without the header.
+func SaveIdempotentResponse(ctx context.Context, q db.Querier, c *gin.Context, status int, response any) error {
+	key := c.GetString(idempotencyKeyContextKey)
+	if key == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+		Key:          key,
+		RequestHash:  c.GetString(idempotencyHashContextKey),
+		StatusCode:   int32(status),
+		ResponseBody: body,
+	})
+	if err != nil {
+		return err
+	}
+
+	// A concurrent request with the same key committed while this one ran.
+	if rows == 0 {
+		return ErrIdempotencyKeyUsed
+	}
+
+	return nil
+}
+
+// callerIdempotencyKey prefixes key with the user ID of the caller. Bodies
+// no longer name the user, so without it two users sending the same key and
+// body would be taken for one request and the second would get the first's
+// response.
+func callerIdempotencyKey(c *gin.Context, key string) string {
+	principal, ok := auth.PrincipalFrom(c.Request.Context())
+	if !ok {
+		return key
+	}
+	return principal.UserID.String() + ":" + key
+}
+
+// requestHash identifies a request by its method, path and body. JSON bodies
+// are compacted first so a retry that only reformats the body still matches.
+func requestHash(method string, path string, body []byte) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err == nil {
+		body = compact.Bytes()
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/buranasakS/trading_application/auth"
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gin.SetMode(gin.TestMode)
+
+	body := `{"amount": "10.00"}`
+	storedHash := requestHash(http.MethodPatch, "/balance", []byte(`{"amount":"10.00"}`))
+
+	tests := []struct {
+		name            string
+		key             string
+		body            string
+		setupMock       func(mockDB *mockdb.MockQuerier)
+		expectedStatus  int
+		expectedBody    string
+		expectedReplay  bool
+		nextHandlerCall bool
+	}{
+		{
+			name:            "No key",
+			body:            body,
+			setupMock:       func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"message":"Add balance completed"}`,
+			nextHandlerCall: true,
+		},
+		{
+			name: "First request stores the response",
+			key:  "key-1",
+			body: body,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").Return(db.IdempotencyKey{}, pgx.ErrNoRows).Times(1)
+				mockDB.EXPECT().CreateIdempotencyKey(gomock.Any(), db.CreateIdempotencyKeyParams{
+					Key:          "key-1",
+					RequestHash:  storedHash,
+					StatusCode:   http.StatusOK,
+					ResponseBody: []byte(`{"message":"Add balance completed"}`),
+				}).Return(int64(1), nil).Times(1)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"message":"Add balance completed"}`,
+			nextHandlerCall: true,
+		},
+		{
+			name: "Retry replays the stored response",
+			key:  "key-1",
+			body: `{"amount":"10.00"}`,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").Return(db.IdempotencyKey{
+					Key:          "key-1",
+					RequestHash:  storedHash,
+					StatusCode:   http.StatusOK,
+					ResponseBody: []byte(`{"message":"Add balance completed"}`),
+				}, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"Add balance completed"}`,
+			expectedReplay: true,
+		},
+		{
+			name: "Key reused with a different body",
+			key:  "key-1",
+			body: `{"amount": "20.00"}`,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetIdempotencyKey(gomock.Any(), "key-1").Return(db.IdempotencyKey{
+					Key:         "key-1",
+					RequestHash: storedHash,
+				}, nil).Times(1)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"error":"Idempotency-Key was already used with a different request"}`,
+		},
+		{
+			name: "Concurrent request stored the key first",
+			key:  "key-2",
+			body: body,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetIdempotencyKey(gomock.Any(), "key-2").Return(db.IdempotencyKey{}, pgx.ErrNoRows).Times(1)
+				mockDB.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1)
+			},
+			expectedStatus:  http.StatusConflict,
+			expectedBody:    `{"error":"idempotency key already used"}`,
+			nextHandlerCall: true,
+		},
+		{
+			name:           "Key too long",
+			key:            strings.Repeat("k", 256),
+			body:           body,
+			setupMock:      func(mockDB *mockdb.MockQuerier) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Idempotency-Key must not be longer than 255 characters"}`,
+		},
+		{
+			name: "Database error",
+			key:  "key-3",
+			body: body,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetIdempotencyKey(gomock.Any(), "key-3").Return(db.IdempotencyKey{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"Failed to look up Idempotency-Key"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			tt.setupMock(mockDB)
+
+			nextHandlerCalled := false
+			router := gin.New()
+			router.PATCH("/balance", IdempotencyMiddleware(mockDB), func(c *gin.Context) {
+				nextHandlerCalled = true
+				response := gin.H{"message": "Add balance completed"}
+				if err := SaveIdempotentResponse(context.Background(), mockDB, c, http.StatusOK, response); err != nil {
+					c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+					return
+				}
+				c.JSON(http.StatusOK, response)
+			})
+
+			req := httptest.NewRequest(http.MethodPatch, "/balance", strings.NewReader(tt.body))
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.JSONEq(t, tt.expectedBody, recorder.Body.String())
+			require.Equal(t, tt.nextHandlerCall, nextHandlerCalled)
+			if tt.expectedReplay {
+				require.Equal(t, "true", recorder.Header().Get(IdempotencyReplayedHeader))
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewarePerCaller(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gin.SetMode(gin.TestMode)
+
+	callerA := auth.Principal{UserID: pgtype.UUID{Bytes: [16]byte{1}, Valid: true}, Role: auth.RoleCustomer}
+	callerB := auth.Principal{UserID: pgtype.UUID{Bytes: [16]byte{2}, Valid: true}, Role: auth.RoleCustomer}
+	body := `{"product_id":"123e4567-e89b-12d3-a456-426614174000","quantity":1}`
+	hash := requestHash(http.MethodPost, "/users/order", []byte(body))
+	storedA := db.IdempotencyKey{
+		Key:          callerA.UserID.String() + ":key-1",
+		RequestHash:  hash,
+		StatusCode:   http.StatusCreated,
+		ResponseBody: []byte(`{"order_id":"a"}`),
+	}
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+	gomock.InOrder(
+		mockDB.EXPECT().GetIdempotencyKey(gomock.Any(), storedA.Key).Return(db.IdempotencyKey{}, pgx.ErrNoRows).Times(1),
+		mockDB.EXPECT().CreateIdempotencyKey(gomock.Any(), db.CreateIdempotencyKeyParams{
+			Key:          storedA.Key,
+			RequestHash:  hash,
+			StatusCode:   http.StatusCreated,
+			ResponseBody: storedA.ResponseBody,
+		}).Return(int64(1), nil).Times(1),
+		// B's key of the same name is its own and not found.
+		mockDB.EXPECT().GetIdempotencyKey(gomock.Any(), callerB.UserID.String()+":key-1").Return(db.IdempotencyKey{}, pgx.ErrNoRows).Times(1),
+		mockDB.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, arg db.CreateIdempotencyKeyParams) (int64, error) {
+				require.Equal(t, callerB.UserID.String()+":key-1", arg.Key)
+				return 1, nil
+			}).Times(1),
+		// A's retry replays A's order.
+		mockDB.EXPECT().GetIdempotencyKey(gomock.Any(), storedA.Key).Return(storedA, nil).Times(1),
+	)
+
+	send := func(caller auth.Principal) (*httptest.ResponseRecorder, bool) {
+		placed := false
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), caller))
+		})
+		router.POST("/users/order", IdempotencyMiddleware(mockDB), func(c *gin.Context) {
+			placed = true
+			response := gin.H{"order_id": "a"}
+			if caller.UserID != callerA.UserID {
+				response = gin.H{"order_id": "b"}
+			}
+			require.NoError(t, SaveIdempotentResponse(context.Background(), mockDB, c, http.StatusCreated, response))
+			c.JSON(http.StatusCreated, response)
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/users/order", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder, placed
+	}
+
+	recorder, placed := send(callerA)
+	require.True(t, placed)
+	require.JSONEq(t, `{"order_id":"a"}`, recorder.Body.String())
+
+	recorder, placed = send(callerB)
+	require.True(t, placed, "B's order must be placed, not A's replayed")
+	require.Empty(t, recorder.Header().Get(IdempotencyReplayedHeader))
+	require.JSONEq(t, `{"order_id":"b"}`, recorder.Body.String())
+
+	recorder, placed = send(callerA)
+	require.False(t, placed)
+	require.Equal(t, "true", recorder.Header().Get(IdempotencyReplayedHeader))
+	require.JSONEq(t, `{"order_id":"a"}`, recorder.Body.String())
+}
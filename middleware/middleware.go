@@ -1,18 +1,21 @@
 package middleware
 
 import (
-	"context"
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
 	"strings"
-	"time"
 
+	"github.com/buranasakS/trading_application/auth"
+	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-func JwtMiddleware() gin.HandlerFunc {
+// JwtMiddleware accepts access tokens signed by one of keys that have not
+// expired and whose session (jti) has not been revoked. It puts the caller into the
+// request context as an auth.Principal.
+func JwtMiddleware(q db.Querier, keys *auth.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenHeader := c.GetHeader("Authorization")
 		if tokenHeader == "" {
@@ -28,42 +31,52 @@ func JwtMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		secretKey := os.Getenv("SECRET_KEY")
-		if secretKey == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"Error": "SECRET_KEY is not set"})
+		claims, err := auth.ParseAccessToken(keys, tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"Error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		claims := jwt.MapClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(secretKey), nil
-		})
+		var userID pgtype.UUID
+		if err := userID.Scan(claims.Subject); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"Error": "User ID not found in token"})
+			c.Abort()
+			return
+		}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"Error": "Invalid or expired token"})
+		if !auth.ValidRole(claims.Role) {
+			c.JSON(http.StatusUnauthorized, gin.H{"Error": "Role not found in token"})
 			c.Abort()
 			return
 		}
 
-		expiration := int64(claims["exp"].(float64))
-		if time.Now().Unix() > expiration {
-			c.JSON(http.StatusUnauthorized, gin.H{"Error": "Token has expired"})
+		var sessionID pgtype.UUID
+		if err := sessionID.Scan(claims.ID); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"Error": "Session ID not found in token"})
 			c.Abort()
 			return
 		}
 
-		userID, ok := claims["sub"].(string)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"Error": "User ID not found in token"})
+		session, err := q.GetAuthSessionByID(c.Request.Context(), sessionID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"Error": "Failed to check session"})
+			c.Abort()
+			return
+		}
+
+		if err != nil || session.RevokedAt.Valid || session.UserID != userID {
+			c.JSON(http.StatusUnauthorized, gin.H{"Error": "Session has been revoked"})
 			c.Abort()
 			return
 		}
 
-		ctx := context.WithValue(c.Request.Context(), "user_id", userID)
+		ctx := auth.WithPrincipal(c.Request.Context(), auth.Principal{
+			UserID:    userID,
+			SessionID: sessionID,
+			Username:  claims.Username,
+			Role:      claims.Role,
+		})
 		c.Request = c.Request.WithContext(ctx)
 
 		c.Next()
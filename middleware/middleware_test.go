@@ -1,56 +1,81 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 	"time"
 
+	"github.com/buranasakS/trading_application/auth"
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestJwtMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
 	gin.SetMode(gin.TestMode)
 
-	testSecretKey := "test_secret_key"
-	os.Setenv("SECRET_KEY", testSecretKey)
-	defer os.Unsetenv("SECRET_KEY")
+	key, err := auth.GenerateKey("test")
+	require.NoError(t, err)
+	keys, err := auth.NewKeySet(key.ID, key)
+	require.NoError(t, err)
+
+	userID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	sessionID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	userIDString := userID.String()
 
-	validToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": "testuser",
-		"exp": time.Now().Add(time.Hour).Unix(),
-		"iat": time.Now().Unix(),
-	})
-	validTokenString, err := validToken.SignedString([]byte(testSecretKey))
+	validTokenString, err := auth.NewAccessToken(keys, userID, sessionID, "testuser", auth.RoleCustomer, time.Now())
 	require.NoError(t, err)
 
-	expiredToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": "testuser",
-		"exp": time.Now().Add(-time.Hour).Unix(),
-		"iat": time.Now().Add(-2 * time.Hour).Unix(),
-	})
+	expiredTokenString, err := auth.NewAccessToken(keys, userID, sessionID, "testuser", auth.RoleCustomer, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
 
-	expiredTokenString, err := expiredToken.SignedString([]byte(testSecretKey))
+	otherKey, err := auth.GenerateKey("other")
+	require.NoError(t, err)
+	otherKeys, err := auth.NewKeySet(otherKey.ID, otherKey)
 	require.NoError(t, err)
+	unknownKeyTokenString, err := auth.NewAccessToken(otherKeys, userID, sessionID, "testuser", auth.RoleCustomer, time.Now())
+	require.NoError(t, err)
+
+	signClaims := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = key.ID
+		tokenString, err := token.SignedString(key.Private)
+		require.NoError(t, err)
+		return tokenString
+	}
+
+	activeSession := db.AuthSession{ID: sessionID, UserID: userID}
 
 	tests := []struct {
 		name            string
 		tokenHeader     string
+		setupMock       func(mockDB *mockdb.MockQuerier)
 		expectedStatus  int
 		expectedError   string
 		expectedUserID  string
 		testNextHandler bool
 	}{
 		{
-			name:            "Valid Token",
-			tokenHeader:     "Bearer " + validTokenString,
+			name:        "Valid Token",
+			tokenHeader: "Bearer " + validTokenString,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetAuthSessionByID(gomock.Any(), sessionID).Return(activeSession, nil).Times(1)
+			},
 			expectedStatus:  http.StatusOK,
 			expectedError:   "",
-			expectedUserID:  "testuser",
+			expectedUserID:  userIDString,
 			testNextHandler: true,
 		},
 		{
@@ -93,42 +118,126 @@ func TestJwtMiddleware(t *testing.T) {
 			expectedUserID:  "",
 			testNextHandler: false,
 		},
+		{
+			name:            "Unknown Signing Key",
+			tokenHeader:     "Bearer " + unknownKeyTokenString,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedError:   "Invalid or expired token",
+			expectedUserID:  "",
+			testNextHandler: false,
+		},
 		{
 			name: "Missing sub in token",
-			tokenHeader: "Bearer " + func() string {
-				missingSubToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-					"exp": time.Now().Add(time.Hour).Unix(),
-					"iat": time.Now().Unix(),
-				})
-				missingSubTokenString, err := missingSubToken.SignedString([]byte(testSecretKey))
-				require.NoError(t, err)
-				return missingSubTokenString
-			}(),
+			tokenHeader: "Bearer " + signClaims(jwt.MapClaims{
+				"exp": time.Now().Add(time.Hour).Unix(),
+				"iat": time.Now().Unix(),
+			}),
 			expectedStatus:  http.StatusUnauthorized,
 			expectedError:   "User ID not found in token",
 			expectedUserID:  "",
 			testNextHandler: false,
 		},
+		{
+			name: "Missing role in token",
+			tokenHeader: "Bearer " + signClaims(jwt.MapClaims{
+				"sub": userIDString,
+				"jti": sessionID.String(),
+				"exp": time.Now().Add(time.Hour).Unix(),
+				"iat": time.Now().Unix(),
+			}),
+			expectedStatus:  http.StatusUnauthorized,
+			expectedError:   "Role not found in token",
+			expectedUserID:  "",
+			testNextHandler: false,
+		},
+		{
+			name: "Missing jti in token",
+			tokenHeader: "Bearer " + signClaims(jwt.MapClaims{
+				"sub":  userIDString,
+				"role": auth.RoleCustomer,
+				"exp":  time.Now().Add(time.Hour).Unix(),
+				"iat":  time.Now().Unix(),
+			}),
+			expectedStatus:  http.StatusUnauthorized,
+			expectedError:   "Session ID not found in token",
+			expectedUserID:  "",
+			testNextHandler: false,
+		},
+		{
+			name:        "Revoked Session",
+			tokenHeader: "Bearer " + validTokenString,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				revoked := activeSession
+				revoked.RevokedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+				mockDB.EXPECT().GetAuthSessionByID(gomock.Any(), sessionID).Return(revoked, nil).Times(1)
+			},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedError:   "Session has been revoked",
+			expectedUserID:  "",
+			testNextHandler: false,
+		},
+		{
+			name:        "Unknown Session",
+			tokenHeader: "Bearer " + validTokenString,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetAuthSessionByID(gomock.Any(), sessionID).Return(db.AuthSession{}, pgx.ErrNoRows).Times(1)
+			},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedError:   "Session has been revoked",
+			expectedUserID:  "",
+			testNextHandler: false,
+		},
+		{
+			name:        "Session of another user",
+			tokenHeader: "Bearer " + validTokenString,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				other := activeSession
+				other.UserID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+				mockDB.EXPECT().GetAuthSessionByID(gomock.Any(), sessionID).Return(other, nil).Times(1)
+			},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedError:   "Session has been revoked",
+			expectedUserID:  "",
+			testNextHandler: false,
+		},
+		{
+			name:        "Session lookup fails",
+			tokenHeader: "Bearer " + validTokenString,
+			setupMock: func(mockDB *mockdb.MockQuerier) {
+				mockDB.EXPECT().GetAuthSessionByID(gomock.Any(), sessionID).Return(db.AuthSession{}, errors.New("db error")).Times(1)
+			},
+			expectedStatus:  http.StatusInternalServerError,
+			expectedError:   "Failed to check session",
+			expectedUserID:  "",
+			testNextHandler: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			if tt.setupMock != nil {
+				tt.setupMock(mockDB)
+			}
+
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
 			var userIDFromContext string
 			nextHandlerCalled := false
 
 			if tt.testNextHandler {
-				router.Use(JwtMiddleware())
+				router.Use(JwtMiddleware(mockDB, keys))
 				router.GET("/test", func(c *gin.Context) {
 					nextHandlerCalled = true
-					userIDFromContext = c.Request.Context().Value("user_id").(string)
+					principal, _ := auth.PrincipalFrom(c.Request.Context())
+					userIDFromContext = principal.UserID.String()
 					c.JSON(http.StatusOK, gin.H{"message": "success"})
 				})
 			} else {
-				router.GET("/test", JwtMiddleware(), func(c *gin.Context) {
+				router.GET("/test", JwtMiddleware(mockDB, keys), func(c *gin.Context) {
 					nextHandlerCalled = true
-					userIDFromContext = c.Request.Context().Value("user_id").(string)
+					principal, _ := auth.PrincipalFrom(c.Request.Context())
+					userIDFromContext = principal.UserID.String()
 					c.JSON(http.StatusOK, gin.H{"message": "success"})
 				})
 			}
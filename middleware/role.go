@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/buranasakS/trading_application/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole lets the request through only if the role of the principal
+// JwtMiddleware put into the request context is one of roles. It must run
+// after JwtMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.PrincipalFrom(c.Request.Context())
+		if !ok || !slices.Contains(roles, principal.Role) {
+			c.JSON(http.StatusForbidden, gin.H{"Error": "Forbidden"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
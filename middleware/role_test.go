@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buranasakS/trading_application/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		role           string
+		allowed        []string
+		expectedStatus int
+	}{
+		{name: "Allowed role", role: auth.RoleAdmin, allowed: []string{auth.RoleSupport, auth.RoleAdmin}, expectedStatus: http.StatusOK},
+		{name: "Other role", role: auth.RoleCustomer, allowed: []string{auth.RoleSupport, auth.RoleAdmin}, expectedStatus: http.StatusForbidden},
+		{name: "No role in context", allowed: []string{auth.RoleCustomer}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.role != "" {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), auth.Principal{Role: tt.role}))
+				}
+			})
+
+			nextHandlerCalled := false
+			router.GET("/test", RequireRole(tt.allowed...), func(c *gin.Context) {
+				nextHandlerCalled = true
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			require.Equal(t, tt.expectedStatus, recorder.Code)
+			require.Equal(t, tt.expectedStatus == http.StatusOK, nextHandlerCalled)
+			if tt.expectedStatus == http.StatusForbidden {
+				require.JSONEq(t, `{"Error":"Forbidden"}`, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Package pagination parses the paging, sorting and cursor parameters shared
+// by the list endpoints and builds the page metadata they return.
+//
+// A list is paged either by offset, with limit and page, or by keyset, with
+// limit and the next_cursor of the previous page. Keyset paging stays stable
+// while rows are inserted and does not get slower on deep pages.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("Invalid limit value. Must be a positive integer.")
+	ErrInvalidPage   = errors.New("Invalid page value. Must be a positive integer.")
+	ErrInvalidSort   = errors.New("Invalid sort value.")
+	ErrInvalidCursor = errors.New("Invalid cursor value.")
+	ErrPageAndCursor = errors.New("Use either page or cursor, not both.")
+)
+
+// Kind is the type of the values of a sortable field, which the value of a
+// cursor has to parse as before it reaches the query.
+type Kind int
+
+const (
+	KindText Kind = iota
+	KindNumber
+	// KindTime values are in RFC 3339 format.
+	KindTime
+)
+
+// numberPattern is the plain decimal notation cursors hold numbers in.
+var numberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Sort is a field a list can be sorted by.
+type Sort struct {
+	Field string
+	Kind  Kind
+}
+
+// Text, Number and Time return the sortable field of each kind.
+func Text(field string) Sort   { return Sort{Field: field, Kind: KindText} }
+func Number(field string) Sort { return Sort{Field: field, Kind: KindNumber} }
+func Time(field string) Sort   { return Sort{Field: field, Kind: KindTime} }
+
+// valid reports whether value can be a value of the field.
+func (s Sort) valid(value string) bool {
+	switch s.Kind {
+	case KindNumber:
+		return numberPattern.MatchString(value)
+	case KindTime:
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	default:
+		// PostgreSQL text cannot hold NUL.
+		return !strings.ContainsRune(value, 0)
+	}
+}
+
+// Cursor points just past the last row of a page: the value of the sort
+// field in that row and its ID, which breaks ties between equal values. Sort
+// is the sort the cursor was made for; it is only valid with that sort.
+type Cursor struct {
+	Sort  string      `json:"s,omitempty"`
+	Value string      `json:"v,omitempty"`
+	ID    pgtype.UUID `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor handed to clients.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by Encode.
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || !cursor.ID.Valid {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// Params are the parsed paging parameters of a request.
+type Params struct {
+	Limit int32
+	// Page is 1-based in offset mode and 0 in cursor mode.
+	Page int32
+	// Sort is the field to sort by, empty for the default order by ID.
+	Sort string
+	Desc bool
+	// After is set in cursor mode.
+	After *Cursor
+}
+
+// ParseOffset reads limit and page, for lists that only page by offset.
+// limit defaults to 10 and is capped at 100, page defaults to 1.
+func ParseOffset(values url.Values) (Params, error) {
+	limit, err := parsePositive(values, "limit", DefaultLimit, ErrInvalidLimit)
+	if err != nil {
+		return Params{}, err
+	}
+
+	page, err := parsePositive(values, "page", 1, ErrInvalidPage)
+	if err != nil {
+		return Params{}, err
+	}
+
+	return Params{Limit: min(limit, MaxLimit), Page: page}, nil
+}
+
+// Parse reads limit, page, cursor and sort. sort is the field of one of
+// sorts, prefixed with "-" for descending order. A cursor switches to keyset
+// paging and cannot be combined with page; its value has to be of the kind
+// of the sort field.
+func Parse(values url.Values, sorts ...Sort) (Params, error) {
+	if values.Has("page") && values.Has("cursor") {
+		return Params{}, ErrPageAndCursor
+	}
+
+	params, err := ParseOffset(values)
+	if err != nil {
+		return Params{}, err
+	}
+
+	var sortBy Sort
+	if sort := values.Get("sort"); sort != "" {
+		params.Desc = strings.HasPrefix(sort, "-")
+		params.Sort = strings.TrimPrefix(sort, "-")
+		i := slices.IndexFunc(sorts, func(s Sort) bool { return s.Field == params.Sort })
+		if i < 0 {
+			return Params{}, ErrInvalidSort
+		}
+		sortBy = sorts[i]
+	}
+
+	if values.Has("cursor") {
+		cursor, err := DecodeCursor(values.Get("cursor"))
+		if err != nil {
+			return Params{}, err
+		}
+		if cursor.Sort != params.SortKey() {
+			return Params{}, ErrInvalidCursor
+		}
+		if params.Sort != "" && !sortBy.valid(cursor.Value) {
+			return Params{}, ErrInvalidCursor
+		}
+		params.After = &cursor
+		params.Page = 0
+	}
+
+	return params, nil
+}
+
+// SortKey is the sort as the list queries take it: the field, prefixed with
+// "-" when descending.
+func (p Params) SortKey() string {
+	if p.Desc {
+		return "-" + p.Sort
+	}
+	return p.Sort
+}
+
+// Offset is the number of rows to skip, always 0 in cursor mode.
+func (p Params) Offset() int32 {
+	if p.Page <= 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Limit
+}
+
+// FetchLimit is one more row than the page holds, so NewPage can tell
+// whether there is a next page.
+func (p Params) FetchLimit() int32 {
+	return p.Limit + 1
+}
+
+// AfterValue is the sort value of the cursor, NULL outside cursor mode.
+func (p Params) AfterValue() pgtype.Text {
+	if p.After == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: p.After.Value, Valid: true}
+}
+
+// AfterID is the row ID of the cursor, NULL outside cursor mode.
+func (p Params) AfterID() pgtype.UUID {
+	if p.After == nil {
+		return pgtype.UUID{}
+	}
+	return p.After.ID
+}
+
+// Page is one page of a list with its metadata.
+type Page[T any] struct {
+	Page       int32  `json:"page"`
+	TotalPage  int32  `json:"total_page"`
+	Count      int32  `json:"count"`
+	TotalCount int32  `json:"total_count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Data       []T    `json:"data"`
+}
+
+// NewPage builds the page from rows fetched with FetchLimit and the
+// total number of matching rows. cursorOf returns the cursor of a row and
+// is called for the last row when there is a next page. It leaves Sort
+// empty, NewPage fills it in.
+func NewPage[T any](p Params, rows []T, total int64, cursorOf func(T) Cursor) Page[T] {
+	page := Page[T]{
+		Page:       p.Page,
+		TotalPage:  TotalPages(total, p.Limit),
+		TotalCount: int32(total),
+		Data:       rows,
+	}
+
+	if int32(len(rows)) > p.Limit {
+		page.Data = rows[:p.Limit]
+		cursor := cursorOf(page.Data[len(page.Data)-1])
+		cursor.Sort = p.SortKey()
+		page.NextCursor = cursor.Encode()
+	}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	page.Count = int32(len(page.Data))
+
+	return page
+}
+
+// TotalPages is the number of pages of limit rows needed for total rows.
+func TotalPages(total int64, limit int32) int32 {
+	if limit <= 0 {
+		return 0
+	}
+	return (int32(total) + limit - 1) / limit
+}
+
+func parsePositive(values url.Values, key string, fallback int32, invalid error) (int32, error) {
+	if !values.Has(key) {
+		return fallback, nil
+	}
+
+	n, err := strconv.ParseInt(values.Get(key), 10, 32)
+	if err != nil || n <= 0 {
+		return 0, invalid
+	}
+
+	return int32(n), nil
+}
@@ -0,0 +1,109 @@
+package pagination
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	id := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	nameCursor := Cursor{Sort: "name", Value: "b", ID: id}.Encode()
+	priceCursor := Cursor{Sort: "-price", Value: "12.50", ID: id}.Encode()
+	createdAtCursor := Cursor{Sort: "created_at", Value: "2024-01-02T03:04:05.123456Z", ID: id}.Encode()
+
+	tests := []struct {
+		name          string
+		query         string
+		expected      Params
+		expectedError error
+	}{
+		{name: "Defaults", query: "", expected: Params{Limit: DefaultLimit, Page: 1}},
+		{name: "Limit is capped", query: "limit=500&page=3", expected: Params{Limit: MaxLimit, Page: 3}},
+		{name: "Descending sort", query: "sort=-price", expected: Params{Limit: DefaultLimit, Page: 1, Sort: "price", Desc: true}},
+		{
+			name:     "Cursor",
+			query:    "sort=name&cursor=" + nameCursor,
+			expected: Params{Limit: DefaultLimit, Sort: "name", After: &Cursor{Sort: "name", Value: "b", ID: id}},
+		},
+		{
+			name:     "Number cursor",
+			query:    "sort=-price&cursor=" + priceCursor,
+			expected: Params{Limit: DefaultLimit, Sort: "price", Desc: true, After: &Cursor{Sort: "-price", Value: "12.50", ID: id}},
+		},
+		{
+			name:     "Time cursor",
+			query:    "sort=created_at&cursor=" + createdAtCursor,
+			expected: Params{Limit: DefaultLimit, Sort: "created_at", After: &Cursor{Sort: "created_at", Value: "2024-01-02T03:04:05.123456Z", ID: id}},
+		},
+		{name: "Invalid limit", query: "limit=0", expectedError: ErrInvalidLimit},
+		{name: "Invalid page", query: "page=abc", expectedError: ErrInvalidPage},
+		{name: "Unknown sort", query: "sort=quantity", expectedError: ErrInvalidSort},
+		{name: "Malformed cursor", query: "cursor=!!!", expectedError: ErrInvalidCursor},
+		{name: "Cursor for another sort", query: "sort=-name&cursor=" + nameCursor, expectedError: ErrInvalidCursor},
+		{name: "Page and cursor", query: "page=1&cursor=" + nameCursor, expectedError: ErrPageAndCursor},
+		{name: "Cursor value not a number", query: "sort=price&cursor=" + Cursor{Sort: "price", Value: "x", ID: id}.Encode(), expectedError: ErrInvalidCursor},
+		{name: "Cursor value in exponent notation", query: "sort=price&cursor=" + Cursor{Sort: "price", Value: "1e999999", ID: id}.Encode(), expectedError: ErrInvalidCursor},
+		{name: "Cursor value not a time", query: "sort=created_at&cursor=" + Cursor{Sort: "created_at", Value: "yesterday", ID: id}.Encode(), expectedError: ErrInvalidCursor},
+		{name: "Cursor value with NUL", query: "sort=name&cursor=" + Cursor{Sort: "name", Value: "a\x00", ID: id}.Encode(), expectedError: ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			params, err := Parse(values, Text("name"), Number("price"), Time("created_at"))
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, params)
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	cursorOf := func(n int) Cursor {
+		return Cursor{Value: string(rune('a' + n)), ID: pgtype.UUID{Bytes: [16]byte{byte(n)}, Valid: true}}
+	}
+
+	t.Run("Trims the extra row into a cursor", func(t *testing.T) {
+		params := Params{Limit: 2, Page: 1, Sort: "name", Desc: true}
+		page := NewPage(params, []int{1, 2, 3}, 7, cursorOf)
+
+		require.Equal(t, []int{1, 2}, page.Data)
+		require.Equal(t, int32(2), page.Count)
+		require.Equal(t, int32(7), page.TotalCount)
+		require.Equal(t, int32(4), page.TotalPage)
+
+		cursor, err := DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+		require.Equal(t, "-name", cursor.Sort)
+		require.Equal(t, cursorOf(2).ID, cursor.ID)
+	})
+
+	t.Run("Last page has no cursor", func(t *testing.T) {
+		page := NewPage(Params{Limit: 2, Page: 1}, []int{1}, 1, cursorOf)
+
+		require.Empty(t, page.NextCursor)
+		require.Equal(t, int32(1), page.Count)
+	})
+
+	t.Run("Empty page has empty data", func(t *testing.T) {
+		page := NewPage(Params{Limit: 2, Page: 1}, nil, 0, cursorOf)
+
+		require.NotNil(t, page.Data)
+		require.Zero(t, page.TotalPage)
+	})
+}
+
+func TestOffset(t *testing.T) {
+	require.Equal(t, int32(0), Params{Limit: 10, Page: 1}.Offset())
+	require.Equal(t, int32(20), Params{Limit: 10, Page: 3}.Offset())
+	require.Equal(t, int32(0), Params{Limit: 10}.Offset())
+}
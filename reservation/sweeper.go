@@ -0,0 +1,62 @@
+// Package reservation runs the background sweeper that expires stock
+// reservations once their TTL has passed, giving the held stock back to the
+// product. The same sweeper forgets failed logins that no longer count.
+package reservation
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/buranasakS/trading_application/auth"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Sweeper expires overdue reservations and forgets stale failed logins every
+// Interval.
+type Sweeper struct {
+	q        db.Querier
+	interval time.Duration
+}
+
+func NewSweeper(q db.Querier, interval time.Duration) *Sweeper {
+	return &Sweeper{q: q, interval: interval}
+}
+
+// Sweep expires the overdue reservations once and returns how many it
+// expired.
+func (s *Sweeper) Sweep(ctx context.Context) (int64, error) {
+	return s.q.ExpireStockReservations(ctx)
+}
+
+// ForgetLoginFailures deletes the failed login counts older than
+// auth.LoginFailureRetention and returns how many it deleted.
+func (s *Sweeper) ForgetLoginFailures(ctx context.Context) (int64, error) {
+	return s.q.DeleteStaleLoginFailures(ctx, pgtype.Timestamptz{Time: time.Now().Add(-auth.LoginFailureRetention), Valid: true})
+}
+
+// Run sweeps every interval until ctx is cancelled. A failed sweep is logged
+// and retried on the next tick.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.Sweep(ctx)
+			if err != nil {
+				log.Printf("Failed to expire stock reservations: %v\n", err)
+			} else if expired > 0 {
+				log.Printf("Expired %d stock reservations\n", expired)
+			}
+
+			if _, err := s.ForgetLoginFailures(ctx); err != nil {
+				log.Printf("Failed to forget login failures: %v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/buranasakS/trading_application/auth"
+	mockdb "github.com/buranasakS/trading_application/db/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweep(t *testing.T) {
+	tests := []struct {
+		name          string
+		expired       int64
+		err           error
+		expectedError string
+	}{
+		{name: "Expires overdue reservations", expired: 3},
+		{name: "Nothing to expire"},
+		{name: "Database error", err: errors.New("db error"), expectedError: "db error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := mockdb.NewMockQuerier(ctrl)
+			mockDB.EXPECT().ExpireStockReservations(gomock.Any()).Return(tt.expired, tt.err)
+
+			expired, err := NewSweeper(mockDB, time.Minute).Sweep(context.Background())
+			if tt.expectedError != "" {
+				require.EqualError(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expired, expired)
+		})
+	}
+}
+
+func TestForgetLoginFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+	mockDB.EXPECT().DeleteStaleLoginFailures(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, cutoff pgtype.Timestamptz) (int64, error) {
+			require.True(t, cutoff.Valid)
+			require.WithinDuration(t, time.Now().Add(-auth.LoginFailureRetention), cutoff.Time, time.Second)
+			return 4, nil
+		})
+
+	deleted, err := NewSweeper(mockDB, time.Minute).ForgetLoginFailures(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), deleted)
+}
+
+func TestRunSweepsUntilCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockDB := mockdb.NewMockQuerier(ctrl)
+	mockDB.EXPECT().DeleteStaleLoginFailures(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	first := mockDB.EXPECT().ExpireStockReservations(gomock.Any()).Return(int64(0), errors.New("db error"))
+	mockDB.EXPECT().ExpireStockReservations(gomock.Any()).After(first).DoAndReturn(func(context.Context) (int64, error) {
+		cancel()
+		return 2, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		NewSweeper(mockDB, time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop after the context was cancelled")
+	}
+}
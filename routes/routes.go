@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"github.com/buranasakS/trading_application/auth"
+	db "github.com/buranasakS/trading_application/db/sqlc"
 	"github.com/buranasakS/trading_application/handlers"
 	"github.com/buranasakS/trading_application/middleware"
 	"github.com/gin-gonic/gin"
@@ -9,48 +11,134 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRoutes(router *gin.Engine, h *handlers.Handler) {
+// Roles allowed on a route, checked by middleware.RequireRole after
+// middleware.JwtMiddleware. No user account is linked to an affiliate, so
+// partners only get what is the same for every affiliate; the balances and
+// commissions of a single affiliate are for staff.
+var (
+	anyRole   = auth.Roles
+	staff     = []string{auth.RoleSupport, auth.RoleAdmin}
+	partners  = []string{auth.RoleAffiliate, auth.RoleSupport, auth.RoleAdmin}
+	adminOnly = []string{auth.RoleAdmin}
+)
+
+func SetupRoutes(router *gin.Engine, h *handlers.Handler, q db.Querier, keys *auth.KeySet) {
 	router.Use(cors.Default())
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/.well-known/jwks.json", h.JWKSHandler)
 
-	// protected := router.Group("/", middleware.JwtMiddleware())
+	// protected := router.Group("/", middleware.JwtMiddleware(q, keys))
 	
 	router.POST("/login", h.LoginUserHandler)
 	router.POST("/register", h.RegisterUserHandler)
 
+	authRoutes := router.Group("/auth")
+	{
+		authRoutes.POST("/refresh", h.RefreshTokenHandler)
+		authRoutes.POST("/logout", middleware.JwtMiddleware(q, keys), h.LogoutHandler)
+		authRoutes.POST("/logout/all", middleware.JwtMiddleware(q, keys), h.LogoutAllHandler)
+	}
+
 	productRoutes := router.Group("/products")
-	productRoutes.Use(middleware.JwtMiddleware())
+	productRoutes.Use(middleware.JwtMiddleware(q, keys))
     {
-        productRoutes.POST("", h.CreateProductHandler)
-        productRoutes.GET("/list", h.ListProductsHandler)
-        productRoutes.GET("/:id", h.GetProductDetailHandler)
+        productRoutes.POST("", middleware.RequireRole(adminOnly...), h.CreateProductHandler)
+        productRoutes.GET("/list", middleware.RequireRole(anyRole...), h.ListProductsHandler)
+        productRoutes.GET("/search", middleware.RequireRole(anyRole...), h.SearchProductsHandler)
+        productRoutes.GET("/:id", middleware.RequireRole(anyRole...), h.GetProductDetailHandler)
+        productRoutes.PATCH("/:id", middleware.RequireRole(adminOnly...), h.UpdateProductHandler)
+        productRoutes.DELETE("/:id", middleware.RequireRole(adminOnly...), h.ArchiveProductHandler)
+        productRoutes.POST("/:id/restock", middleware.RequireRole(adminOnly...), middleware.IdempotencyMiddleware(q), h.RestockProductHandler)
+        productRoutes.GET("/:id/inventory", middleware.RequireRole(staff...), h.ListProductInventoryHandler)
+        productRoutes.POST("/:id/inventory/adjustments", middleware.RequireRole(adminOnly...), middleware.IdempotencyMiddleware(q), h.AdjustProductInventoryHandler)
+        productRoutes.GET("/:id/prices", middleware.RequireRole(anyRole...), h.ListProductPricesHandler)
+        productRoutes.POST("/:id/prices", middleware.RequireRole(adminOnly...), h.ScheduleProductPriceHandler)
+        productRoutes.DELETE("/:id/prices/:price_id", middleware.RequireRole(adminOnly...), h.CancelProductPriceHandler)
+        productRoutes.GET("/:id/commission", middleware.RequireRole(partners...), h.GetProductCommissionHandler)
+        productRoutes.PUT("/:id/commission", middleware.RequireRole(adminOnly...), h.SetProductCommissionHandler)
+        productRoutes.DELETE("/:id/commission", middleware.RequireRole(adminOnly...), h.DeleteProductCommissionHandler)
     }
 
 	affiliateRoutes := router.Group("/affiliates") 
-	affiliateRoutes.Use(middleware.JwtMiddleware())
+	affiliateRoutes.Use(middleware.JwtMiddleware(q, keys))
 	{
-		affiliateRoutes.POST("", h.CreateAffiliateHandler)
-		affiliateRoutes.GET("/list", h.ListAffiliatesHandler)
-		affiliateRoutes.GET("/:id", h.GetAffiliateDetailHandler)
+		affiliateRoutes.POST("", middleware.RequireRole(adminOnly...), h.CreateAffiliateHandler)
+		affiliateRoutes.GET("/list", middleware.RequireRole(staff...), h.ListAffiliatesHandler)
+		affiliateRoutes.GET("/:id", middleware.RequireRole(staff...), h.GetAffiliateDetailHandler)
+		affiliateRoutes.GET("/:id/transactions", middleware.RequireRole(staff...), h.ListAffiliateTransactionsHandler)
 	}
 
 	commissionRoutes := router.Group("/commissions")
-	commissionRoutes.Use(middleware.JwtMiddleware())
+	commissionRoutes.Use(middleware.JwtMiddleware(q, keys))
+	{
+		commissionRoutes.GET("/list", middleware.RequireRole(staff...), h.ListCommissionsHandler)
+		commissionRoutes.POST("/simulate", middleware.RequireRole(partners...), h.SimulateCommissionHandler)
+		commissionRoutes.GET("/:id", middleware.RequireRole(staff...), h.GetCommissionDetailHandler)
+		commissionRoutes.GET("/distribution/:order_id", middleware.RequireRole(staff...), h.GetCommissionDistributionHandler)
+	}
+
+	commissionPlanRoutes := router.Group("/commission-plans")
+	commissionPlanRoutes.Use(middleware.JwtMiddleware(q, keys))
+	{
+		commissionPlanRoutes.POST("", middleware.RequireRole(adminOnly...), h.CreateCommissionPlanHandler)
+		commissionPlanRoutes.GET("/list", middleware.RequireRole(partners...), h.ListCommissionPlansHandler)
+		commissionPlanRoutes.GET("/:id", middleware.RequireRole(partners...), h.GetCommissionPlanDetailHandler)
+		commissionPlanRoutes.PATCH("/:id", middleware.RequireRole(adminOnly...), h.UpdateCommissionPlanHandler)
+		commissionPlanRoutes.DELETE("/:id", middleware.RequireRole(adminOnly...), h.DeleteCommissionPlanHandler)
+	}
+
+	orderRoutes := router.Group("/orders")
+	orderRoutes.Use(middleware.JwtMiddleware(q, keys))
+	{
+		orderRoutes.GET("/:id", middleware.RequireRole(anyRole...), h.GetOrderDetailHandler)
+		orderRoutes.POST("/:id/cancel", middleware.RequireRole(anyRole...), h.CancelOrderHandler)
+		orderRoutes.POST("/:id/refund", middleware.RequireRole(staff...), h.RefundOrderHandler)
+	}
+
+	cartRoutes := router.Group("/carts")
+	cartRoutes.Use(middleware.JwtMiddleware(q, keys))
+	{
+		cartRoutes.POST("", middleware.RequireRole(anyRole...), h.CreateCartHandler)
+		cartRoutes.GET("/:id", middleware.RequireRole(anyRole...), h.GetCartHandler)
+		cartRoutes.POST("/:id/items", middleware.RequireRole(anyRole...), h.AddCartItemHandler)
+		cartRoutes.DELETE("/:id/items/:product_id", middleware.RequireRole(anyRole...), h.RemoveCartItemHandler)
+		cartRoutes.POST("/:id/checkout", middleware.RequireRole(anyRole...), middleware.IdempotencyMiddleware(q), h.CheckoutCartHandler)
+	}
+
+	reservationRoutes := router.Group("/reservations")
+	reservationRoutes.Use(middleware.JwtMiddleware(q, keys))
+	{
+		reservationRoutes.POST("", middleware.RequireRole(anyRole...), middleware.IdempotencyMiddleware(q), h.CreateStockReservationHandler)
+		reservationRoutes.GET("/:id", middleware.RequireRole(anyRole...), h.GetStockReservationHandler)
+		reservationRoutes.POST("/:id/confirm", middleware.RequireRole(anyRole...), middleware.IdempotencyMiddleware(q), h.ConfirmStockReservationHandler)
+		reservationRoutes.POST("/:id/release", middleware.RequireRole(anyRole...), h.ReleaseStockReservationHandler)
+	}
+
+	inventoryRoutes := router.Group("/inventory")
+	inventoryRoutes.Use(middleware.JwtMiddleware(q, keys))
+	{
+		inventoryRoutes.GET("/reconcile", middleware.RequireRole(staff...), h.ReconcileInventoryHandler)
+	}
+
+	ledgerRoutes := router.Group("/ledger")
+	ledgerRoutes.Use(middleware.JwtMiddleware(q, keys))
 	{
-		commissionRoutes.GET("/list", h.ListCommissionsHandler)
-		commissionRoutes.GET("/:id", h.GetCommissionDetailHandler)
-		commissionRoutes.GET("/distribution/:order_id", h.GetCommissionDistributionHandler)
+		ledgerRoutes.GET("/reconcile", middleware.RequireRole(staff...), h.ReconcileLedgerHandler)
 	}
 
 	userRoutes := router.Group("/users")
-	// userRoutes.Use(middleware.JwtMiddleware())
+	userRoutes.Use(middleware.JwtMiddleware(q, keys))
 	{
-		userRoutes.GET("/all", h.ListUsersHandler)
-		userRoutes.GET("/:id", h.GetUserDetailHandler)
-		userRoutes.PATCH("/deduct/balance/:id", h.DeductUserBalanceHandler)
-		userRoutes.PATCH("/add/balance/:id", h.AddUserBalanceHandler)
-		userRoutes.POST("/order", h.UserOrderProductHandler)
+		userRoutes.GET("/all", middleware.RequireRole(staff...), h.ListUsersHandler)
+		userRoutes.GET("/:id", middleware.RequireRole(anyRole...), h.GetUserDetailHandler)
+		userRoutes.GET("/:id/orders", middleware.RequireRole(anyRole...), h.ListUserOrdersHandler)
+		userRoutes.GET("/:id/transactions", middleware.RequireRole(anyRole...), h.ListUserTransactionsHandler)
+		userRoutes.PATCH("/deduct/balance/:id", middleware.RequireRole(adminOnly...), middleware.IdempotencyMiddleware(q), h.DeductUserBalanceHandler)
+		userRoutes.PATCH("/add/balance/:id", middleware.RequireRole(adminOnly...), middleware.IdempotencyMiddleware(q), h.AddUserBalanceHandler)
+		userRoutes.POST("/order", middleware.RequireRole(anyRole...), middleware.IdempotencyMiddleware(q), h.UserOrderProductHandler)
+		userRoutes.PATCH("/:id/role", middleware.RequireRole(adminOnly...), h.UpdateUserRoleHandler)
+		userRoutes.POST("/:id/unlock", middleware.RequireRole(adminOnly...), h.UnlockUserHandler)
 	}
 
 }
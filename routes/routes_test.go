@@ -0,0 +1,165 @@
+package routes
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/buranasakS/trading_application/auth"
+	db "github.com/buranasakS/trading_application/db/sqlc"
+	"github.com/buranasakS/trading_application/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+var testUserID = pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+
+// routeStore only answers the session lookup of JwtMiddleware. A request
+// that gets past the role check reaches a handler whose first query panics,
+// which the recovery middleware turns into a 500.
+type routeStore struct {
+	db.Querier
+}
+
+func (routeStore) GetAuthSessionByID(ctx context.Context, id pgtype.UUID) (db.AuthSession, error) {
+	return db.AuthSession{ID: id, UserID: testUserID}, nil
+}
+
+func (s routeStore) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	return fn(s)
+}
+
+func TestSetupRoutesRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, err := auth.GenerateKey("test")
+	require.NoError(t, err)
+	keys, err := auth.NewKeySet(key.ID, key)
+	require.NoError(t, err)
+
+	store := routeStore{}
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(io.Discard))
+	SetupRoutes(router, handlers.NewHandler(store, keys), store, keys)
+
+	public := []string{
+		"GET /swagger/*any",
+		"GET /.well-known/jwks.json",
+		"POST /login",
+		"POST /register",
+		"POST /auth/refresh",
+	}
+
+	allowed := map[string][]string{
+		"POST /auth/logout":     anyRole,
+		"POST /auth/logout/all": anyRole,
+
+		"POST /products":                           adminOnly,
+		"GET /products/list":                       anyRole,
+		"GET /products/search":                     anyRole,
+		"GET /products/:id":                        anyRole,
+		"PATCH /products/:id":                      adminOnly,
+		"DELETE /products/:id":                     adminOnly,
+		"POST /products/:id/restock":               adminOnly,
+		"GET /products/:id/inventory":              staff,
+		"POST /products/:id/inventory/adjustments": adminOnly,
+		"GET /products/:id/prices":                 anyRole,
+		"POST /products/:id/prices":                adminOnly,
+		"DELETE /products/:id/prices/:price_id":    adminOnly,
+		"GET /products/:id/commission":             partners,
+		"PUT /products/:id/commission":             adminOnly,
+		"DELETE /products/:id/commission":          adminOnly,
+
+		"POST /affiliates":                 adminOnly,
+		"GET /affiliates/list":             staff,
+		"GET /affiliates/:id":              staff,
+		"GET /affiliates/:id/transactions": staff,
+
+		"GET /commissions/list":                   staff,
+		"POST /commissions/simulate":              partners,
+		"GET /commissions/:id":                    staff,
+		"GET /commissions/distribution/:order_id": staff,
+
+		"POST /commission-plans":       adminOnly,
+		"GET /commission-plans/list":   partners,
+		"GET /commission-plans/:id":    partners,
+		"PATCH /commission-plans/:id":  adminOnly,
+		"DELETE /commission-plans/:id": adminOnly,
+
+		"GET /orders/:id":         anyRole,
+		"POST /orders/:id/cancel": anyRole,
+		"POST /orders/:id/refund": staff,
+
+		"POST /carts":                         anyRole,
+		"GET /carts/:id":                      anyRole,
+		"POST /carts/:id/items":               anyRole,
+		"DELETE /carts/:id/items/:product_id": anyRole,
+		"POST /carts/:id/checkout":            anyRole,
+
+		"POST /reservations":             anyRole,
+		"GET /reservations/:id":          anyRole,
+		"POST /reservations/:id/confirm": anyRole,
+		"POST /reservations/:id/release": anyRole,
+
+		"GET /inventory/reconcile": staff,
+		"GET /ledger/reconcile":    staff,
+
+		"GET /users/all":                  staff,
+		"GET /users/:id":                  anyRole,
+		"GET /users/:id/orders":           anyRole,
+		"GET /users/:id/transactions":     anyRole,
+		"PATCH /users/deduct/balance/:id": adminOnly,
+		"PATCH /users/add/balance/:id":    adminOnly,
+		"POST /users/order":               anyRole,
+		"PATCH /users/:id/role":           adminOnly,
+		"POST /users/:id/unlock":          adminOnly,
+	}
+
+	tokens := map[string]string{}
+	for _, role := range auth.Roles {
+		token, err := auth.NewAccessToken(keys, testUserID, pgtype.UUID{Bytes: [16]byte{2}, Valid: true}, "testuser", role, time.Now())
+		require.NoError(t, err)
+		tokens[role] = token
+	}
+
+	param := regexp.MustCompile(`:[a-z_]+`)
+
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		if slices.Contains(public, key) {
+			continue
+		}
+
+		roles, ok := allowed[key]
+		require.True(t, ok, "no roles listed for %s", key)
+
+		// Path IDs are the caller's own, so ownership checks do not interfere.
+		path := param.ReplaceAllString(route.Path, testUserID.String())
+
+		t.Run(key, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(route.Method, path, nil))
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+			for _, role := range auth.Roles {
+				req := httptest.NewRequest(route.Method, path, nil)
+				req.Header.Set("Authorization", "Bearer "+tokens[role])
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, req)
+
+				if slices.Contains(roles, role) {
+					require.NotEqual(t, http.StatusForbidden, recorder.Code, "%s should be allowed", role)
+					require.NotEqual(t, http.StatusUnauthorized, recorder.Code, "%s should be allowed", role)
+				} else {
+					require.Equal(t, http.StatusForbidden, recorder.Code, "%s should be forbidden", role)
+				}
+			}
+		})
+	}
+}